@@ -229,7 +229,7 @@ func main() {
 	token, _ := registrar.CheckToken()
 
 	// add all PostgreSQL Operator controllers to the runtime manager
-	addControllersToManager(mgr, log, registrar)
+	addControllersToManager(running, mgr, log, registrar)
 
 	if features.Enabled(feature.BridgeIdentifiers) {
 		constructor := func() *bridge.Client {
@@ -288,7 +288,9 @@ func main() {
 
 // addControllersToManager adds all PostgreSQL Operator controllers to the provided controller
 // runtime manager.
-func addControllersToManager(mgr runtime.Manager, log logging.Logger, reg registration.Registration) {
+func addControllersToManager(
+	ctx context.Context, mgr runtime.Manager, log logging.Logger, reg registration.Registration,
+) {
 	pgReconciler := &postgrescluster.Reconciler{
 		Client:       mgr.GetClient(),
 		Owner:        postgrescluster.ControllerName,
@@ -296,7 +298,7 @@ func addControllersToManager(mgr runtime.Manager, log logging.Logger, reg regist
 		Registration: reg,
 	}
 
-	if err := pgReconciler.SetupWithManager(mgr); err != nil {
+	if err := pgReconciler.SetupWithManager(ctx, mgr); err != nil {
 		log.Error(err, "unable to create PostgresCluster controller")
 		os.Exit(1)
 	}
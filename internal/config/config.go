@@ -7,6 +7,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -128,6 +131,42 @@ func PostgresContainerImage(cluster *v1beta1.PostgresCluster) string {
 	return defaultFromEnv(image, key)
 }
 
+// PostgresInstanceImage returns the container image to use for PostgreSQL in
+// the instance set described by spec.
+func PostgresInstanceImage(
+	cluster *v1beta1.PostgresCluster, spec *v1beta1.PostgresInstanceSetSpec,
+) string {
+	if spec != nil && spec.Image != "" {
+		return spec.Image
+	}
+	return PostgresContainerImage(cluster)
+}
+
+// postgresImageVersion matches the part of an image tag that names a version
+// of PostgreSQL, such as "17" or "17.4". The major version is the first group.
+var postgresImageVersion = regexp.MustCompile(`^(\d{2})(?:\.\d+)?$`)
+
+// PostgresImageMajorVersion returns the major version of PostgreSQL named in
+// the tag of image and whether or not it could be determined. It recognizes
+// tags like "17.4", "17-alpine", and "ubi9-17.4-2520".
+func PostgresImageMajorVersion(image string) (int, bool) {
+	image, _, _ = strings.Cut(image, "@")
+
+	// The tag follows the last colon after the last slash, if any.
+	colon := strings.LastIndex(image, ":")
+	if colon < 0 || colon < strings.LastIndex(image, "/") {
+		return 0, false
+	}
+
+	for _, part := range strings.Split(image[colon+1:], "-") {
+		if match := postgresImageVersion.FindStringSubmatch(part); match != nil {
+			version, err := strconv.Atoi(match[1])
+			return version, err == nil
+		}
+	}
+	return 0, false
+}
+
 // PGONamespace returns the namespace where the PGO is running,
 // based on the env var from the DownwardAPI
 // If no env var is found, returns ""
@@ -178,3 +217,31 @@ func VerifyImageValues(cluster *v1beta1.PostgresCluster) error {
 
 	return nil
 }
+
+// VerifyInstanceImages checks that instance sets overriding the PostgreSQL
+// image of cluster name the major version in spec.postgresVersion. Images
+// without a recognizable version in their tag are not checked, and neither
+// are sets that use the image of cluster.
+func VerifyInstanceImages(cluster *v1beta1.PostgresCluster) error {
+	var sets []string
+
+	for i := range cluster.Spec.InstanceSets {
+		set := &cluster.Spec.InstanceSets[i]
+		image := set.Image
+
+		if image == "" || image == PostgresContainerImage(cluster) {
+			continue
+		}
+		if version, ok := PostgresImageMajorVersion(image); ok &&
+			version != cluster.Spec.PostgresVersion {
+			sets = append(sets, fmt.Sprintf("%s (%s)", set.Name, image))
+		}
+	}
+
+	if len(sets) > 0 {
+		return fmt.Errorf("expected PostgreSQL %d in the image of instance set(s): %s",
+			cluster.Spec.PostgresVersion, strings.Join(sets, ", "))
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -247,6 +248,64 @@ func TestPostgresContainerImage(t *testing.T) {
 	assert.Equal(t, PostgresContainerImage(cluster), "spec-image")
 }
 
+func TestPostgresInstanceImage(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.PostgresVersion = 12
+	cluster.Spec.Image = "spec-image"
+
+	assert.Equal(t, PostgresInstanceImage(cluster, nil), "spec-image")
+
+	spec := &v1beta1.PostgresInstanceSetSpec{}
+	assert.Equal(t, PostgresInstanceImage(cluster, spec), "spec-image")
+
+	spec.Image = "canary-image"
+	assert.Equal(t, PostgresInstanceImage(cluster, spec), "canary-image")
+}
+
+func TestPostgresImageMajorVersion(t *testing.T) {
+	for _, tt := range []struct {
+		image   string
+		version int
+		ok      bool
+	}{
+		{image: "postgres:17", version: 17, ok: true},
+		{image: "postgres:16.8-alpine", version: 16, ok: true},
+		{image: "localhost:5000/postgis/postgis:17-3.5", version: 17, ok: true},
+		{image: "example.com/crunchy-postgres:ubi9-17.4-2520", version: 17, ok: true},
+		{image: "example.com/crunchy-postgres:ubi9-16.8-2520@sha256:abc", version: 16, ok: true},
+		{image: "postgres", ok: false},
+		{image: "localhost:5000/postgres", ok: false},
+		{image: "postgres:latest", ok: false},
+		{image: "postgres@sha256:abc", ok: false},
+		{image: "example.com/crunchy-postgres:ubi9-2520", ok: false},
+	} {
+		version, ok := PostgresImageMajorVersion(tt.image)
+		assert.Equal(t, ok, tt.ok, "image %q", tt.image)
+		assert.Equal(t, version, tt.version, "image %q", tt.image)
+	}
+}
+
+func TestVerifyInstanceImages(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.PostgresVersion = 17
+	cluster.Spec.Image = "postgres:17.4"
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "00"}, {Name: "01", Image: "postgres:17.5"}, {Name: "02", Image: "postgres:canary"},
+	}
+	assert.NilError(t, VerifyInstanceImages(cluster))
+
+	cluster.Spec.InstanceSets[1].Image = "postgres:18.0"
+	err := VerifyInstanceImages(cluster)
+	assert.ErrorContains(t, err, "PostgreSQL 17")
+	assert.ErrorContains(t, err, "01 (postgres:18.0)")
+	assert.Assert(t, !strings.Contains(err.Error(), "00"))
+
+	// Sets that use the image of the cluster are not checked.
+	cluster.Spec.Image = "postgres:16.8"
+	cluster.Spec.InstanceSets[1].Image = "postgres:16.8"
+	assert.NilError(t, VerifyInstanceImages(cluster))
+}
+
 func TestVerifyImageValues(t *testing.T) {
 	cluster := &v1beta1.PostgresCluster{}
 
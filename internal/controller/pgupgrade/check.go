@@ -0,0 +1,325 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+
+	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/kubernetes"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// checkSourceVolumes returns the volumes of the instance Pod template that
+// are backed by PersistentVolumeClaims. Every one of them is copied for the
+// check Job so that it never writes to the volumes of a running instance.
+func checkSourceVolumes(instance *appsv1.StatefulSet) []corev1.Volume {
+	var volumes []corev1.Volume
+	for _, volume := range instance.Spec.Template.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			volumes = append(volumes, volume)
+		}
+	}
+	return volumes
+}
+
+// generateCheckSnapshot returns a VolumeSnapshot of source using the class in
+// the check settings of upgrade.
+func (r *PGUpgradeReconciler) generateCheckSnapshot(
+	upgrade *v1beta1.PGUpgrade, volume string, source *corev1.PersistentVolumeClaim,
+) *volumesnapshotv1.VolumeSnapshot {
+	return r.generateVolumeSnapshot(upgrade, pgUpgradeCheckVolume(upgrade, volume),
+		pgUpgradeCheck, upgrade.Spec.Check.VolumeSnapshotClassName, source.Name)
+}
+
+// generateVolumeSnapshot returns a VolumeSnapshot of the source volume using
+// class. It is labeled with role and controlled by upgrade.
+func (r *PGUpgradeReconciler) generateVolumeSnapshot(
+	upgrade *v1beta1.PGUpgrade, objectMeta metav1.ObjectMeta,
+	role string, class *string, source string,
+) *volumesnapshotv1.VolumeSnapshot {
+	snapshot := &volumesnapshotv1.VolumeSnapshot{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: volumesnapshotv1.SchemeGroupVersion.String(),
+			Kind:       "VolumeSnapshot",
+		},
+		ObjectMeta: objectMeta,
+	}
+	snapshot.Spec.Source.PersistentVolumeClaimName = &source
+	snapshot.Spec.VolumeSnapshotClassName = class
+
+	// These do not have the cluster label so that the PostgresCluster
+	// controller does not mistake them for its own snapshots.
+	snapshot.Annotations = upgrade.Spec.Metadata.GetAnnotationsOrNil()
+	snapshot.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			LabelPGUpgrade: upgrade.Name,
+			LabelRole:      role,
+		})
+
+	r.setControllerReference(upgrade, snapshot)
+	return snapshot
+}
+
+// generateCheckVolume returns a PersistentVolumeClaim that is populated from
+// source. When the check settings of upgrade have a VolumeSnapshotClass, it
+// is populated from the VolumeSnapshot of the same name.
+func (r *PGUpgradeReconciler) generateCheckVolume(
+	upgrade *v1beta1.PGUpgrade, volume string, source *corev1.PersistentVolumeClaim,
+) *corev1.PersistentVolumeClaim {
+	// - https://docs.k8s.io/concepts/storage/volume-pvc-datasource/
+	dataSource := &corev1.TypedLocalObjectReference{
+		Kind: "PersistentVolumeClaim",
+		Name: source.Name,
+	}
+	if upgrade.Spec.Check.VolumeSnapshotClassName != nil {
+		dataSource = snapshotDataSource(pgUpgradeCheckVolume(upgrade, volume).Name)
+	}
+
+	return r.generateVolume(upgrade, pgUpgradeCheckVolume(upgrade, volume),
+		pgUpgradeCheck, source, dataSource)
+}
+
+// generateVolume returns a PersistentVolumeClaim with the given name and role
+// that requests the same storage as source and is populated from dataSource.
+func (r *PGUpgradeReconciler) generateVolume(
+	upgrade *v1beta1.PGUpgrade, objectMeta metav1.ObjectMeta, role string,
+	source *corev1.PersistentVolumeClaim, dataSource *corev1.TypedLocalObjectReference,
+) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: objectMeta}
+	pvc.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+
+	pvc.Annotations = upgrade.Spec.Metadata.GetAnnotationsOrNil()
+	pvc.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			LabelPGUpgrade: upgrade.Name,
+			LabelRole:      role,
+		})
+
+	// Request the same kind and amount of storage as the source.
+	pvc.Spec.AccessModes = source.Spec.AccessModes
+	pvc.Spec.Resources.Requests = source.Spec.Resources.Requests
+	pvc.Spec.StorageClassName = source.Spec.StorageClassName
+	pvc.Spec.VolumeMode = source.Spec.VolumeMode
+	pvc.Spec.DataSource = dataSource
+
+	r.setControllerReference(upgrade, pvc)
+	return pvc
+}
+
+// snapshotDataSource returns a reference to the VolumeSnapshot with name.
+// - https://docs.k8s.io/concepts/storage/persistent-volumes/#volume-snapshot-and-restore-volume-from-snapshot-support
+func snapshotDataSource(name string) *corev1.TypedLocalObjectReference {
+	return &corev1.TypedLocalObjectReference{
+		APIGroup: initialize.String(volumesnapshotv1.SchemeGroupVersion.Group),
+		Kind:     "VolumeSnapshot",
+		Name:     name,
+	}
+}
+
+// checkFailureMessage returns the termination message of the check Job in
+// world, if any.
+func checkFailureMessage(upgrade *v1beta1.PGUpgrade, world *World) string {
+	for _, pod := range world.JobPods[pgUpgradeCheckJob(upgrade).Name] {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name == ContainerDatabase && status.State.Terminated != nil {
+				if message := strings.TrimSpace(status.State.Terminated.Message); message != "" {
+					return message
+				}
+			}
+		}
+	}
+	return ""
+}
+
+//+kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={create,patch,delete}
+//+kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources="volumesnapshots",verbs={create,patch,delete}
+//+kubebuilder:rbac:groups="batch",resources="jobs",verbs={create,patch}
+
+// reconcileCheck runs the compatibility checks of pg_upgrade against copies
+// of the volumes of the cluster leader in world. The cluster keeps running
+// unless its instances have more than one volume.
+func (r *PGUpgradeReconciler) reconcileCheck(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, world *World,
+) (ctrl.Result, error) {
+	cluster := world.Cluster
+	spec := upgrade.Spec.Check
+
+	progressing := func(reason, message string, args ...any) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            fmt.Sprintf(message, args...),
+		})
+	}
+	stopped := func(reason, message string, args ...any) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            fmt.Sprintf(message, args...),
+		})
+	}
+
+	if version := cluster.Spec.PostgresVersion; version != int(upgrade.Spec.FromPostgresVersion) {
+		stopped("PGUpgradeInvalidForCluster",
+			"Current postgres version is %d, but upgrade expected %d",
+			version, upgrade.Spec.FromPostgresVersion)
+		return ctrl.Result{}, nil
+	}
+
+	setStatusToProgressingIfReasonWas("PGUpgradeInvalidForCluster", upgrade)
+
+	// See the same check in [PGUpgradeReconciler.Reconcile].
+	if cluster.GetAnnotations()[AnnotationAllowUpgrade] != upgrade.Name {
+		stopped("PGClusterMissingRequiredAnnotation",
+			"PostgresCluster %s lacks annotation for upgrade %s",
+			upgrade.Spec.PostgresClusterName, upgrade.GetName())
+		return ctrl.Result{}, nil
+	}
+
+	setStatusToProgressingIfReasonWas("PGClusterMissingRequiredAnnotation", upgrade)
+
+	if spec.VolumeSnapshotClassName != nil && !kubernetes.Has(ctx,
+		volumesnapshotv1.SchemeGroupVersion.WithKind("VolumeSnapshot")) {
+		stopped("PGUpgradeInvalid",
+			"VolumeSnapshots are not installed/enabled in this Kubernetes cluster")
+		return ctrl.Result{}, nil
+	}
+
+	job := world.Jobs[pgUpgradeCheckJob(upgrade).Name]
+	finished := job != nil && (jobCompleted(job) || jobFailed(job))
+
+	// Copy the volumes of the leader and start the check. A stopped cluster
+	// has no leader; use the instance that will start first.
+	if job == nil {
+		leader := world.ClusterLeader
+		if leader == nil && world.ClusterShutdown {
+			leader = world.ClusterPrimary
+		}
+		if leader == nil {
+			stopped("PGClusterPrimaryNotIdentified",
+				"PostgresCluster primary instance not identified")
+			return ctrl.Result{}, nil
+		}
+
+		setStatusToProgressingIfReasonWas("PGClusterPrimaryNotIdentified", upgrade)
+
+		// Volumes are copied one at a time. The copy of a running instance
+		// is consistent only when it has one volume, so copy the data and
+		// WAL volumes only after every instance has stopped.
+		volumes := checkSourceVolumes(leader)
+		if len(volumes) > 1 && !world.ClusterShutdown {
+			stopped("PGClusterNotShutdown",
+				"PostgresCluster %s must be shut down to copy its %d volumes together",
+				upgrade.Spec.PostgresClusterName, len(volumes))
+			return ctrl.Result{}, nil
+		}
+
+		setStatusToProgressingIfReasonWas("PGClusterNotShutdown", upgrade)
+
+		ready := true
+		var err error
+		for _, volume := range volumes {
+			source := world.Volumes[volume.PersistentVolumeClaim.ClaimName]
+			if source == nil {
+				stopped("PGClusterVolumeNotFound",
+					"PersistentVolumeClaim %s not found", volume.PersistentVolumeClaim.ClaimName)
+				return ctrl.Result{}, nil
+			}
+
+			if err == nil && ready && spec.VolumeSnapshotClassName != nil {
+				snapshot := world.VolumeSnapshots[pgUpgradeCheckVolume(upgrade, volume.Name).Name]
+				if snapshot == nil {
+					err = errors.WithStack(r.apply(ctx, r.generateCheckSnapshot(upgrade, volume.Name, source)))
+				}
+				ready = snapshotReady(snapshot)
+			}
+			if err == nil && ready {
+				err = errors.WithStack(r.apply(ctx, r.generateCheckVolume(upgrade, volume.Name, source)))
+			}
+		}
+
+		if err == nil && !ready {
+			progressing("PGUpgradeCopyingData",
+				"Copying the volumes of instance %s", leader.Name)
+			return runtime.RequeueWithBackoff(), nil
+		}
+		if err == nil {
+			err = errors.WithStack(r.apply(ctx, r.generateCheckJob(ctx, upgrade, leader,
+				config.FetchKeyCommand(&cluster.Spec))))
+		}
+		if err == nil {
+			progressing("PGUpgradeChecking",
+				"Checking a copy of instance %s for upgrade to version %d",
+				leader.Name, upgrade.Spec.ToPostgresVersion)
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !finished {
+		progressing("PGUpgradeChecking",
+			"Checking PostgresCluster %s for upgrade to version %d",
+			upgrade.Spec.PostgresClusterName, upgrade.Spec.ToPostgresVersion)
+		return ctrl.Result{}, nil
+	}
+
+	// Delete the copies once the check is done.
+	var err error
+	for _, object := range world.Volumes {
+		if err == nil && metav1.IsControlledBy(object, upgrade) {
+			err = errors.WithStack(client.IgnoreNotFound(r.Client.Delete(ctx, object)))
+		}
+	}
+	for _, object := range world.VolumeSnapshots {
+		if err == nil && metav1.IsControlledBy(object, upgrade) {
+			err = errors.WithStack(client.IgnoreNotFound(r.Client.Delete(ctx, object)))
+		}
+	}
+
+	stopped("PGUpgradeCheckCompleted",
+		"Remove the check field to upgrade PostgresCluster %s, or delete Job %s to check again",
+		upgrade.Spec.PostgresClusterName, job.Name)
+
+	if jobCompleted(job) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeSucceeded,
+			Status:             metav1.ConditionTrue,
+			Reason:             "PGUpgradeCheckPassed",
+			Message: fmt.Sprintf(
+				"PostgresCluster %s passed the checks for upgrade to version %d",
+				upgrade.Spec.PostgresClusterName, upgrade.Spec.ToPostgresVersion),
+		})
+	} else {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeSucceeded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradeCheckFailed",
+			Message: cmp.Or(checkFailureMessage(upgrade, world),
+				"Upgrade check failed, please check the pod logs"),
+		})
+	}
+
+	return ctrl.Result{}, err
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func checkTestUpgrade(t testing.TB) *v1beta1.PGUpgrade {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.UID = "uid3"
+	require.UnmarshalInto(t, &upgrade.Spec, `{
+		postgresClusterName: pg5,
+		fromPostgresVersion: 16,
+		toPostgresVersion: 17,
+		check: {},
+	}`)
+	return upgrade
+}
+
+func TestGenerateCheckVolume(t *testing.T) {
+	reconciler := &PGUpgradeReconciler{}
+	upgrade := checkTestUpgrade(t)
+
+	source := &corev1.PersistentVolumeClaim{}
+	source.Name = "pg5-one-abcd-pgdata"
+	require.UnmarshalInto(t, &source.Spec, `{
+		accessModes: [ReadWriteOnce],
+		resources: { requests: { storage: 1Gi } },
+		storageClassName: fast,
+	}`)
+
+	t.Run("Clone", func(t *testing.T) {
+		pvc := reconciler.generateCheckVolume(upgrade, "postgres-data", source)
+		assert.Assert(t, cmp.MarshalMatches(pvc, `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  creationTimestamp: null
+  labels:
+    postgres-operator.crunchydata.com/pgupgrade: pgu2
+    postgres-operator.crunchydata.com/role: pgupgrade-check
+  name: pgu2-check-postgres-data
+  namespace: ns1
+  ownerReferences:
+  - apiVersion: postgres-operator.crunchydata.com/v1beta1
+    blockOwnerDeletion: true
+    controller: true
+    kind: PGUpgrade
+    name: pgu2
+    uid: uid3
+spec:
+  accessModes:
+  - ReadWriteOnce
+  dataSource:
+    apiGroup: null
+    kind: PersistentVolumeClaim
+    name: pg5-one-abcd-pgdata
+  resources:
+    requests:
+      storage: 1Gi
+  storageClassName: fast
+status: {}
+		`))
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		upgrade := upgrade.DeepCopy()
+		upgrade.Spec.Check.VolumeSnapshotClassName = initialize.String("snaps")
+
+		pvc := reconciler.generateCheckVolume(upgrade, "postgres-data", source)
+		assert.Assert(t, cmp.MarshalMatches(pvc.Spec.DataSource, `
+apiGroup: snapshot.storage.k8s.io
+kind: VolumeSnapshot
+name: pgu2-check-postgres-data
+		`))
+
+		snapshot := reconciler.generateCheckSnapshot(upgrade, "postgres-data", source)
+		assert.Assert(t, cmp.MarshalMatches(snapshot, `
+apiVersion: snapshot.storage.k8s.io/v1
+kind: VolumeSnapshot
+metadata:
+  creationTimestamp: null
+  labels:
+    postgres-operator.crunchydata.com/pgupgrade: pgu2
+    postgres-operator.crunchydata.com/role: pgupgrade-check
+  name: pgu2-check-postgres-data
+  namespace: ns1
+  ownerReferences:
+  - apiVersion: postgres-operator.crunchydata.com/v1beta1
+    blockOwnerDeletion: true
+    controller: true
+    kind: PGUpgrade
+    name: pgu2
+    uid: uid3
+spec:
+  source:
+    persistentVolumeClaimName: pg5-one-abcd-pgdata
+  volumeSnapshotClassName: snaps
+		`))
+	})
+}
+
+func TestCheckFailureMessage(t *testing.T) {
+	upgrade := checkTestUpgrade(t)
+	world := NewWorld()
+
+	assert.Equal(t, checkFailureMessage(upgrade, world), "")
+
+	pod := &corev1.Pod{}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name: ContainerDatabase,
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			Message: "Checking for reg* data types in user tables  fatal\n",
+		}},
+	}}
+	world.JobPods["pgu2-check"] = []*corev1.Pod{pod}
+
+	assert.Equal(t, checkFailureMessage(upgrade, world),
+		"Checking for reg* data types in user tables  fatal")
+}
+
+func TestReconcileCheck(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace, cluster.Name = "ns1", "pg5"
+	cluster.Annotations = map[string]string{AnnotationAllowUpgrade: "pgu2"}
+	cluster.Spec.PostgresVersion = 16
+
+	leader := &appsv1.StatefulSet{}
+	leader.Namespace, leader.Name = "ns1", "pg5-one-abcd"
+	leader.Spec.Template.Spec.Containers = []corev1.Container{{Name: ContainerDatabase}}
+
+	newWorld := func(volumes ...string) *World {
+		world := NewWorld()
+		world.Cluster = cluster.DeepCopy()
+		world.ClusterLeader = leader.DeepCopy()
+		for _, volume := range volumes {
+			pvc := &corev1.PersistentVolumeClaim{}
+			pvc.Namespace, pvc.Name = "ns1", "pg5-one-abcd-"+volume
+			world.Volumes[pvc.Name] = pvc
+
+			world.ClusterLeader.Spec.Template.Spec.Volumes = append(
+				world.ClusterLeader.Spec.Template.Spec.Volumes, corev1.Volume{
+					Name: volume,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvc.Name,
+						},
+					},
+				})
+		}
+		return world
+	}
+
+	reason := func(upgrade *v1beta1.PGUpgrade) string {
+		return meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeProgressing).Reason
+	}
+
+	t.Run("DataVolume", func(t *testing.T) {
+		cc := &applyClient{Client: fake.NewClientBuilder().WithScheme(runtime.Scheme).Build()}
+		reconciler := &PGUpgradeReconciler{Client: cc}
+		upgrade := checkTestUpgrade(t)
+
+		_, err := reconciler.reconcileCheck(ctx, upgrade, newWorld("pgdata"))
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGUpgradeChecking")
+		assert.Equal(t, len(cc.applied), 2, "expected a volume and a Job")
+	})
+
+	t.Run("WALVolume", func(t *testing.T) {
+		cc := &applyClient{Client: fake.NewClientBuilder().WithScheme(runtime.Scheme).Build()}
+		reconciler := &PGUpgradeReconciler{Client: cc}
+		upgrade := checkTestUpgrade(t)
+
+		// Volumes of a running instance are not copied together.
+		world := newWorld("pgdata", "pgwal")
+		_, err := reconciler.reconcileCheck(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGClusterNotShutdown")
+		assert.Equal(t, len(cc.applied), 0)
+
+		// They are copied after every instance stops.
+		world.ClusterLeader, world.ClusterPrimary = nil, world.ClusterLeader
+		world.ClusterShutdown = true
+
+		_, err = reconciler.reconcileCheck(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGUpgradeChecking")
+		assert.Equal(t, len(cc.applied), 3, "expected two volumes and a Job")
+	})
+}
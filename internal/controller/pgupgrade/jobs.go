@@ -10,17 +10,23 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
+	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/feature"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -38,6 +44,18 @@ func pgUpgradeJob(upgrade *v1beta1.PGUpgrade) metav1.ObjectMeta {
 // upgradeCommand returns an entrypoint that prepares the filesystem for
 // and performs a PostgreSQL major version upgrade using pg_upgrade.
 func upgradeCommand(spec *v1beta1.PGUpgradeSettings, fetchKeyCommand string) []string {
+	return pgUpgradeCommand(spec, fetchKeyCommand, false)
+}
+
+// checkCommand returns an entrypoint that recovers a copy of a running
+// PostgreSQL data directory then stops after the compatibility checks of
+// pg_upgrade. When those fail, it writes their findings to the termination
+// message of the container.
+func checkCommand(spec *v1beta1.PGUpgradeSettings, fetchKeyCommand string) []string {
+	return pgUpgradeCommand(spec, fetchKeyCommand, true)
+}
+
+func pgUpgradeCommand(spec *v1beta1.PGUpgradeSettings, fetchKeyCommand string, checkOnly bool) []string {
 	argJobs := fmt.Sprintf(` --jobs=%d`, max(1, spec.Jobs))
 	argMethod := cmp.Or(map[string]string{
 		"Clone":         ` --clone`,
@@ -54,10 +72,15 @@ func upgradeCommand(spec *v1beta1.PGUpgradeSettings, fetchKeyCommand string) []s
 		initdb += ` --encryption-key-command "` + fetchKeyCommand + `"`
 	}
 
+	action, name := `Performing`, `upgrade`
+	if checkOnly {
+		action, name = `Checking`, `check`
+	}
+
 	args := []string{fmt.Sprint(oldVersion), fmt.Sprint(newVersion)}
-	script := strings.Join([]string{
+	script := []string{
 		`declare -r data_volume='/pgdata' old_version="$1" new_version="$2"`,
-		`printf 'Performing PostgreSQL upgrade from version "%s" to "%s" ...\n\n' "$@"`,
+		`printf '` + action + ` PostgreSQL upgrade from version "%s" to "%s" ...\n\n' "$@"`,
 
 		// Note: Rather than import the nss_wrapper init container, as we do in
 		// the main postgres-operator, this job does the required nss_wrapper
@@ -89,6 +112,23 @@ func upgradeCommand(spec *v1beta1.PGUpgradeSettings, fetchKeyCommand string) []s
 		// To begin, we first move to the mounted /pgdata directory and create a
 		// new version directory which is then initialized with the initdb command.
 		`cd /pgdata || exit`,
+	}
+
+	// The copy of a running data directory looks like one that crashed.
+	// pg_upgrade requires one that was shut down cleanly, so start and stop
+	// PostgreSQL on the copy without archiving or network connections.
+	if checkOnly {
+		script = append(script,
+			`echo -e "Step 0: Recovering the copy of the old pgdata directory...\n"`,
+			`rm -f /pgdata/pg"${old_version}"/postmaster.pid`,
+			`/usr/pgsql-"${old_version}"/bin/pg_ctl start --wait --timeout=3600 --log=/tmp/recovery.log \`,
+			`--pgdata=/pgdata/pg"${old_version}" --options="-c archive_mode=off -c listen_addresses='' -c unix_socket_directories=/tmp" \`,
+			`|| { cat /tmp/recovery.log; exit 1; }`,
+			`/usr/pgsql-"${old_version}"/bin/pg_ctl stop --wait --mode=fast --pgdata=/pgdata/pg"${old_version}"`,
+		)
+	}
+
+	script = append(script,
 		`echo -e "Step 1: Making new pgdata directory...\n"`,
 		`mkdir /pgdata/pg"${new_version}"`,
 		`echo -e "Step 2: Initializing new pgdata directory...\n"`,
@@ -106,16 +146,44 @@ func upgradeCommand(spec *v1beta1.PGUpgradeSettings, fetchKeyCommand string) []s
 		// Before the actual upgrade is run, we will run the upgrade --check to
 		// verify everything before actually changing any data.
 		`echo -e "Step 5: Running pg_upgrade check...\n"`,
-		`time /usr/pgsql-"${new_version}"/bin/pg_upgrade --old-bindir /usr/pgsql-"${old_version}"/bin \`,
-		`--new-bindir /usr/pgsql-"${new_version}"/bin --old-datadir /pgdata/pg"${old_version}"\`,
-		` --new-datadir /pgdata/pg"${new_version}" --check` + argMethod + argJobs,
+	)
+
+	check := `time /usr/pgsql-"${new_version}"/bin/pg_upgrade --old-bindir /usr/pgsql-"${old_version}"/bin \` + "\n" +
+		`--new-bindir /usr/pgsql-"${new_version}"/bin --old-datadir /pgdata/pg"${old_version}"\` + "\n" +
+		` --new-datadir /pgdata/pg"${new_version}" --check` + argMethod + argJobs
+
+	if !checkOnly {
+		script = append(script, check)
+	} else {
+		// pg_upgrade explains each failed check in its output and lists the
+		// problem objects in files: in the current directory before PostgreSQL
+		// 15 and in the new data directory since then. Send them all to the
+		// termination message; Kubernetes keeps the first 4096 bytes.
+		// - https://git.postgresql.org/gitweb/?p=postgresql.git;f=src/bin/pg_upgrade/pg_upgrade.c;hb=REL_15_0#l101
+		// - https://kubernetes.io/docs/tasks/debug/debug-application/determine-reason-pod-failure/
+		script = append(script,
+			`status=0; { `+check+`; } > /tmp/check.log 2>&1 || status=$?`,
+			`cat /tmp/check.log`,
+			`if [[ "${status}" -ne 0 ]]; then`,
+			`  { sed -n '/fatal$/,$ p' /tmp/check.log`,
+			`    find /pgdata -maxdepth 1 -name '*.txt' -exec tail -n +1 -- {} +`,
+			`    find /pgdata/pg"${new_version}" -path '*pg_upgrade_output.d/*.txt' -exec tail -n +1 -- {} +`,
+			`  } > /dev/termination-log`,
+			`  exit "${status}"`,
+			`fi`,
+			`echo -e "\npg_upgrade check Complete!"`,
+		)
+
+		return append([]string{"bash", "-ceu", "--", strings.Join(script, "\n"), name}, args...)
+	}
 
+	script = append(script,
 		// Assuming the check completes successfully, the pg_upgrade command will
 		// be run that actually prepares the upgraded pgdata directory.
 		`echo -e "\nStep 6: Running pg_upgrade...\n"`,
 		`time /usr/pgsql-"${new_version}"/bin/pg_upgrade --old-bindir /usr/pgsql-"${old_version}"/bin \`,
 		`--new-bindir /usr/pgsql-"${new_version}"/bin --old-datadir /pgdata/pg"${old_version}" \`,
-		`--new-datadir /pgdata/pg"${new_version}"` + argMethod + argJobs,
+		`--new-datadir /pgdata/pg"${new_version}"`+argMethod+argJobs,
 
 		// Since we have cleared the Patroni cluster step by removing the EndPoints, we copy patroni.dynamic.json
 		// from the old data dir to help retain PostgreSQL parameters you had set before.
@@ -124,9 +192,9 @@ func upgradeCommand(spec *v1beta1.PGUpgradeSettings, fetchKeyCommand string) []s
 		`cp /pgdata/pg"${old_version}"/patroni.dynamic.json /pgdata/pg"${new_version}"`,
 
 		`echo -e "\npg_upgrade Job Complete!"`,
-	}, "\n")
+	)
 
-	return append([]string{"bash", "-ceu", "--", script, "upgrade"}, args...)
+	return append([]string{"bash", "-ceu", "--", strings.Join(script, "\n"), name}, args...)
 }
 
 // largestWholeCPU returns the maximum CPU request or limit as a non-negative
@@ -145,15 +213,38 @@ func largestWholeCPU(resources corev1.ResourceRequirements) int64 {
 func (r *PGUpgradeReconciler) generateUpgradeJob(
 	ctx context.Context, upgrade *v1beta1.PGUpgrade,
 	startup *appsv1.StatefulSet, fetchKeyCommand string,
+) *batchv1.Job {
+	return r.generatePGUpgradeJob(ctx, upgrade, startup, fetchKeyCommand, false)
+}
+
+// generateCheckJob returns a Job that runs the compatibility checks of
+// pg_upgrade against copies of the volumes of the leader instance. See
+// [pgUpgradeCheckVolume].
+func (r *PGUpgradeReconciler) generateCheckJob(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade,
+	leader *appsv1.StatefulSet, fetchKeyCommand string,
+) *batchv1.Job {
+	return r.generatePGUpgradeJob(ctx, upgrade, leader, fetchKeyCommand, true)
+}
+
+func (r *PGUpgradeReconciler) generatePGUpgradeJob(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade,
+	startup *appsv1.StatefulSet, fetchKeyCommand string, checkOnly bool,
 ) *batchv1.Job {
 	job := &batchv1.Job{}
 	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
 
+	role := pgUpgrade
 	job.Namespace = upgrade.Namespace
 	job.Name = pgUpgradeJob(upgrade).Name
 
+	if checkOnly {
+		role = pgUpgradeCheck
+		job.Name = pgUpgradeCheckJob(upgrade).Name
+	}
+
 	job.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
-		commonLabels(pgUpgrade, upgrade), //FIXME role pgupgrade
+		commonLabels(role, upgrade),
 		map[string]string{
 			LabelVersion: fmt.Sprint(upgrade.Spec.ToPostgresVersion),
 		})
@@ -198,6 +289,18 @@ func (r *PGUpgradeReconciler) generateUpgradeJob(
 		settings.Jobs = wholeCPUs - 1
 	}
 
+	command := upgradeCommand(settings, fetchKeyCommand)
+	if checkOnly {
+		command = checkCommand(settings, fetchKeyCommand)
+
+		// Mount the copies rather than the volumes of the running instance.
+		for i := range job.Spec.Template.Spec.Volumes {
+			if claim := job.Spec.Template.Spec.Volumes[i].PersistentVolumeClaim; claim != nil {
+				claim.ClaimName = pgUpgradeCheckVolume(upgrade, job.Spec.Template.Spec.Volumes[i].Name).Name
+			}
+		}
+	}
+
 	// Replace all containers with one that does the upgrade.
 	job.Spec.Template.Spec.EphemeralContainers = nil
 	job.Spec.Template.Spec.InitContainers = nil
@@ -210,12 +313,18 @@ func (r *PGUpgradeReconciler) generateUpgradeJob(
 		VolumeMounts:    database.VolumeMounts,
 
 		// Use our upgrade command and the specified image and resources.
-		Command:         upgradeCommand(settings, fetchKeyCommand),
+		Command:         command,
 		Image:           pgUpgradeContainerImage(upgrade),
 		ImagePullPolicy: upgrade.Spec.ImagePullPolicy,
 		Resources:       upgrade.Spec.Resources,
 	}}
 
+	// The check command writes its findings to the termination message.
+	if checkOnly {
+		job.Spec.Template.Spec.Containers[0].TerminationMessagePolicy =
+			corev1.TerminationMessageFallbackToLogsOnError
+	}
+
 	// The following will set these fields to null if not set in the spec
 	job.Spec.Template.Spec.Affinity = upgrade.Spec.Affinity
 	job.Spec.Template.Spec.PriorityClassName =
@@ -226,6 +335,111 @@ func (r *PGUpgradeReconciler) generateUpgradeJob(
 	return job
 }
 
+// pgUpgradeCheckJob returns the ObjectMeta for the Job that runs only the
+// compatibility checks of pg_upgrade.
+func pgUpgradeCheckJob(upgrade *v1beta1.PGUpgrade) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-check",
+	}
+}
+
+// pgUpgradeCheckVolume returns the ObjectMeta for the copy of an instance
+// volume, identified by its name in the Pod template, that the check Job
+// mounts. VolumeSnapshots of the instance volumes share these names.
+func pgUpgradeCheckVolume(upgrade *v1beta1.PGUpgrade, volume string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-check-" + volume,
+	}
+}
+
+// pgUpgradeRetainedVolume returns the ObjectMeta for the VolumeSnapshot of an
+// instance volume, identified by its name in the Pod template, that is taken
+// before the upgrade Job runs.
+func pgUpgradeRetainedVolume(upgrade *v1beta1.PGUpgrade, volume string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-retained-" + volume,
+	}
+}
+
+// pgUpgradeRestoreJob returns the ObjectMeta for the Job that restores the
+// volumes of the primary from its retained VolumeSnapshots.
+func pgUpgradeRestoreJob(upgrade *v1beta1.PGUpgrade) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-restore",
+	}
+}
+
+// pgUpgradeRestoreVolume returns the ObjectMeta for the PersistentVolumeClaim
+// populated from the retained VolumeSnapshot of an instance volume, identified
+// by its name in the Pod template.
+func pgUpgradeRestoreVolume(upgrade *v1beta1.PGUpgrade, volume string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-restore-" + volume,
+	}
+}
+
+// restoreCommand returns an entrypoint that replaces the contents of each
+// given mount path with that of the same path under "/restore".
+func restoreCommand(upgrade *v1beta1.PGUpgrade, paths []string) []string {
+	oldVersion := fmt.Sprint(upgrade.Spec.FromPostgresVersion)
+
+	// Every file is compared and copied, and those that are not in the
+	// snapshot are deleted. This includes the directories of the new version.
+	rsync := `rsync --archive --delete --hard-links --exclude=/lost+found`
+
+	args := append([]string{oldVersion}, paths...)
+	script := strings.Join([]string{
+		`declare -r old_version="$1"; shift`,
+		`printf 'Restoring the PostgreSQL %s data from before the upgrade...\n\n' "${old_version}"`,
+		`for directory in "$@"; do`,
+		`  echo -e "Restoring ${directory}...\n"`,
+		`  time ` + rsync + ` "/restore${directory}/" "${directory}/"`,
+		`done`,
+		`echo -e "Restore Job Complete!"`,
+	}, "\n")
+
+	return append([]string{"bash", "-ceu", "--", script, "restore"}, args...)
+}
+
+// generateRestoreJob returns a Job that copies the restore volumes of upgrade
+// onto the volumes of the primary StatefulSet.
+func (r *PGUpgradeReconciler) generateRestoreJob(
+	_ context.Context, upgrade *v1beta1.PGUpgrade, primary *appsv1.StatefulSet,
+) *batchv1.Job {
+	job := r.generateReplicaJob(upgrade, primary,
+		pgUpgradeRestoreJob(upgrade).Name, pgUpgradeRestore, nil)
+
+	// Mount each restore volume under "/restore" at the path of its source.
+	var paths []string
+	container := &job.Spec.Template.Spec.Containers[0]
+	for _, volume := range checkSourceVolumes(primary) {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == volume.Name {
+				paths = append(paths, mount.MountPath)
+
+				volume.Name = "restore-" + volume.Name
+				volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: pgUpgradeRestoreVolume(upgrade, mount.Name).Name,
+				}
+				mount.Name = volume.Name
+				mount.MountPath = "/restore" + mount.MountPath
+
+				container.VolumeMounts = append(container.VolumeMounts, mount)
+				job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, volume)
+				break
+			}
+		}
+	}
+	container.Command = restoreCommand(upgrade, paths)
+
+	return job
+}
+
 // Remove data job
 
 // removeDataCommand returns an entrypoint that removes certain directories.
@@ -266,19 +480,200 @@ func removeDataCommand(upgrade *v1beta1.PGUpgrade) []string {
 	return append([]string{"bash", "-ceu", "--", script, "remove"}, args...)
 }
 
+// removeDataJob returns the ObjectMeta for the Job that removes the data of
+// the given replica StatefulSet.
+func removeDataJob(upgrade *v1beta1.PGUpgrade, sts *appsv1.StatefulSet) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-" + sts.Name,
+	}
+}
+
 // generateRemoveDataJob returns a Job that can remove the data
 // on the given replica StatefulSet
 func (r *PGUpgradeReconciler) generateRemoveDataJob(
 	_ context.Context, upgrade *v1beta1.PGUpgrade, sts *appsv1.StatefulSet,
+) *batchv1.Job {
+	return r.generateReplicaJob(upgrade, sts,
+		removeDataJob(upgrade, sts).Name, removeData, removeDataCommand(upgrade))
+}
+
+// replicaUpgradeJob returns the ObjectMeta for the Job that upgrades the data
+// of the given replica StatefulSet in place.
+func replicaUpgradeJob(upgrade *v1beta1.PGUpgrade, replica *appsv1.StatefulSet) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-rsync-" + replica.Name,
+	}
+}
+
+// replicaUpgradeSchedulingTimeout is how long the Pod of a replica upgrade Job
+// can be unschedulable before that replica is recreated instead. The Pod
+// mounts the volumes of both the primary and the replica, and the scheduler
+// cannot place it when those are bound to different Nodes or zones.
+const replicaUpgradeSchedulingTimeout = 2 * time.Minute
+
+// podsUnschedulable returns true when any of pods has been unschedulable for
+// longer than timeout.
+func podsUnschedulable(pods []*corev1.Pod, timeout time.Duration) bool {
+	for _, pod := range pods {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled &&
+				condition.Status == corev1.ConditionFalse &&
+				condition.Reason == corev1.PodReasonUnschedulable &&
+				time.Since(condition.LastTransitionTime.Time) > timeout {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replicaUpgradeCommand returns an entrypoint that upgrades the old pgdata
+// directory of a replica in place using the data directories of the upgraded
+// primary mounted under "/primary". Only a replica that stopped at the same
+// checkpoint as the primary can be upgraded this way; the old pgdata directory
+// of any other is removed so that it is recreated.
+// - https://www.postgresql.org/docs/current/pgupgrade.html#PGUPGRADE-STEP-REPLICAS
+func replicaUpgradeCommand(upgrade *v1beta1.PGUpgrade) []string {
+	oldVersion := fmt.Sprint(upgrade.Spec.FromPostgresVersion)
+	newVersion := fmt.Sprint(upgrade.Spec.ToPostgresVersion)
+
+	// rsync copies the files of the primary that differ in size, and it
+	// recreates the hard links between the old and new directories of the
+	// primary. It must see both of them at once to do that.
+	rsync := `rsync --archive --delete --hard-links --size-only --no-inc-recursive`
+
+	args := []string{oldVersion, newVersion}
+	script := strings.Join([]string{
+		`declare -r old_version="$1" new_version="$2"`,
+		`printf 'Upgrading PostgreSQL replica from version "%s" to "%s" ...\n\n' "$@"`,
+		`cd /pgdata || exit`,
+		`controldata() { LC_ALL=C /usr/pgsql-"${old_version}"/bin/pg_controldata "$1" | sed -n "s/^$2: *//p"; }`,
+
+		// See the same check in [removeDataCommand].
+		`echo -e "Step 1: Checking the old pgdata directory was shut down as a replica...\n"`,
+		`if [[ "$(controldata /pgdata/pg"${old_version}" 'Database cluster state')" != 'shut down in recovery' ]]; then echo -e "Directory in use, cannot upgrade..."; exit 1; fi`,
+
+		// In link mode, pg_upgrade renames the control file of the old primary
+		// so that it cannot start. Read a copy of it.
+		`echo -e "Step 2: Comparing the latest checkpoint of the replica to that of the primary...\n"`,
+		`mkdir -p /tmp/primary/global`,
+		`cp /primary/pgdata/pg"${old_version}"/global/pg_control.old /tmp/primary/global/pg_control`,
+		`primary=$(controldata /tmp/primary 'Latest checkpoint location')`,
+		`replica=$(controldata /pgdata/pg"${old_version}" 'Latest checkpoint location')`,
+		`printf 'primary: %s\nreplica: %s\n\n' "${primary}" "${replica}"`,
+		`if [[ -z "${primary}" || "${primary}" != "${replica}" ]]; then`,
+		`  echo -e "Replica is behind the primary, removing old pgdata directory...\n"`,
+		`  rm -rf /pgdata/pg"${old_version}" "$(realpath /pgdata/pg"${old_version}"/pg_wal)"`,
+		`  echo -e "Replica Upgrade Job Complete!"; exit 0`,
+		`fi`,
+
+		// The new pgdata directory of the primary has its own WAL directory
+		// because it has never started, so only tablespaces need more.
+		`echo -e "Step 3: Copying the upgrade from the primary...\n"`,
+		`time ` + rsync + ` /primary/pgdata/pg"${old_version}" /primary/pgdata/pg"${new_version}" /pgdata`,
+		`for directory in /primary/tablespaces/*/data; do`,
+		`  if [[ -d "${directory}" && -d "$(dirname "${directory#/primary}")" ]]; then`,
+		`    time ` + rsync + ` "${directory}" "$(dirname "${directory#/primary}")"`,
+		`  fi`,
+		`done`,
+
+		`echo -e "\nReplica Upgrade Job Complete!"`,
+	}, "\n")
+
+	return append([]string{"bash", "-ceu", "--", script, "replica"}, args...)
+}
+
+// generateReplicaUpgradeJob returns a Job that upgrades the data of the given
+// replica StatefulSet in place using the data of the upgraded primary.
+func (r *PGUpgradeReconciler) generateReplicaUpgradeJob(
+	_ context.Context, upgrade *v1beta1.PGUpgrade, primary, replica *appsv1.StatefulSet,
+) *batchv1.Job {
+	job := r.generateReplicaJob(upgrade, replica,
+		replicaUpgradeJob(upgrade, replica).Name, pgUpgradeReplica, replicaUpgradeCommand(upgrade))
+
+	// Mount the data and tablespace volumes of the primary under "/primary".
+	// Its WAL volume is not needed; see [replicaUpgradeCommand].
+	container := &job.Spec.Template.Spec.Containers[0]
+	for _, volume := range checkSourceVolumes(primary) {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == volume.Name && mount.Name != postgres.WALVolumeMount().Name {
+				volume.Name = "primary-" + volume.Name
+				mount.Name = volume.Name
+				mount.MountPath = "/primary" + mount.MountPath
+
+				container.VolumeMounts = append(container.VolumeMounts, mount)
+				job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, volume)
+				break
+			}
+		}
+	}
+
+	return job
+}
+
+//+kubebuilder:rbac:groups="batch",resources="jobs",verbs={create,patch,delete}
+
+// reconcileReplicaJobs creates a Job for each replica in world that removes
+// its data or, when the replicaMethod of upgrade is "Rsync", upgrades it in
+// place. A replica whose upgrade Job cannot be scheduled is recreated instead.
+func (r *PGUpgradeReconciler) reconcileReplicaJobs(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, world *World,
+) (ctrl.Result, error) {
+	var result ctrl.Result
+	var err error
+
+	for _, sts := range world.ClusterReplicas {
+		if err != nil {
+			break
+		}
+		rsync := world.Jobs[replicaUpgradeJob(upgrade, sts).Name]
+
+		switch {
+		case upgrade.Spec.ReplicaMethod != v1beta1.PGUpgradeReplicaMethodRsync,
+			world.Jobs[removeDataJob(upgrade, sts).Name] != nil:
+			err = r.apply(ctx, r.generateRemoveDataJob(ctx, upgrade, sts))
+
+		case rsync != nil && podsUnschedulable(world.JobPods[rsync.Name], replicaUpgradeSchedulingTimeout):
+			r.Recorder.Eventf(upgrade, corev1.EventTypeWarning, "ReplicaUpgradeUnschedulable",
+				"Job %s could not be scheduled with the volumes of the primary; recreating replica %s instead",
+				rsync.Name, sts.Name)
+
+			err = errors.WithStack(client.IgnoreNotFound(r.Client.Delete(ctx, rsync,
+				client.PropagationPolicy(metav1.DeletePropagationBackground))))
+			if err == nil {
+				err = r.apply(ctx, r.generateRemoveDataJob(ctx, upgrade, sts))
+			}
+
+		default:
+			err = r.apply(ctx, r.generateReplicaUpgradeJob(ctx, upgrade, world.ClusterPrimary, sts))
+
+			// A Pod that cannot be scheduled does not change its Job, so
+			// look again after the timeout.
+			if rsync != nil && !jobCompleted(rsync) && !jobFailed(rsync) {
+				result = runtime.RequeueWithoutBackoff(replicaUpgradeSchedulingTimeout)
+			}
+		}
+	}
+
+	return result, err
+}
+
+// generateReplicaJob returns a Job with the given name and role that runs
+// command using the volumes of the given replica StatefulSet.
+func (r *PGUpgradeReconciler) generateReplicaJob(
+	upgrade *v1beta1.PGUpgrade, sts *appsv1.StatefulSet,
+	name, role string, command []string,
 ) *batchv1.Job {
 	job := &batchv1.Job{}
 	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
 
 	job.Namespace = upgrade.Namespace
-	job.Name = upgrade.Name + "-" + sts.Name
+	job.Name = name
 
 	job.Labels = labels.Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
-		commonLabels(removeData, upgrade)) //FIXME role removedata
+		commonLabels(role, upgrade))
 
 	// Find the database container.
 	var database *corev1.Container
@@ -307,7 +702,7 @@ func (r *PGUpgradeReconciler) generateRemoveDataJob(
 	// Use the image pull secrets specified for the upgrade image.
 	job.Spec.Template.Spec.ImagePullSecrets = upgrade.Spec.ImagePullSecrets
 
-	// Attempt the removal exactly once.
+	// Attempt the command exactly once.
 	job.Spec.BackoffLimit = initialize.Int32(0)
 	job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
 
@@ -323,8 +718,8 @@ func (r *PGUpgradeReconciler) generateRemoveDataJob(
 		SecurityContext: database.SecurityContext,
 		VolumeMounts:    database.VolumeMounts,
 
-		// Use our remove command and the specified resources.
-		Command:         removeDataCommand(upgrade),
+		// Use the given command and the specified resources.
+		Command:         command,
 		Image:           pgUpgradeContainerImage(upgrade),
 		ImagePullPolicy: upgrade.Spec.ImagePullPolicy,
 		Resources:       upgrade.Spec.Resources,
@@ -9,16 +9,21 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/feature"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/events"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -122,6 +127,31 @@ func TestUpgradeCommand(t *testing.T) {
 	})
 }
 
+func TestCheckCommand(t *testing.T) {
+	spec := &v1beta1.PGUpgradeSettings{
+		FromPostgresVersion: 16, ToPostgresVersion: 17, TransferMethod: "Copy",
+	}
+
+	command := checkCommand(spec, "")
+	assert.Assert(t, len(command) > 3)
+	assert.DeepEqual(t, []string{"bash", "-ceu", "--"}, command[:3])
+	assert.DeepEqual(t, []string{"check", "16", "17"}, command[4:])
+
+	script := command[3]
+	assert.Assert(t, cmp.Contains(script, `pg_ctl start`))
+	assert.Assert(t, cmp.Contains(script, `--check --copy --jobs=1`))
+	assert.Assert(t, cmp.Contains(script, `/dev/termination-log`))
+	assert.Assert(t, !strings.Contains(script, `Step 6`),
+		"expected no upgrade, got:\n%s", script)
+
+	t.Run("PrettyYAML", func(t *testing.T) {
+		b, err := yaml.Marshal(script)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.HasPrefix(string(b), `|`),
+			"expected literal block scalar, got:\n%s", b)
+	})
+}
+
 func TestGenerateUpgradeJob(t *testing.T) {
 	ctx := context.Background()
 	reconciler := &PGUpgradeReconciler{}
@@ -266,6 +296,65 @@ status: {}
 		`/usr/pgsql-"${new_version}"/bin/initdb -k -D /pgdata/pg"${new_version}" --encryption-key-command "echo testKey"`))
 }
 
+func TestGenerateCheckJob(t *testing.T) {
+	ctx := context.Background()
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.UID = "uid3"
+	upgrade.Spec.Image = initialize.Pointer("img4")
+	upgrade.Spec.PostgresClusterName = "pg5"
+	upgrade.Spec.FromPostgresVersion = 19
+	upgrade.Spec.ToPostgresVersion = 25
+	upgrade.Spec.Check = &v1beta1.PGUpgradeCheckSpec{}
+
+	leader := &appsv1.StatefulSet{}
+	leader.Spec.Template.Spec = corev1.PodSpec{
+		Containers: []corev1.Container{{Name: ContainerDatabase}},
+		Volumes: []corev1.Volume{
+			{
+				Name: "postgres-data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: "pg5-one-abcd-pgdata",
+					},
+				},
+			},
+			{
+				Name: "vol2",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: new(corev1.HostPathVolumeSource),
+				},
+			},
+		},
+	}
+
+	job := reconciler.generateCheckJob(ctx, upgrade, leader, "")
+	assert.Equal(t, job.Name, "pgu2-check")
+	assert.Equal(t, job.Labels[LabelRole], "pgupgrade-check")
+	assert.Equal(t, job.Spec.Template.Labels[LabelRole], "pgupgrade-check")
+
+	assert.Assert(t, cmp.MarshalMatches(job.Spec.Template.Spec.Volumes, `
+- name: postgres-data
+  persistentVolumeClaim:
+    claimName: pgu2-check-postgres-data
+- hostPath:
+    path: ""
+  name: vol2
+	`))
+
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.DeepEqual(t, container.Command[4:], []string{"check", "19", "25"})
+	assert.Equal(t, container.TerminationMessagePolicy,
+		corev1.TerminationMessageFallbackToLogsOnError)
+
+	// The instance is unchanged.
+	assert.Equal(t, leader.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName,
+		"pg5-one-abcd-pgdata")
+}
+
 func TestGenerateRemoveDataJob(t *testing.T) {
 	ctx := context.Background()
 	reconciler := &PGUpgradeReconciler{}
@@ -371,6 +460,223 @@ status: {}
 	`))
 }
 
+func TestReplicaUpgradeCommand(t *testing.T) {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Spec.FromPostgresVersion = 16
+	upgrade.Spec.ToPostgresVersion = 17
+
+	command := replicaUpgradeCommand(upgrade)
+	assert.Assert(t, len(command) > 3)
+	assert.DeepEqual(t, []string{"bash", "-ceu", "--"}, command[:3])
+	assert.DeepEqual(t, []string{"replica", "16", "17"}, command[4:])
+
+	script := command[3]
+	assert.Assert(t, cmp.Contains(script, `global/pg_control.old`))
+	assert.Assert(t, cmp.Contains(script,
+		`rsync --archive --delete --hard-links --size-only --no-inc-recursive`+
+			` /primary/pgdata/pg"${old_version}" /primary/pgdata/pg"${new_version}" /pgdata`))
+
+	t.Run("PrettyYAML", func(t *testing.T) {
+		b, err := yaml.Marshal(script)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.HasPrefix(string(b), `|`),
+			"expected literal block scalar, got:\n%s", b)
+	})
+}
+
+func TestGenerateReplicaUpgradeJob(t *testing.T) {
+	ctx := context.Background()
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.UID = "uid3"
+	upgrade.Spec.PostgresClusterName = "pg5"
+	upgrade.Spec.FromPostgresVersion = 16
+	upgrade.Spec.ToPostgresVersion = 17
+
+	instance := func(name string) *appsv1.StatefulSet {
+		sts := &appsv1.StatefulSet{}
+		sts.Name = name
+		sts.Spec.Template.Spec = corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: ContainerDatabase,
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "postgres-data", MountPath: "/pgdata"},
+					{Name: "postgres-wal", MountPath: "/pgwal"},
+					{Name: "tablespace-trial", MountPath: "/tablespaces/trial"},
+				},
+			}},
+		}
+		for _, volume := range []string{"postgres-data", "postgres-wal", "tablespace-trial"} {
+			sts.Spec.Template.Spec.Volumes = append(sts.Spec.Template.Spec.Volumes, corev1.Volume{
+				Name: volume,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: name + "-" + volume,
+					},
+				},
+			})
+		}
+		return sts
+	}
+
+	job := reconciler.generateReplicaUpgradeJob(ctx, upgrade, instance("pg5-one"), instance("pg5-two"))
+	assert.Equal(t, job.Name, "pgu2-rsync-pg5-two")
+	assert.Equal(t, job.Labels[LabelRole], "pgupgrade-replica")
+	assert.Equal(t, job.Spec.Template.Labels[LabelRole], "pgupgrade-replica")
+	assert.DeepEqual(t, job.Spec.Template.Spec.Containers[0].Command, replicaUpgradeCommand(upgrade))
+
+	// The WAL volume of the primary is not mounted.
+	assert.Assert(t, cmp.MarshalMatches(job.Spec.Template.Spec.Containers[0].VolumeMounts, `
+- mountPath: /pgdata
+  name: postgres-data
+- mountPath: /pgwal
+  name: postgres-wal
+- mountPath: /tablespaces/trial
+  name: tablespace-trial
+- mountPath: /primary/pgdata
+  name: primary-postgres-data
+- mountPath: /primary/tablespaces/trial
+  name: primary-tablespace-trial
+	`))
+	assert.Assert(t, cmp.MarshalMatches(job.Spec.Template.Spec.Volumes, `
+- name: postgres-data
+  persistentVolumeClaim:
+    claimName: pg5-two-postgres-data
+- name: postgres-wal
+  persistentVolumeClaim:
+    claimName: pg5-two-postgres-wal
+- name: tablespace-trial
+  persistentVolumeClaim:
+    claimName: pg5-two-tablespace-trial
+- name: primary-postgres-data
+  persistentVolumeClaim:
+    claimName: pg5-one-postgres-data
+- name: primary-tablespace-trial
+  persistentVolumeClaim:
+    claimName: pg5-one-tablespace-trial
+	`))
+}
+
+func TestReconcileReplicaJobs(t *testing.T) {
+	ctx := context.Background()
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.Spec.PostgresClusterName = "pg5"
+	upgrade.Spec.FromPostgresVersion = 16
+	upgrade.Spec.ToPostgresVersion = 17
+	upgrade.Spec.ReplicaMethod = v1beta1.PGUpgradeReplicaMethodRsync
+
+	instance := func(name string) *appsv1.StatefulSet {
+		sts := &appsv1.StatefulSet{}
+		sts.Name = name
+		sts.Spec.Template.Spec.Containers = []corev1.Container{{Name: ContainerDatabase}}
+		return sts
+	}
+
+	newWorld := func() *World {
+		world := NewWorld()
+		world.ClusterPrimary = instance("pg5-one")
+		world.ClusterReplicas = []*appsv1.StatefulSet{instance("pg5-two")}
+		return world
+	}
+
+	t.Run("Recreate", func(t *testing.T) {
+		cc := &applyClient{}
+		reconciler := &PGUpgradeReconciler{Client: cc}
+
+		upgrade := upgrade.DeepCopy()
+		upgrade.Spec.ReplicaMethod = v1beta1.PGUpgradeReplicaMethodRecreate
+
+		result, err := reconciler.reconcileReplicaJobs(ctx, upgrade, newWorld())
+		assert.NilError(t, err)
+		assert.Assert(t, result.IsZero())
+		assert.Equal(t, len(cc.applied), 1)
+		assert.Equal(t, cc.applied[0].GetName(), "pgu2-pg5-two")
+	})
+
+	t.Run("Rsync", func(t *testing.T) {
+		cc := &applyClient{}
+		reconciler := &PGUpgradeReconciler{Client: cc}
+		world := newWorld()
+
+		result, err := reconciler.reconcileReplicaJobs(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Assert(t, result.IsZero())
+		assert.Equal(t, len(cc.applied), 1)
+		assert.Equal(t, cc.applied[0].GetName(), "pgu2-rsync-pg5-two")
+
+		// A running Job is checked again after the timeout.
+		job := &batchv1.Job{}
+		job.Name = "pgu2-rsync-pg5-two"
+		world.Jobs[job.Name] = job
+
+		pod := &corev1.Pod{}
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodScheduled,
+			Status:             corev1.ConditionFalse,
+			Reason:             corev1.PodReasonUnschedulable,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Second)),
+		}}
+		world.JobPods[job.Name] = []*corev1.Pod{pod}
+
+		result, err = reconciler.reconcileReplicaJobs(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, result.RequeueAfter, replicaUpgradeSchedulingTimeout)
+		assert.Equal(t, len(cc.deleted), 0)
+		assert.Equal(t, len(cc.applied), 2)
+		assert.Equal(t, cc.applied[1].GetName(), "pgu2-rsync-pg5-two")
+	})
+
+	t.Run("Unschedulable", func(t *testing.T) {
+		cc := &applyClient{}
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		reconciler := &PGUpgradeReconciler{Client: cc, Recorder: recorder}
+		world := newWorld()
+
+		job := &batchv1.Job{}
+		job.Name = "pgu2-rsync-pg5-two"
+		world.Jobs[job.Name] = job
+
+		pod := &corev1.Pod{}
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:               corev1.PodScheduled,
+			Status:             corev1.ConditionFalse,
+			Reason:             corev1.PodReasonUnschedulable,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+		}}
+		world.JobPods[job.Name] = []*corev1.Pod{pod}
+
+		// The rsync Job is replaced by one that removes the data.
+		_, err := reconciler.reconcileReplicaJobs(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, len(cc.deleted), 1)
+		assert.Equal(t, cc.deleted[0].GetName(), "pgu2-rsync-pg5-two")
+		assert.Equal(t, len(cc.applied), 1)
+		assert.Equal(t, cc.applied[0].GetName(), "pgu2-pg5-two")
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Type, "Warning")
+		assert.Equal(t, recorder.Events[0].Reason, "ReplicaUpgradeUnschedulable")
+
+		// The replica stays that way.
+		delete(world.Jobs, job.Name)
+		remove := &batchv1.Job{}
+		remove.Name = "pgu2-pg5-two"
+		world.Jobs[remove.Name] = remove
+
+		cc.applied = nil
+		_, err = reconciler.reconcileReplicaJobs(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, len(cc.applied), 1)
+		assert.Equal(t, cc.applied[0].GetName(), "pgu2-pg5-two")
+	})
+}
+
 func TestPGUpgradeContainerImage(t *testing.T) {
 	upgrade := &v1beta1.PGUpgrade{}
 
@@ -17,6 +17,14 @@ const (
 	// status of a Postgres major upgrade.
 	ConditionPGUpgradeSucceeded = "Succeeded"
 
+	// ConditionPGUpgradeExtensionsUpdated is the type used in a condition to
+	// indicate that extensions were updated after a Postgres major upgrade.
+	ConditionPGUpgradeExtensionsUpdated = "ExtensionsUpdated"
+
+	// ConditionPGUpgradeAnalyzed is the type used in a condition to indicate
+	// that planner statistics were collected after a Postgres major upgrade.
+	ConditionPGUpgradeAnalyzed = "Analyzed"
+
 	labelPrefix           = "postgres-operator.crunchydata.com/"
 	LabelPGUpgrade        = labelPrefix + "pgupgrade"
 	LabelCluster          = labelPrefix + "cluster"
@@ -29,8 +37,16 @@ const (
 	ReplicaCreate     = "replica-create"
 	ContainerDatabase = "database"
 
-	pgUpgrade  = "pgupgrade"
-	removeData = "removedata"
+	pgUpgrade           = "pgupgrade"
+	pgUpgradeCheck      = "pgupgrade-check"
+	pgUpgradeReplica    = "pgupgrade-replica"
+	pgUpgradeRetained   = "pgupgrade-retained"
+	pgUpgradeRestore    = "pgupgrade-restore"
+	pgUpgradeAnalyze    = "pgupgrade-analyze"
+	pgUpgradeExtensions = "pgupgrade-extensions"
+	removeData          = "removedata"
+	logicalSchema       = "logical-schema"
+	logicalCutover      = "logical-cutover"
 )
 
 func commonLabels(role string, upgrade *v1beta1.PGUpgrade) map[string]string {
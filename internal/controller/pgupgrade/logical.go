@@ -0,0 +1,675 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pgbouncer"
+	"github.com/crunchydata/postgres-operator/internal/pgmonitor"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// A logical replication upgrade copies the data of a running PostgresCluster
+// (the source) to a new PostgresCluster (the target) at the new version:
+//
+//  1. The source publishes every table of each database, and the target is
+//     created from the specification of the source.
+//  2. A Job copies the roles of the source then the schema of each database
+//     to the target.
+//  3. The target subscribes to each publication. PostgreSQL copies the
+//     existing rows of every table then streams changes as they happen.
+//  4. When every subscription is streaming, the source stops connections to
+//     each database, and a Job ends the sessions already connected then waits
+//     for the target to receive the last changes. It then copies the values
+//     of sequences and drops the subscriptions.
+//  5. The primary Service and PgBouncer of the source are redirected to the
+//     primary of the target.
+//
+// Writes fail only between steps 4 and 5. When the Job fails, or when the
+// redirect is later removed, the source restores its connection limits.
+// - https://www.postgresql.org/docs/current/logical-replication.html
+
+// logicalName returns the name of the publication and subscription that copy
+// the database at index of a logical replication upgrade. Replication slots
+// take the name of their subscription and must be unique across databases.
+func logicalName(index int) string { return fmt.Sprintf("pgupgrade_%d", index) }
+
+// logicalUsers returns the users of cluster, including the default user
+// created when none are specified.
+func logicalUsers(cluster *v1beta1.PostgresCluster) []v1beta1.PostgresUserSpec {
+	if cluster.Spec.Users == nil {
+		return []v1beta1.PostgresUserSpec{{
+			Name:      cluster.Name,
+			Databases: []string{cluster.Name},
+		}}
+	}
+	return cluster.Spec.Users
+}
+
+// logicalJob returns the ObjectMeta for a Job of a logical replication upgrade.
+func logicalJob(upgrade *v1beta1.PGUpgrade, role string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-" + role,
+	}
+}
+
+// generateLogicalSource returns the fields of the PostgresCluster being
+// upgraded that are managed during a logical replication upgrade. When stop
+// is true, source stops connections to the databases being copied. When
+// redirect is true, connections to source go to the new cluster instead.
+func generateLogicalSource(
+	upgrade *v1beta1.PGUpgrade, source *v1beta1.PostgresCluster, stop, redirect bool,
+) *v1beta1.PostgresCluster {
+	spec := upgrade.Spec.LogicalReplication
+
+	intent := &v1beta1.PostgresCluster{}
+	intent.SetGroupVersionKind(v1beta1.GroupVersion.WithKind("PostgresCluster"))
+	intent.Namespace = source.Namespace
+	intent.Name = source.Name
+
+	// Publish every table of each database, including tables created later.
+	for i, database := range spec.Databases {
+		intent.Spec.Publications = append(intent.Spec.Publications,
+			v1beta1.PostgresPublicationSpec{Name: logicalName(i), Database: database})
+	}
+
+	// The source restores the connection limit of each database when this
+	// stops, unless it is redirected.
+	if stop {
+		databases, _ := json.Marshal(spec.Databases)
+		intent.Annotations = map[string]string{
+			naming.PostgresStopConnections: string(databases),
+		}
+	}
+	if redirect {
+		intent.Annotations = map[string]string{
+			naming.PostgresPrimaryRedirect: spec.ClusterName,
+		}
+	}
+
+	return intent
+}
+
+// generateLogicalTarget returns the PostgresCluster that replaces source
+// during a logical replication upgrade. When subscribe is true, it receives
+// changes from source.
+func generateLogicalTarget(
+	upgrade *v1beta1.PGUpgrade, source *v1beta1.PostgresCluster, subscribe bool,
+) *v1beta1.PostgresCluster {
+	spec := upgrade.Spec.LogicalReplication
+
+	intent := &v1beta1.PostgresCluster{}
+	intent.SetGroupVersionKind(v1beta1.GroupVersion.WithKind("PostgresCluster"))
+	intent.Namespace = source.Namespace
+	intent.Name = spec.ClusterName
+	intent.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{LabelPGUpgrade: upgrade.Name})
+
+	// Start with the specification of source, but at the new version and with
+	// its own data.
+	source.Spec.DeepCopyInto(&intent.Spec)
+	intent.Spec.PostgresVersion = int(upgrade.Spec.ToPostgresVersion)
+	intent.Spec.Image = initialize.FromPointer(spec.Image)
+	intent.Spec.DataSource = nil
+	intent.Spec.Shutdown = nil
+	intent.Spec.Standby = nil
+
+	// The users of source are created explicitly so their names and
+	// passwords stay the same; see [PGUpgradeReconciler.copyLogicalSecrets].
+	intent.Spec.Users = logicalUsers(source)
+
+	// Logical replication of the new cluster is managed below.
+	intent.Spec.Publications = nil
+	intent.Spec.Subscriptions = nil
+
+	// Node ports cannot be shared by two Services.
+	for _, service := range []*v1beta1.ServiceSpec{
+		intent.Spec.Service, intent.Spec.ReplicaService,
+	} {
+		if service != nil {
+			service.NodePort = nil
+		}
+	}
+	if intent.Spec.Proxy != nil && intent.Spec.Proxy.PGBouncer != nil &&
+		intent.Spec.Proxy.PGBouncer.Service != nil {
+		intent.Spec.Proxy.PGBouncer.Service.NodePort = nil
+	}
+
+	// Keep only pgBackRest repositories that belong to each cluster. Cloud
+	// repositories are shared, and two clusters cannot write to the same one.
+	if pgbackrest := &intent.Spec.Backups.PGBackRest; len(pgbackrest.Repos) > 0 {
+		repos := pgbackrest.Repos[:0]
+		for _, repo := range pgbackrest.Repos {
+			if repo.Volume != nil {
+				repos = append(repos, repo)
+			} else {
+				for key := range pgbackrest.Global {
+					if strings.HasPrefix(key, repo.Name+"-") {
+						delete(pgbackrest.Global, key)
+					}
+				}
+			}
+		}
+		pgbackrest.Repos = repos
+		pgbackrest.Restore = nil
+
+		if len(repos) == 0 {
+			intent.Spec.Backups = v1beta1.Backups{}
+		}
+	}
+
+	if subscribe {
+		for i, database := range spec.Databases {
+			intent.Spec.Subscriptions = append(intent.Spec.Subscriptions,
+				v1beta1.PostgresSubscriptionSpec{
+					Name:         logicalName(i),
+					Database:     database,
+					Publications: []string{logicalName(i)},
+					Source: v1beta1.PostgresSubscriptionSourceSpec{
+						ClusterName: source.Name,
+						User:        spec.User,
+					},
+				})
+		}
+	}
+
+	return intent
+}
+
+// logicalStreaming returns true when every subscription of target is
+// receiving changes after copying its tables.
+func logicalStreaming(upgrade *v1beta1.PGUpgrade, target *v1beta1.PostgresCluster) bool {
+	states := make(map[string]string, len(target.Status.Subscriptions))
+	for _, status := range target.Status.Subscriptions {
+		states[status.Name] = status.State
+	}
+	for i := range upgrade.Spec.LogicalReplication.Databases {
+		if states[logicalName(i)] != v1beta1.PostgresSubscriptionStreaming {
+			return false
+		}
+	}
+	return true
+}
+
+// logicalPassword returns the Secret key that holds the password of user in
+// cluster.
+func logicalPassword(cluster *v1beta1.PostgresCluster, user string) *corev1.SecretKeySelector {
+	for _, spec := range cluster.Spec.Users {
+		if spec.Name == user && spec.Password != nil && spec.Password.SecretKeyRef != nil {
+			return &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: spec.Password.SecretKeyRef.Name,
+				},
+				Key: spec.Password.SecretKeyRef.Key,
+			}
+		}
+	}
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{
+			Name: naming.PostgresUserSecret(cluster, user).Name,
+		},
+		Key: "password",
+	}
+}
+
+// logicalSchemaCommand returns an entrypoint that copies the roles and the
+// schema of each database from the source to the target.
+func logicalSchemaCommand(upgrade *v1beta1.PGUpgrade, source *v1beta1.PostgresCluster) []string {
+	// The operator sets the passwords of these roles in each cluster.
+	managed := []string{"postgres", pgbouncer.PostgresqlUser, pgmonitor.MonitoringUser, postgres.ReplicationUser}
+	for _, user := range logicalUsers(source) {
+		managed = append(managed, user.Name)
+	}
+	roles, _ := json.Marshal(managed)
+
+	args := []string{fmt.Sprint(upgrade.Spec.ToPostgresVersion), string(roles)}
+	args = append(args, upgrade.Spec.LogicalReplication.Databases...)
+
+	script := strings.Join([]string{
+		`declare -r new_version="$1" managed_roles="$2"; shift 2`,
+		`set -o pipefail`,
+
+		// The schema refers to roles, such as the owners of tables and those
+		// in privileges, that are not in the users field. Copy every role
+		// first. Roles that the operator already created in the target are
+		// reported and skipped by psql, but their attributes are copied.
+		// - https://www.postgresql.org/docs/current/app-pg-dumpall.html
+		`echo 'Copying roles ...'`,
+		`PGHOST="${SOURCE_HOST}" PGPASSWORD="${SOURCE_PASSWORD}" \`,
+		`/usr/pgsql-"${new_version}"/bin/pg_dumpall --roles-only --no-role-passwords |`,
+		`PGHOST="${TARGET_HOST}" PGPASSWORD="${TARGET_PASSWORD}" \`,
+		`/usr/pgsql-"${new_version}"/bin/psql --dbname=postgres --no-psqlrc --quiet`,
+
+		// Copy the passwords of roles that the operator does not manage.
+		// - https://www.postgresql.org/docs/current/catalog-pg-authid.html
+		`PGHOST="${SOURCE_HOST}" PGPASSWORD="${SOURCE_PASSWORD}" \`,
+		`/usr/pgsql-"${new_version}"/bin/psql --dbname=postgres --no-psqlrc --no-align --quiet --tuples-only \`,
+		`--set=ON_ERROR_STOP=1 --set=managed="${managed_roles}" <<< \`,
+		`"SELECT pg_catalog.format('ALTER ROLE %I WITH PASSWORD %L;', rolname, rolpassword) FROM pg_catalog.pg_authid` +
+			` WHERE rolpassword IS NOT NULL AND rolname NOT IN (SELECT pg_catalog.json_array_elements_text(:'managed'))" |`,
+		`PGHOST="${TARGET_HOST}" PGPASSWORD="${TARGET_PASSWORD}" \`,
+		`/usr/pgsql-"${new_version}"/bin/psql --dbname=postgres --no-psqlrc --quiet --set=ON_ERROR_STOP=1`,
+
+		// Extensions that the operator already created in the target are
+		// skipped by "IF NOT EXISTS", and so are user schemas after the
+		// change below. The PgBouncer and monitoring schemas belong to each
+		// cluster.
+		// - https://www.postgresql.org/docs/current/app-pgdump.html
+		`for database in "$@"; do`,
+		`printf 'Copying the schema of database "%s" ...\n' "${database}"`,
+		`PGHOST="${SOURCE_HOST}" PGPASSWORD="${SOURCE_PASSWORD}" \`,
+		`/usr/pgsql-"${new_version}"/bin/pg_dump --dbname="${database}" \`,
+		`--schema-only --no-publications --no-subscriptions --exclude-schema=pgbouncer --exclude-schema=monitor |`,
+		`sed -e 's/^CREATE SCHEMA /CREATE SCHEMA IF NOT EXISTS /' |`,
+		`PGHOST="${TARGET_HOST}" PGPASSWORD="${TARGET_PASSWORD}" \`,
+		`/usr/pgsql-"${new_version}"/bin/psql --dbname="${database}" --no-psqlrc --quiet --set=ON_ERROR_STOP=1`,
+		`done`,
+
+		`echo -e "\nSchema Job Complete!"`,
+	}, "\n")
+
+	return append([]string{"bash", "-ceu", "--", script, "schema"}, args...)
+}
+
+// logicalCutoverCommand returns an entrypoint that ends sessions in the source
+// and waits for the target to receive every change. It then copies the
+// values of sequences and drops the subscriptions.
+func logicalCutoverCommand(upgrade *v1beta1.PGUpgrade) []string {
+	args := []string{fmt.Sprint(upgrade.Spec.ToPostgresVersion)}
+	for i, database := range upgrade.Spec.LogicalReplication.Databases {
+		args = append(args, logicalName(i), database)
+	}
+
+	script := strings.Join([]string{
+		`declare -r new_version="$1"; shift`,
+		`set -o pipefail`,
+		`on_source() { PGHOST="${SOURCE_HOST}" PGPASSWORD="${SOURCE_PASSWORD}" \`,
+		`/usr/pgsql-"${new_version}"/bin/psql --no-psqlrc --no-align --quiet --tuples-only --set=ON_ERROR_STOP=1 "$@"; }`,
+		`on_target() { PGHOST="${TARGET_HOST}" PGPASSWORD="${TARGET_PASSWORD}" \`,
+		`/usr/pgsql-"${new_version}"/bin/psql --no-psqlrc --no-align --quiet --tuples-only --set=ON_ERROR_STOP=1 "$@"; }`,
+
+		// The source sets the connection limit of each database to zero, which
+		// stops everyone except superusers from connecting, including PgBouncer.
+		// Unlike a setting, sessions cannot override it. Wait for that, then end
+		// every session already connected, and wait for them to exit so their
+		// last changes are in the WAL.
+		// - https://www.postgresql.org/docs/current/sql-alterdatabase.html
+		`echo -e "Step 1: Stopping connections...\n"`,
+		`for (( i = 2; i <= $#; i += 2 )); do`,
+		`until [[ "$(on_source --dbname="${!i}" --set=database="${!i}" <<< \`,
+		`"SELECT datconnlimit FROM pg_catalog.pg_database WHERE datname = :'database'")" == '0' ]]`,
+		`do sleep 1; done`,
+		`until [[ "$(on_source --dbname="${!i}" --set=database="${!i}" <<< \`,
+		`"SELECT count(pg_catalog.pg_terminate_backend(pid)) FROM pg_catalog.pg_stat_activity` +
+			` WHERE datname = :'database' AND backend_type = 'client backend' AND pid <> pg_catalog.pg_backend_pid()")" == '0' ]]`,
+		`do sleep 1; done`,
+		`done`,
+		`lsn="$(on_source --dbname="$2" --command='SELECT pg_catalog.pg_current_wal_lsn()')"`,
+
+		`while [[ $# -gt 0 ]]; do`,
+		`subscription="$1" database="$2"; shift 2`,
+
+		// Each subscription confirms positions in the WAL of the source
+		// through its replication slot.
+		// - https://www.postgresql.org/docs/current/view-pg-replication-slots.html
+		`printf '\nStep 2: Waiting for subscription "%s" to receive changes through %s...\n' "${subscription}" "${lsn}"`,
+		`until [[ "$(on_source --dbname="${database}" --set=slot="${subscription}" --set=lsn="${lsn}" <<< \`,
+		`"SELECT confirmed_flush_lsn >= :'lsn' FROM pg_catalog.pg_replication_slots WHERE slot_name = :'slot'")" == 't' ]]`,
+		`do sleep 1; done`,
+
+		// Logical replication does not replicate sequences.
+		// - https://www.postgresql.org/docs/current/logical-replication-restrictions.html
+		`printf 'Step 3: Copying sequences of database "%s"...\n' "${database}"`,
+		`on_source --dbname="${database}" --command="SELECT pg_catalog.format('SELECT pg_catalog.setval(%L, %s);',` +
+			` pg_catalog.format('%I.%I', schemaname, sequencename), last_value)` +
+			` FROM pg_catalog.pg_sequences WHERE last_value IS NOT NULL" |`,
+		`on_target --dbname="${database}"`,
+
+		// Dropping a subscription drops its replication slot in the source.
+		// This fails while the slot is still in use, so try again.
+		// - https://www.postgresql.org/docs/current/sql-dropsubscription.html
+		`printf 'Step 4: Dropping subscription "%s"...\n' "${subscription}"`,
+		`until on_target --dbname="${database}" --set=subscription="${subscription}" <<< \`,
+		`'DROP SUBSCRIPTION IF EXISTS :"subscription"'`,
+		`do sleep 1; done`,
+		`done`,
+
+		`echo -e "\nCutover Job Complete!"`,
+	}, "\n")
+
+	return append([]string{"bash", "-ceu", "--", script, "cutover"}, args...)
+}
+
+// generateLogicalJob returns a Job that runs command against the source and
+// target of a logical replication upgrade.
+func (r *PGUpgradeReconciler) generateLogicalJob(
+	upgrade *v1beta1.PGUpgrade, source *v1beta1.PostgresCluster,
+	role string, command []string,
+) *batchv1.Job {
+	spec := upgrade.Spec.LogicalReplication
+	target := &v1beta1.PostgresCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: source.Namespace, Name: spec.ClusterName,
+	}}
+	target.Spec.Users = source.Spec.Users
+
+	job := &batchv1.Job{ObjectMeta: logicalJob(upgrade, role)}
+	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+
+	job.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
+		commonLabels(role, upgrade),
+		map[string]string{
+			LabelVersion: fmt.Sprint(upgrade.Spec.ToPostgresVersion),
+		})
+	job.Annotations = Merge(upgrade.Spec.Metadata.GetAnnotationsOrNil(),
+		map[string]string{
+			naming.DefaultContainerAnnotation: ContainerDatabase,
+		})
+
+	// Use the same labels and annotations as the job.
+	job.Spec.Template.ObjectMeta = metav1.ObjectMeta{
+		Annotations: job.Annotations,
+		Labels:      job.Labels,
+	}
+
+	// Run each step exactly once. Neither can be repeated safely.
+	job.Spec.BackoffLimit = initialize.Int32(0)
+	job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+	// Connect to the primary Service of each cluster. The user has the same
+	// name in both clusters; see [PGUpgradeReconciler.copyLogicalSecrets].
+	primary := func(cluster *v1beta1.PostgresCluster) string {
+		service := naming.ClusterPrimaryService(cluster)
+		return service.Name + "." + service.Namespace + ".svc"
+	}
+	env := []corev1.EnvVar{
+		{Name: "PGCONNECT_TIMEOUT", Value: "10"},
+		{Name: "PGPORT", Value: fmt.Sprint(*source.Spec.Port)},
+		{Name: "PGSSLMODE", Value: "require"},
+		{Name: "PGUSER", Value: spec.User},
+		{Name: "SOURCE_HOST", Value: primary(source)},
+		{Name: "SOURCE_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: logicalPassword(source, spec.User),
+		}},
+		{Name: "TARGET_HOST", Value: primary(target)},
+		{Name: "TARGET_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: logicalPassword(target, spec.User),
+		}},
+	}
+
+	job.Spec.Template.Spec.ImagePullSecrets = upgrade.Spec.ImagePullSecrets
+	job.Spec.Template.Spec.SecurityContext = initialize.PodSecurityContext()
+	job.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name:            ContainerDatabase,
+		Command:         command,
+		Env:             env,
+		Image:           pgUpgradeContainerImage(upgrade),
+		ImagePullPolicy: upgrade.Spec.ImagePullPolicy,
+		Resources:       upgrade.Spec.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+	}}
+
+	// The following will set these fields to null if not set in the spec
+	job.Spec.Template.Spec.Affinity = upgrade.Spec.Affinity
+	job.Spec.Template.Spec.PriorityClassName =
+		initialize.FromPointer(upgrade.Spec.PriorityClassName)
+	job.Spec.Template.Spec.Tolerations = upgrade.Spec.Tolerations
+
+	r.setControllerReference(upgrade, job)
+	return job
+}
+
+//+kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+//+kubebuilder:rbac:groups="",resources="secrets",verbs={create}
+
+// copyLogicalSecrets creates Secrets for the target of a logical replication
+// upgrade that hold the passwords of source. This keeps the passwords of
+// users and PgBouncer the same after their connections are redirected.
+func (r *PGUpgradeReconciler) copyLogicalSecrets(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, source *v1beta1.PostgresCluster,
+) error {
+	target := &v1beta1.PostgresCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: source.Namespace, Name: upgrade.Spec.LogicalReplication.ClusterName,
+	}}
+
+	copySecret := func(from, to metav1.ObjectMeta, labels map[string]string, keys ...string) error {
+		existing := &corev1.Secret{ObjectMeta: from}
+		err := errors.WithStack(
+			r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing))
+
+		// The operator generates any Secret that is missing.
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		intent := &corev1.Secret{ObjectMeta: to}
+		intent.Labels = labels
+		intent.Type = corev1.SecretTypeOpaque
+		intent.Data = make(map[string][]byte, len(keys))
+		for _, key := range keys {
+			if value := existing.Data[key]; len(value) > 0 {
+				intent.Data[key] = value
+			}
+		}
+
+		// Create the Secret once; the operator manages it afterward.
+		return client.IgnoreAlreadyExists(errors.WithStack(r.Client.Create(ctx, intent)))
+	}
+
+	var err error
+	for _, user := range logicalUsers(source) {
+		if user.Password != nil && user.Password.SecretKeyRef != nil {
+			continue
+		}
+		if err == nil {
+			err = copySecret(
+				naming.PostgresUserSecret(source, user.Name),
+				naming.PostgresUserSecret(target, user.Name),
+				map[string]string{
+					naming.LabelCluster:      target.Name,
+					naming.LabelPostgresUser: user.Name,
+					naming.LabelRole:         naming.RolePostgresUser,
+				},
+				"password", "verifier")
+		}
+	}
+
+	// PgBouncer logs into PostgreSQL with the password in its Secret.
+	if err == nil && source.Spec.Proxy != nil && source.Spec.Proxy.PGBouncer != nil {
+		err = copySecret(
+			naming.ClusterPGBouncer(source),
+			naming.ClusterPGBouncer(target),
+			map[string]string{
+				naming.LabelCluster: target.Name,
+				naming.LabelRole:    naming.RolePGBouncer,
+			},
+			"pgbouncer-password", "pgbouncer-verifier")
+	}
+
+	return err
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={create,patch}
+//+kubebuilder:rbac:groups="batch",resources="jobs",verbs={create,patch}
+
+// reconcileLogicalReplication moves the cluster in world to a new
+// PostgresCluster at the "to" version using logical replication.
+func (r *PGUpgradeReconciler) reconcileLogicalReplication(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, world *World,
+) (ctrl.Result, error) {
+	spec := upgrade.Spec.LogicalReplication
+	source, target := world.Cluster, world.TargetCluster
+
+	progressing := func(reason, message string, args ...any) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            fmt.Sprintf(message, args...),
+		})
+	}
+	stopped := func(reason, message string, args ...any) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            fmt.Sprintf(message, args...),
+		})
+	}
+
+	// NOTE: CRD validation also rejects this.
+	if spec == nil {
+		stopped("PGUpgradeInvalid", "Method %s requires logicalReplication settings",
+			v1beta1.PGUpgradeMethodLogicalReplication)
+		return ctrl.Result{}, nil
+	}
+
+	// The source continues to run at the "from" version throughout.
+	if version := source.Spec.PostgresVersion; version != int(upgrade.Spec.FromPostgresVersion) {
+		stopped("PGUpgradeInvalidForCluster",
+			"Current postgres version is %d, but upgrade expected %d",
+			version, upgrade.Spec.FromPostgresVersion)
+		return ctrl.Result{}, nil
+	}
+
+	setStatusToProgressingIfReasonWas("PGUpgradeInvalidForCluster", upgrade)
+
+	// See the same check in [PGUpgradeReconciler.Reconcile].
+	if source.GetAnnotations()[AnnotationAllowUpgrade] != upgrade.Name {
+		stopped("PGClusterMissingRequiredAnnotation",
+			"PostgresCluster %s lacks annotation for upgrade %s",
+			upgrade.Spec.PostgresClusterName, upgrade.GetName())
+		return ctrl.Result{}, nil
+	}
+
+	setStatusToProgressingIfReasonWas("PGClusterMissingRequiredAnnotation", upgrade)
+
+	if source.Spec.Shutdown != nil && *source.Spec.Shutdown {
+		stopped("PGClusterShutdown",
+			"PostgresCluster %s must be running to replicate its data",
+			upgrade.Spec.PostgresClusterName)
+		return ctrl.Result{}, nil
+	}
+
+	setStatusToProgressingIfReasonWas("PGClusterShutdown", upgrade)
+
+	// Never take over a cluster that this upgrade did not create.
+	if target != nil && target.GetLabels()[LabelPGUpgrade] != upgrade.Name {
+		stopped("PGClusterTargetExists",
+			"PostgresCluster %s already exists", spec.ClusterName)
+		return ctrl.Result{}, nil
+	}
+
+	setStatusToProgressingIfReasonWas("PGClusterTargetExists", upgrade)
+
+	schemaJob := world.Jobs[logicalJob(upgrade, logicalSchema).Name]
+	cutoverJob := world.Jobs[logicalJob(upgrade, logicalCutover).Name]
+	schemaComplete := schemaJob != nil && jobCompleted(schemaJob)
+	cutoverComplete := cutoverJob != nil && jobCompleted(cutoverJob)
+
+	if (schemaJob != nil && jobFailed(schemaJob)) || (cutoverJob != nil && jobFailed(cutoverJob)) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeSucceeded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradeFailed",
+			Message:            "Upgrade jobs failed, please check individual pod logs",
+		})
+
+		// Let connections to the source start again.
+		return ctrl.Result{}, errors.WithStack(r.apply(ctx,
+			generateLogicalSource(upgrade, source, false, false)))
+	}
+
+	// Stop writes to the source once the target is streaming its changes.
+	// The source stops connections, and the cutover Job waits for that. They
+	// stay stopped while the source is redirected to the target.
+	// The source does not publish every table until each has a replica
+	// identity. It reports the others in a condition.
+	unidentified := meta.FindStatusCondition(source.Status.Conditions, v1beta1.MissingReplicaIdentity)
+	incomplete := cutoverJob == nil && unidentified != nil && unidentified.Status == metav1.ConditionTrue
+
+	streaming := target != nil && logicalStreaming(upgrade, target)
+	freeze := cutoverJob != nil || (schemaComplete && streaming && !incomplete)
+
+	err := r.copyLogicalSecrets(ctx, upgrade, source)
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx,
+			generateLogicalSource(upgrade, source, freeze && !cutoverComplete, cutoverComplete)))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx,
+			generateLogicalTarget(upgrade, source, schemaComplete && !cutoverComplete)))
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch {
+	case incomplete:
+		stopped("PGClusterMissingReplicaIdentity",
+			"PostgresCluster %s cannot publish every table: %s",
+			source.Name, unidentified.Message)
+
+	case target == nil || target.Status.DatabaseRevision == "":
+		progressing("PGClusterTargetNotReady",
+			"Waiting for PostgresCluster %s to initialize", spec.ClusterName)
+
+	case !schemaComplete:
+		progressing("PGUpgradeCopyingSchema",
+			"Copying the schema of %s to %s", source.Name, spec.ClusterName)
+
+		err = errors.WithStack(r.apply(ctx, r.generateLogicalJob(
+			upgrade, source, logicalSchema, logicalSchemaCommand(upgrade, source))))
+
+	case !freeze:
+		progressing("PGUpgradeSynchronizing",
+			"Copying the data of %s to %s", source.Name, spec.ClusterName)
+
+	case !cutoverComplete:
+		progressing("PGUpgradeCuttingOver",
+			"Writes to %s are stopped while %s receives the last changes",
+			source.Name, spec.ClusterName)
+
+		err = errors.WithStack(r.apply(ctx, r.generateLogicalJob(
+			upgrade, source, logicalCutover, logicalCutoverCommand(upgrade))))
+
+	default:
+		stopped("PGUpgradeCompleted",
+			"PostgresCluster %s is running version %d",
+			spec.ClusterName, upgrade.Spec.ToPostgresVersion)
+
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeSucceeded,
+			Status:             metav1.ConditionTrue,
+			Reason:             "PGUpgradeSucceeded",
+			Message: fmt.Sprintf(
+				"Connections to PostgresCluster %s go to %s at version %d",
+				upgrade.Spec.PostgresClusterName, spec.ClusterName,
+				upgrade.Spec.ToPostgresVersion),
+		})
+	}
+
+	return ctrl.Result{}, err
+}
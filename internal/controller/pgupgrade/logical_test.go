@@ -0,0 +1,452 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func logicalTestUpgrade(t testing.TB) *v1beta1.PGUpgrade {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.UID = "uid3"
+	require.UnmarshalInto(t, &upgrade.Spec, `{
+		image: img4,
+		postgresClusterName: pg5,
+		fromPostgresVersion: 16,
+		toPostgresVersion: 17,
+		method: LogicalReplication,
+		logicalReplication: {
+			clusterName: pg6, databases: [app, other], user: postgres,
+		},
+	}`)
+	return upgrade
+}
+
+func TestGenerateLogicalSource(t *testing.T) {
+	upgrade := logicalTestUpgrade(t)
+
+	source := v1beta1.NewPostgresCluster()
+	source.Namespace = "ns1"
+	source.Name = "pg5"
+	source.Spec.PostgresVersion = 16
+
+	t.Run("Publish", func(t *testing.T) {
+		intent := generateLogicalSource(upgrade, source, false, false)
+		assert.Assert(t, cmp.MarshalMatches(intent.ObjectMeta, `
+creationTimestamp: null
+name: pg5
+namespace: ns1
+		`))
+		assert.Assert(t, intent.Spec.Config == nil)
+		assert.Assert(t, cmp.MarshalMatches(intent.Spec.Publications, `
+- database: app
+  name: pgupgrade_0
+- database: other
+  name: pgupgrade_1
+		`))
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		intent := generateLogicalSource(upgrade, source, true, false)
+		assert.Assert(t, cmp.MarshalMatches(intent.ObjectMeta, `
+annotations:
+  postgres-operator.crunchydata.com/stop-connections: '["app","other"]'
+creationTimestamp: null
+name: pg5
+namespace: ns1
+		`))
+	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		intent := generateLogicalSource(upgrade, source, false, true)
+		assert.Assert(t, cmp.MarshalMatches(intent.ObjectMeta, `
+annotations:
+  postgres-operator.crunchydata.com/redirect-primary: pg6
+creationTimestamp: null
+name: pg5
+namespace: ns1
+		`))
+		assert.Assert(t, intent.Spec.Config == nil,
+			"expected the cutover Job to stop writes, not a setting")
+	})
+}
+
+func TestGenerateLogicalTarget(t *testing.T) {
+	upgrade := logicalTestUpgrade(t)
+
+	source := v1beta1.NewPostgresCluster()
+	source.Namespace = "ns1"
+	source.Name = "pg5"
+	require.UnmarshalInto(t, &source.Spec, `{
+		postgresVersion: 16,
+		image: old-image,
+		instances: [{ name: one, dataVolumeClaimSpec: { accessModes: [ReadWriteOnce] } }],
+		config: { parameters: { work_mem: 4MB } },
+		service: { type: NodePort, nodePort: 32000 },
+		shutdown: false,
+		publications: [{ name: mine, database: app }],
+		backups: { pgbackrest: {
+			global: { repo1-retention-full: "2", repo2-s3-uri-style: path },
+			repos: [
+				{ name: repo1, volume: { volumeClaimSpec: { accessModes: [ReadWriteOnce] } } },
+				{ name: repo2, s3: { bucket: b, endpoint: e, region: r } },
+			],
+		} },
+	}`)
+
+	t.Run("Copy", func(t *testing.T) {
+		intent := generateLogicalTarget(upgrade, source, false)
+
+		assert.Assert(t, cmp.MarshalMatches(intent.ObjectMeta, `
+creationTimestamp: null
+labels:
+  postgres-operator.crunchydata.com/pgupgrade: pgu2
+name: pg6
+namespace: ns1
+		`))
+		assert.Equal(t, intent.Spec.PostgresVersion, 17)
+		assert.Equal(t, intent.Spec.Image, "", "expected the default image")
+		assert.Assert(t, intent.Spec.Shutdown == nil)
+		assert.Assert(t, intent.Spec.Publications == nil)
+		assert.Assert(t, intent.Spec.Subscriptions == nil)
+		assert.Assert(t, intent.Spec.Service.NodePort == nil)
+
+		assert.Assert(t, cmp.MarshalMatches(intent.Spec.Users, `
+- databases:
+  - pg5
+  name: pg5
+		`))
+		assert.Assert(t, cmp.MarshalMatches(intent.Spec.Config.Parameters, `
+work_mem: 4MB
+		`))
+		assert.Assert(t, cmp.MarshalMatches(intent.Spec.Backups.PGBackRest.Global, `
+repo1-retention-full: "2"
+		`))
+		assert.Equal(t, len(intent.Spec.Backups.PGBackRest.Repos), 1)
+		assert.Equal(t, intent.Spec.Backups.PGBackRest.Repos[0].Name, "repo1")
+
+		// The source is unchanged.
+		assert.Equal(t, len(source.Spec.Backups.PGBackRest.Repos), 2)
+		assert.Equal(t, len(source.Spec.Backups.PGBackRest.Global), 2)
+	})
+
+	t.Run("Subscribe", func(t *testing.T) {
+		upgrade := upgrade.DeepCopy()
+		upgrade.Spec.LogicalReplication.Image = initialize.Pointer("new-image")
+
+		intent := generateLogicalTarget(upgrade, source, true)
+		assert.Equal(t, intent.Spec.Image, "new-image")
+		assert.Assert(t, cmp.MarshalMatches(intent.Spec.Subscriptions, `
+- database: app
+  name: pgupgrade_0
+  publications:
+  - pgupgrade_0
+  source:
+    clusterName: pg5
+    user: postgres
+- database: other
+  name: pgupgrade_1
+  publications:
+  - pgupgrade_1
+  source:
+    clusterName: pg5
+    user: postgres
+		`))
+	})
+
+	t.Run("OnlyCloudRepos", func(t *testing.T) {
+		source := source.DeepCopy()
+		source.Spec.Backups.PGBackRest.Repos = source.Spec.Backups.PGBackRest.Repos[1:]
+
+		intent := generateLogicalTarget(upgrade, source, false)
+		assert.DeepEqual(t, intent.Spec.Backups, v1beta1.Backups{})
+	})
+}
+
+func TestLogicalStreaming(t *testing.T) {
+	upgrade := logicalTestUpgrade(t)
+	target := v1beta1.NewPostgresCluster()
+
+	assert.Assert(t, !logicalStreaming(upgrade, target))
+
+	target.Status.Subscriptions = []v1beta1.PostgresSubscriptionStatus{
+		{Name: "pgupgrade_0", State: v1beta1.PostgresSubscriptionStreaming},
+		{Name: "pgupgrade_1", State: v1beta1.PostgresSubscriptionSynchronizing},
+	}
+	assert.Assert(t, !logicalStreaming(upgrade, target))
+
+	target.Status.Subscriptions[1].State = v1beta1.PostgresSubscriptionStreaming
+	assert.Assert(t, logicalStreaming(upgrade, target))
+}
+
+func TestLogicalCommands(t *testing.T) {
+	upgrade := logicalTestUpgrade(t)
+
+	source := v1beta1.NewPostgresCluster()
+	source.Namespace, source.Name = "ns1", "pg5"
+
+	// Roles are copied before the schema that refers to them. Passwords of
+	// roles that the operator manages stay as they are in the target.
+	schema := logicalSchemaCommand(upgrade, source)[3]
+	assert.Assert(t, cmp.Contains(schema, `pg_dumpall --roles-only --no-role-passwords`))
+	assert.Assert(t, strings.Index(schema, `pg_dumpall`) < strings.Index(schema, `pg_dump --dbname`))
+	assert.Assert(t, cmp.Contains(schema, `rolname NOT IN`))
+
+	// Copying the schema stops at the first error.
+	assert.Assert(t, cmp.Contains(schema,
+		`psql --dbname="${database}" --no-psqlrc --quiet --set=ON_ERROR_STOP=1`))
+
+	// Writes stop with a connection limit that sessions cannot override. The
+	// source changes it so that it can restore it.
+	cutover := logicalCutoverCommand(upgrade)[3]
+	assert.Assert(t, cmp.Contains(cutover, `SELECT datconnlimit`))
+	assert.Assert(t, !strings.Contains(cutover, `CONNECTION LIMIT`))
+	assert.Assert(t, !strings.Contains(cutover, `default_transaction_read_only`))
+
+	for _, tt := range []struct {
+		Name    string
+		Command []string
+		Args    []string
+	}{
+		{
+			Name:    "Schema",
+			Command: logicalSchemaCommand(upgrade, source),
+			Args: []string{"schema", "17",
+				`["postgres","_crunchypgbouncer","ccp_monitoring","_crunchyrepl","pg5"]`,
+				"app", "other"},
+		},
+		{
+			Name:    "Cutover",
+			Command: logicalCutoverCommand(upgrade),
+			Args:    []string{"cutover", "17", "pgupgrade_0", "app", "pgupgrade_1", "other"},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Assert(t, len(tt.Command) > 3)
+			assert.DeepEqual(t, []string{"bash", "-ceu", "--"}, tt.Command[:3])
+			assert.DeepEqual(t, tt.Args, tt.Command[4:])
+
+			script := tt.Command[3]
+
+			t.Run("PrettyYAML", func(t *testing.T) {
+				b, err := yaml.Marshal(script)
+				assert.NilError(t, err)
+				assert.Assert(t, strings.HasPrefix(string(b), `|`),
+					"expected literal block scalar, got:\n%s", b)
+			})
+
+			t.Run("ShellCheck", func(t *testing.T) {
+				shellcheck := require.ShellCheck(t)
+
+				// Write out that inline script.
+				dir := t.TempDir()
+				file := filepath.Join(dir, "script.bash")
+				assert.NilError(t, os.WriteFile(file, []byte(script), 0o600))
+
+				// Expect shellcheck to be happy.
+				cmd := exec.Command(shellcheck, "--enable=all", "--shell=bash", file)
+				output, err := cmd.CombinedOutput()
+				assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+			})
+		})
+	}
+}
+
+func TestGenerateLogicalJob(t *testing.T) {
+	reconciler := &PGUpgradeReconciler{}
+	upgrade := logicalTestUpgrade(t)
+
+	source := v1beta1.NewPostgresCluster()
+	source.Namespace = "ns1"
+	source.Name = "pg5"
+	source.Spec.Port = initialize.Int32(5432)
+	require.UnmarshalInto(t, &source.Spec.Users, `[
+		{ name: postgres, password: { secretKeyRef: { name: shared, key: pw } } },
+	]`)
+
+	job := reconciler.generateLogicalJob(upgrade, source, logicalCutover, []string{"true"})
+	assert.Assert(t, cmp.MarshalMatches(job, `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  annotations:
+    kubectl.kubernetes.io/default-container: database
+  creationTimestamp: null
+  labels:
+    postgres-operator.crunchydata.com/cluster: pg5
+    postgres-operator.crunchydata.com/pgupgrade: pgu2
+    postgres-operator.crunchydata.com/role: logical-cutover
+    postgres-operator.crunchydata.com/version: "17"
+  name: pgu2-logical-cutover
+  namespace: ns1
+  ownerReferences:
+  - apiVersion: postgres-operator.crunchydata.com/v1beta1
+    blockOwnerDeletion: true
+    controller: true
+    kind: PGUpgrade
+    name: pgu2
+    uid: uid3
+spec:
+  backoffLimit: 0
+  template:
+    metadata:
+      annotations:
+        kubectl.kubernetes.io/default-container: database
+      creationTimestamp: null
+      labels:
+        postgres-operator.crunchydata.com/cluster: pg5
+        postgres-operator.crunchydata.com/pgupgrade: pgu2
+        postgres-operator.crunchydata.com/role: logical-cutover
+        postgres-operator.crunchydata.com/version: "17"
+    spec:
+      containers:
+      - command:
+        - "true"
+        env:
+        - name: PGCONNECT_TIMEOUT
+          value: "10"
+        - name: PGPORT
+          value: "5432"
+        - name: PGSSLMODE
+          value: require
+        - name: PGUSER
+          value: postgres
+        - name: SOURCE_HOST
+          value: pg5-primary.ns1.svc
+        - name: SOURCE_PASSWORD
+          valueFrom:
+            secretKeyRef:
+              key: pw
+              name: shared
+        - name: TARGET_HOST
+          value: pg6-primary.ns1.svc
+        - name: TARGET_PASSWORD
+          valueFrom:
+            secretKeyRef:
+              key: pw
+              name: shared
+        image: img4
+        name: database
+        resources: {}
+        securityContext:
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+          privileged: false
+          readOnlyRootFilesystem: true
+          runAsNonRoot: true
+          seccompProfile:
+            type: RuntimeDefault
+      restartPolicy: Never
+      securityContext:
+        fsGroupChangePolicy: OnRootMismatch
+status: {}
+	`))
+
+	t.Run("GeneratedPasswords", func(t *testing.T) {
+		source := source.DeepCopy()
+		source.Spec.Users[0].Password = nil
+
+		job := reconciler.generateLogicalJob(upgrade, source, logicalSchema, []string{"true"})
+		env := job.Spec.Template.Spec.Containers[0].Env
+		assert.Equal(t, env[5].ValueFrom.SecretKeyRef.Name, "pg5-pguser-postgres")
+		assert.Equal(t, env[5].ValueFrom.SecretKeyRef.Key, "password")
+		assert.Equal(t, env[7].ValueFrom.SecretKeyRef.Name, "pg6-pguser-postgres")
+		assert.Equal(t, env[7].ValueFrom.SecretKeyRef.Key, "password")
+	})
+}
+
+func TestReconcileLogicalReplication(t *testing.T) {
+	ctx := context.Background()
+
+	source := v1beta1.NewPostgresCluster()
+	source.Namespace, source.Name = "ns1", "pg5"
+	source.Annotations = map[string]string{AnnotationAllowUpgrade: "pgu2"}
+	source.Spec.PostgresVersion = 16
+	source.Spec.Port = initialize.Int32(5432)
+
+	reason := func(upgrade *v1beta1.PGUpgrade) string {
+		return meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeProgressing).Reason
+	}
+
+	t.Run("MissingReplicaIdentity", func(t *testing.T) {
+		cc := &applyClient{Client: fake.NewClientBuilder().WithScheme(runtime.Scheme).Build()}
+		reconciler := &PGUpgradeReconciler{Client: cc}
+		upgrade := logicalTestUpgrade(t)
+
+		world := NewWorld()
+		world.Cluster = source.DeepCopy()
+		world.Cluster.Status.Conditions = []metav1.Condition{{
+			Type:    v1beta1.MissingReplicaIdentity,
+			Status:  metav1.ConditionTrue,
+			Message: "table public.events in database app",
+		}}
+
+		_, err := reconciler.reconcileLogicalReplication(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGClusterMissingReplicaIdentity")
+		assert.Assert(t, cmp.Contains(
+			meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeProgressing).Message,
+			"table public.events in database app"))
+
+		// Every table is published once the condition clears.
+		world.Cluster.Status.Conditions = nil
+		_, err = reconciler.reconcileLogicalReplication(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGClusterTargetNotReady")
+	})
+
+	t.Run("CutoverFailed", func(t *testing.T) {
+		cc := &applyClient{Client: fake.NewClientBuilder().WithScheme(runtime.Scheme).Build()}
+		reconciler := &PGUpgradeReconciler{Client: cc}
+		upgrade := logicalTestUpgrade(t)
+
+		job := &batchv1.Job{ObjectMeta: logicalJob(upgrade, logicalCutover)}
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type: batchv1.JobFailed, Status: corev1.ConditionTrue,
+		}}
+
+		world := NewWorld()
+		world.Cluster = source.DeepCopy()
+		world.Jobs[job.Name] = job
+
+		_, err := reconciler.reconcileLogicalReplication(ctx, upgrade, world)
+		assert.NilError(t, err)
+
+		succeeded := meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeSucceeded)
+		assert.Assert(t, succeeded != nil)
+		assert.Equal(t, succeeded.Reason, "PGUpgradeFailed")
+
+		// Connections to the source start again.
+		assert.Equal(t, len(cc.applied), 1)
+		intent, ok := cc.applied[0].(*v1beta1.PostgresCluster)
+		assert.Assert(t, ok)
+		assert.Equal(t, intent.Name, "pg5")
+		assert.Assert(t, intent.Annotations == nil)
+		assert.Equal(t, len(intent.Spec.Publications), 2)
+	})
+}
@@ -7,6 +7,8 @@ package pgupgrade
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
@@ -20,6 +22,7 @@ import (
 
 	"github.com/crunchydata/postgres-operator/internal/config"
 	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/registration"
 	"github.com/crunchydata/postgres-operator/internal/tracing"
@@ -32,8 +35,12 @@ const (
 
 // PGUpgradeReconciler reconciles a PGUpgrade object
 type PGUpgradeReconciler struct {
-	Client client.Client
-	Owner  client.FieldOwner
+	Client  client.Client
+	Owner   client.FieldOwner
+	PodExec func(
+		ctx context.Context, namespace, pod, container string,
+		stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error
 
 	Recorder     record.EventRecorder
 	Registration registration.Registration
@@ -45,6 +52,14 @@ type PGUpgradeReconciler struct {
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *PGUpgradeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.PodExec == nil {
+		var err error
+		r.PodExec, err = runtime.NewPodExecutor(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.PGUpgrade{}).
 		Owns(&batchv1.Job{}).
@@ -59,7 +74,8 @@ func (r *PGUpgradeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 //+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgupgrades",verbs={list}
 
-// findUpgradesForPostgresCluster returns PGUpgrades that target cluster.
+// findUpgradesForPostgresCluster returns PGUpgrades that target cluster or
+// replace it using logical replication.
 func (r *PGUpgradeReconciler) findUpgradesForPostgresCluster(
 	ctx context.Context, cluster client.ObjectKey,
 ) []*v1beta1.PGUpgrade {
@@ -74,7 +90,9 @@ func (r *PGUpgradeReconciler) findUpgradesForPostgresCluster(
 		Namespace: cluster.Namespace,
 	}) == nil {
 		for i := range upgrades.Items {
-			if upgrades.Items[i].Spec.PostgresClusterName == cluster.Name {
+			spec := upgrades.Items[i].Spec
+			if spec.PostgresClusterName == cluster.Name ||
+				(spec.LogicalReplication != nil && spec.LogicalReplication.ClusterName == cluster.Name) {
 				matching = append(matching, &upgrades.Items[i])
 			}
 		}
@@ -141,7 +159,9 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	// the succeeded condition and remove upgrade and removedata jobs.
 	succeeded := meta.FindStatusCondition(upgrade.Status.Conditions,
 		ConditionPGUpgradeSucceeded)
-	if succeeded != nil && succeeded.Reason == "PGUpgradeSucceeded" {
+	// The same is true after a rollback.
+	if succeeded != nil && (succeeded.Reason == "PGUpgradeSucceeded" ||
+		succeeded.Reason == "PGUpgradeRolledBack") {
 		return
 	}
 
@@ -224,6 +244,29 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	setStatusToProgressingIfReasonWas("PGClusterNotFound", upgrade)
 
+	// A logical replication upgrade leaves the cluster running and replaces
+	// it with another; none of the steps below apply.
+	if upgrade.Spec.Method == v1beta1.PGUpgradeMethodLogicalReplication {
+		result, err = r.reconcileLogicalReplication(ctx, upgrade, world)
+
+		log.Info("Reconciled", "requeue", !result.IsZero() || err != nil)
+		return
+	}
+
+	// A check leaves the cluster running and changes none of its volumes.
+	if upgrade.Spec.Check != nil {
+		result, err = r.reconcileCheck(ctx, upgrade, world)
+
+		log.Info("Reconciled", "requeue", !result.IsZero() || err != nil)
+		return
+	}
+
+	// The results of an earlier check do not describe this upgrade.
+	setStatusToProgressingIfReasonWas("PGUpgradeCheckCompleted", upgrade)
+	if succeeded != nil && strings.HasPrefix(succeeded.Reason, "PGUpgradeCheck") {
+		meta.RemoveStatusCondition(&upgrade.Status.Conditions, ConditionPGUpgradeSucceeded)
+	}
+
 	// Get the spec version to check if this cluster is at the requested version
 	version := int64(world.Cluster.Spec.PostgresVersion)
 
@@ -238,7 +281,7 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	var removeDataJobsFailed bool
 	var removeDataJobsCompleted []*batchv1.Job
 	for _, job := range world.Jobs {
-		if job.GetLabels()[LabelRole] == removeData {
+		if role := job.GetLabels()[LabelRole]; role == removeData || role == pgUpgradeReplica {
 			if jobCompleted(job) {
 				removeDataJobsCompleted = append(removeDataJobsCompleted, job)
 			} else if jobFailed(job) {
@@ -249,6 +292,12 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 	removeDataJobsComplete := len(removeDataJobsCompleted) == world.ReplicasExpected
 
+	// Replicas keep their data when it is retained; see [PGUpgradeReconciler.reconcileRetention].
+	if upgrade.Spec.Retention != nil &&
+		upgrade.Spec.ReplicaMethod != v1beta1.PGUpgradeReplicaMethodRsync {
+		removeDataJobsComplete = true
+	}
+
 	// If the PostgresCluster is already set to the desired version, but the upgradejob has
 	// not completed successfully, the operator assumes that the cluster is already
 	// running the desired version. We consider this a no-op rather than a successful upgrade.
@@ -272,17 +321,35 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	setStatusToProgressingIfReasonWas("PGUpgradeResolved", upgrade)
 
 	if statusVersion == int64(upgrade.Spec.ToPostgresVersion) {
-		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
-			ObservedGeneration: upgrade.Generation,
-			Type:               ConditionPGUpgradeProgressing,
-			Status:             metav1.ConditionFalse,
-			Reason:             "PGUpgradeCompleted",
-			Message: fmt.Sprintf(
-				"PostgresCluster %s is running version %d",
-				upgrade.Spec.PostgresClusterName, upgrade.Spec.ToPostgresVersion),
-		})
+		// A rollback does not wait for anything else.
+		if upgradeJobComplete && upgrade.Spec.Retention != nil &&
+			upgrade.Spec.Retention.Action == v1beta1.PGUpgradeRetentionRollback {
+			return r.reconcileRollback(ctx, upgrade, world)
+		}
 
-		if upgradeJobComplete && removeDataJobsComplete {
+		// Run any tasks that need the cluster at the new version before
+		// declaring success.
+		done := true
+		if upgradeJobComplete && removeDataJobsComplete && upgrade.Spec.PostUpgrade != nil {
+			done, result, err = r.reconcilePostUpgrade(ctx, upgrade, world)
+		}
+		if done && err == nil && upgradeJobComplete && upgrade.Spec.Retention != nil {
+			done, result, err = r.reconcileRetention(ctx, upgrade, world)
+		}
+
+		if done {
+			meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+				ObservedGeneration: upgrade.Generation,
+				Type:               ConditionPGUpgradeProgressing,
+				Status:             metav1.ConditionFalse,
+				Reason:             "PGUpgradeCompleted",
+				Message: fmt.Sprintf(
+					"PostgresCluster %s is running version %d",
+					upgrade.Spec.PostgresClusterName, upgrade.Spec.ToPostgresVersion),
+			})
+		}
+
+		if upgradeJobComplete && removeDataJobsComplete && done {
 			meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
 				ObservedGeneration: upgrade.Generation,
 				Type:               ConditionPGUpgradeSucceeded,
@@ -294,7 +361,7 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			})
 		}
 
-		return ctrl.Result{}, nil
+		return result, err
 	}
 
 	// The upgrade needs to manipulate the data directory of the primary while
@@ -395,23 +462,7 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	if upgradeJobComplete && removeDataJobsComplete &&
 		statusVersion != int64(upgrade.Spec.ToPostgresVersion) {
 
-		// Patroni will try to recreate replicas using pgBackRest. Convince PGO to
-		// take a recent backup by deleting its "replica-create" jobs.
-		for _, object := range world.Jobs {
-			if backup := object.Labels[LabelPGBackRestBackup]; err == nil &&
-				backup == ReplicaCreate {
-
-				uid := object.GetUID()
-				version := object.GetResourceVersion()
-				exactly := client.Preconditions{UID: &uid, ResourceVersion: &version}
-				// Jobs default to an `orphanDependents` policy, orphaning pods after deletion.
-				// We don't want that, so we set the delete policy explicitly.
-				// - https://kubernetes.io/docs/concepts/workloads/controllers/job/
-				// - https://github.com/kubernetes/kubernetes/blob/master/pkg/registry/batch/job/strategy.go#L58
-				propagate := client.PropagationPolicy(metav1.DeletePropagationBackground)
-				err = client.IgnoreNotFound(r.Client.Delete(ctx, object, exactly, propagate))
-			}
-		}
+		err = r.deleteReplicaCreateJobs(ctx, world)
 
 		if err == nil {
 			patch := world.Cluster.DeepCopy()
@@ -430,22 +481,38 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
+	// Before the upgrade Job exists, remember the images for a rollback and
+	// take any snapshots of the primary.
+	if upgradeJob == nil {
+		upgrade.Status.PreviousImage = initialize.String(world.Cluster.Spec.Image)
+		upgrade.Status.PreviousInstanceImages = make(map[string]string)
+		for _, set := range world.Cluster.Spec.InstanceSets {
+			if set.Image != "" {
+				upgrade.Status.PreviousInstanceImages[set.Name] = set.Image
+			}
+		}
+
+		if upgrade.Spec.Retention != nil {
+			var done bool
+			done, result, err = r.reconcileRetainedSnapshots(ctx, upgrade, world)
+			if !done || err != nil {
+				return
+			}
+		}
+	}
+
 	// TODO: error from apply could mean that the job exists with a different spec.
 	if err == nil && !upgradeJobComplete {
 		err = errors.WithStack(r.apply(ctx,
 			r.generateUpgradeJob(ctx, upgrade, world.ClusterPrimary, config.FetchKeyCommand(&world.Cluster.Spec))))
 	}
 
-	// Create the jobs to remove the data from the replicas, as long as
-	// the upgrade job has completed.
+	// Create the jobs to remove the data from the replicas, or to upgrade it
+	// in place, as long as the upgrade job has completed.
 	// (When the cluster is not shutdown, the `world.ClusterReplicas` will be [],
 	// so there should be no danger of accidentally targeting the primary.)
 	if err == nil && upgradeJobComplete && !removeDataJobsComplete {
-		for _, sts := range world.ClusterReplicas {
-			if err == nil {
-				err = r.apply(ctx, r.generateRemoveDataJob(ctx, upgrade, sts))
-			}
-		}
+		result, err = r.reconcileReplicaJobs(ctx, upgrade, world)
 	}
 
 	// The upgrade job generates a new system identifier for this cluster.
@@ -477,6 +544,32 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	return
 }
 
+// deleteReplicaCreateJobs deletes the "replica-create" backup Jobs of the
+// cluster in world.
+func (r *PGUpgradeReconciler) deleteReplicaCreateJobs(ctx context.Context, world *World) error {
+	var err error
+
+	// Patroni will try to recreate replicas using pgBackRest. Convince PGO to
+	// take a recent backup by deleting its "replica-create" jobs.
+	for _, object := range world.Jobs {
+		if backup := object.Labels[LabelPGBackRestBackup]; err == nil &&
+			backup == ReplicaCreate {
+
+			uid := object.GetUID()
+			version := object.GetResourceVersion()
+			exactly := client.Preconditions{UID: &uid, ResourceVersion: &version}
+			// Jobs default to an `orphanDependents` policy, orphaning pods after deletion.
+			// We don't want that, so we set the delete policy explicitly.
+			// - https://kubernetes.io/docs/concepts/workloads/controllers/job/
+			// - https://github.com/kubernetes/kubernetes/blob/master/pkg/registry/batch/job/strategy.go#L58
+			propagate := client.PropagationPolicy(metav1.DeletePropagationBackground)
+			err = client.IgnoreNotFound(r.Client.Delete(ctx, object, exactly, propagate))
+		}
+	}
+
+	return err
+}
+
 func setStatusToProgressingIfReasonWas(reason string, upgrade *v1beta1.PGUpgrade) {
 	progressing := meta.FindStatusCondition(upgrade.Status.Conditions,
 		ConditionPGUpgradeProgressing)
@@ -0,0 +1,311 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// updateExtensionsFile is the script pg_upgrade writes to its current
+// directory when extensions need to be updated. The upgrade Job runs in the
+// data volume of the primary; see [upgradeCommand].
+const updateExtensionsFile = "/pgdata/update_extensions.sql"
+
+// postUpgradeJob returns the ObjectMeta for a Job that runs a task after an
+// upgrade.
+func postUpgradeJob(upgrade *v1beta1.PGUpgrade, role string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-" + role,
+	}
+}
+
+// analyzeCommand returns an entrypoint that collects planner statistics in
+// every database using vacuumdb. Statistics are not transferred by pg_upgrade,
+// and "--analyze-in-stages" produces some useful ones quickly before it
+// collects the rest.
+// - https://www.postgresql.org/docs/current/pgupgrade.html
+// - https://www.postgresql.org/docs/current/app-vacuumdb.html
+func analyzeCommand(upgrade *v1beta1.PGUpgrade) []string {
+	script := strings.Join([]string{
+		`declare -r new_version="$1"`,
+		`/usr/pgsql-"${new_version}"/bin/vacuumdb --all --analyze-in-stages --maintenance-db=postgres`,
+		`echo -e "\nAnalyze Job Complete!"`,
+	}, "\n")
+
+	return []string{"bash", "-ceu", "--", script, "analyze",
+		fmt.Sprint(upgrade.Spec.ToPostgresVersion)}
+}
+
+// updateExtensionsCommand returns an entrypoint that runs the script
+// pg_upgrade generated, if any, then updates every extension that is not at
+// its default version in every database. Extensions in the spec of cluster
+// are skipped; the PostgresCluster controller keeps them at their version.
+// - https://www.postgresql.org/docs/current/sql-alterextension.html
+func updateExtensionsCommand(upgrade *v1beta1.PGUpgrade, cluster *v1beta1.PostgresCluster) []string {
+	declared := struct {
+		Cluster   []string            `json:"cluster"`
+		Databases map[string][]string `json:"databases"`
+	}{
+		Cluster:   []string{},
+		Databases: map[string][]string{},
+	}
+	for _, extension := range cluster.Spec.Extensions {
+		declared.Cluster = append(declared.Cluster, string(extension.Name))
+	}
+	for _, database := range cluster.Spec.Databases {
+		for _, extension := range database.Extensions {
+			declared.Databases[string(database.Name)] = append(
+				declared.Databases[string(database.Name)], string(extension.Name))
+		}
+	}
+	encoded, _ := json.Marshal(declared)
+
+	script := strings.Join([]string{
+		`declare -r new_version="$1" declared="$2"`,
+		`set -o pipefail`,
+		`on_primary() { /usr/pgsql-"${new_version}"/bin/psql --no-psqlrc --set=ON_ERROR_STOP=1 "$@"; }`,
+
+		// The script is in the data directory of the primary, so read it
+		// through PostgreSQL. It connects to each database it needs.
+		// - https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-GENFILE
+		`echo 'Running the script from pg_upgrade ...'`,
+		`on_primary --dbname=postgres --no-align --tuples-only --set=file="` + updateExtensionsFile + `" <<< \`,
+		`"SELECT pg_catalog.pg_read_file(:'file') WHERE (pg_catalog.pg_stat_file(:'file', true)).size IS NOT NULL" |`,
+		`on_primary --dbname=postgres --quiet`,
+
+		// Return the names of databases that allow connections, including
+		// "template1". Exclude "template0" to ensure it is never manipulated.
+		// See [postgres.Executor.ExecInAllDatabases].
+		`databases="$(on_primary --dbname=postgres --no-align --tuples-only --command=\`,
+		`"SELECT datname FROM pg_catalog.pg_database WHERE datallowconn AND datname NOT IN ('template0')")"`,
+
+		`while IFS= read -r database; do`,
+		`printf 'Updating extensions in database "%s" ...\n' "${database}"`,
+		`PGDATABASE="${database}" on_primary --quiet --set=declared="${declared}" <<'SQL'`,
+
+		// Prevent unexpected dereferences by emptying "search_path".
+		// The "pg_catalog" schema is still searched.
+		// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+		`SET search_path = '';`,
+		`SELECT pg_catalog.format('ALTER EXTENSION %I UPDATE', installed.extname)`,
+		`  FROM pg_catalog.pg_extension AS installed`,
+		`  JOIN pg_catalog.pg_available_extensions AS available ON available.name = installed.extname`,
+		` WHERE installed.extversion IS DISTINCT FROM available.default_version`,
+		`   AND NOT (:'declared'::jsonb -> 'cluster') ? installed.extname`,
+		`   AND NOT COALESCE(:'declared'::jsonb -> 'databases' -> pg_catalog.current_database(), '[]') ? installed.extname`,
+		` ORDER BY installed.extname`,
+		`\gexec`,
+		`SQL`,
+		`done <<< "${databases}"`,
+
+		`echo -e "\nUpdate Extensions Job Complete!"`,
+	}, "\n")
+
+	return []string{"bash", "-ceu", "--", script, "update-extensions",
+		fmt.Sprint(upgrade.Spec.ToPostgresVersion), string(encoded)}
+}
+
+// generatePostUpgradeJob returns a Job that runs command against the primary
+// of cluster after an upgrade.
+func (r *PGUpgradeReconciler) generatePostUpgradeJob(
+	upgrade *v1beta1.PGUpgrade, cluster *v1beta1.PostgresCluster,
+	role string, command []string,
+) *batchv1.Job {
+	job := &batchv1.Job{ObjectMeta: postUpgradeJob(upgrade, role)}
+	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+
+	job.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
+		commonLabels(role, upgrade),
+		map[string]string{
+			LabelVersion: fmt.Sprint(upgrade.Spec.ToPostgresVersion),
+		})
+	job.Annotations = Merge(upgrade.Spec.Metadata.GetAnnotationsOrNil(),
+		map[string]string{
+			naming.DefaultContainerAnnotation: ContainerDatabase,
+		})
+
+	// Use the same labels and annotations as the job.
+	job.Spec.Template.ObjectMeta = metav1.ObjectMeta{
+		Annotations: job.Annotations,
+		Labels:      job.Labels,
+	}
+
+	// Each task can be repeated safely, so let Kubernetes retry it.
+	job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+	// Connect to the primary Service as the user in the spec.
+	service := naming.ClusterPrimaryService(cluster)
+	user := string(upgrade.Spec.PostUpgrade.User)
+	env := []corev1.EnvVar{
+		{Name: "PGCONNECT_TIMEOUT", Value: "10"},
+		{Name: "PGHOST", Value: service.Name + "." + service.Namespace + ".svc"},
+		{Name: "PGPASSWORD", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: logicalPassword(cluster, user),
+		}},
+		{Name: "PGPORT", Value: fmt.Sprint(initialize.FromPointer(cluster.Spec.Port))},
+		{Name: "PGSSLMODE", Value: "require"},
+		{Name: "PGUSER", Value: user},
+	}
+
+	job.Spec.Template.Spec.ImagePullSecrets = upgrade.Spec.ImagePullSecrets
+	job.Spec.Template.Spec.SecurityContext = initialize.PodSecurityContext()
+	job.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name:            ContainerDatabase,
+		Command:         command,
+		Env:             env,
+		Image:           pgUpgradeContainerImage(upgrade),
+		ImagePullPolicy: upgrade.Spec.ImagePullPolicy,
+		Resources:       upgrade.Spec.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+	}}
+
+	// The following will set these fields to null if not set in the spec
+	job.Spec.Template.Spec.Affinity = upgrade.Spec.Affinity
+	job.Spec.Template.Spec.PriorityClassName =
+		initialize.FromPointer(upgrade.Spec.PriorityClassName)
+	job.Spec.Template.Spec.Tolerations = upgrade.Spec.Tolerations
+
+	r.setControllerReference(upgrade, job)
+	return job
+}
+
+// postUpgradeReady returns true when pod is a ready PostgreSQL instance
+// running the image of its instance set in cluster.
+func postUpgradeReady(cluster *v1beta1.PostgresCluster, pod *corev1.Pod) bool {
+	if pod == nil || pod.DeletionTimestamp != nil {
+		return false
+	}
+
+	var spec *v1beta1.PostgresInstanceSetSpec
+	for i := range cluster.Spec.InstanceSets {
+		if cluster.Spec.InstanceSets[i].Name == pod.Labels[naming.LabelInstanceSet] {
+			spec = &cluster.Spec.InstanceSets[i]
+		}
+	}
+
+	var image, ready bool
+	for _, container := range pod.Spec.Containers {
+		if container.Name == ContainerDatabase {
+			image = container.Image == config.PostgresInstanceImage(cluster, spec)
+		}
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			ready = condition.Status == corev1.ConditionTrue
+		}
+	}
+	return image && ready
+}
+
+//+kubebuilder:rbac:groups="batch",resources="jobs",verbs={create,patch}
+
+// reconcilePostUpgrade runs the tasks in the postUpgrade field of upgrade one
+// at a time in Jobs that connect to the primary of the cluster. It returns
+// true when they are done. The Progressing condition names the task whose Job
+// is running, and the condition of each task reports how its Job finished.
+func (r *PGUpgradeReconciler) reconcilePostUpgrade(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, world *World,
+) (bool, ctrl.Result, error) {
+	cluster, pod := world.Cluster, world.ClusterLeaderPod
+	spec := upgrade.Spec.PostUpgrade
+
+	progressing := func(reason, message string, args ...any) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            fmt.Sprintf(message, args...),
+		})
+	}
+
+	// The tasks connect to PostgreSQL, so wait for the user to start the
+	// cluster at the new version.
+	if cluster.Spec.PostgresVersion != int(upgrade.Spec.ToPostgresVersion) ||
+		initialize.FromPointer(cluster.Spec.Shutdown) || !postUpgradeReady(cluster, pod) {
+		progressing("PGClusterNotRunning",
+			"Start PostgresCluster %s at version %d to finish the upgrade",
+			upgrade.Spec.PostgresClusterName, upgrade.Spec.ToPostgresVersion)
+		return false, ctrl.Result{}, nil
+	}
+
+	for _, task := range []struct {
+		enabled               bool
+		condition, role       string
+		reason, done, message string
+		command               []string
+	}{
+		{
+			enabled:   initialize.FromPointer(spec.UpdateExtensions),
+			condition: ConditionPGUpgradeExtensionsUpdated,
+			role:      pgUpgradeExtensions,
+			reason:    "PGUpgradeUpdatingExtensions",
+			done:      "PGUpgradeExtensionsUpdated",
+			message:   "Updating extensions in every database of PostgresCluster %s",
+			command:   updateExtensionsCommand(upgrade, cluster),
+		},
+		{
+			enabled:   initialize.FromPointer(spec.Analyze),
+			condition: ConditionPGUpgradeAnalyzed,
+			role:      pgUpgradeAnalyze,
+			reason:    "PGUpgradeAnalyzing",
+			done:      "PGUpgradeAnalyzed",
+			message:   "Collecting planner statistics in every database of PostgresCluster %s",
+			command:   analyzeCommand(upgrade),
+		},
+	} {
+		if !task.enabled || meta.IsStatusConditionTrue(upgrade.Status.Conditions, task.condition) {
+			continue
+		}
+
+		job := world.Jobs[postUpgradeJob(upgrade, task.role).Name]
+
+		switch {
+		case job != nil && jobFailed(job):
+			meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+				ObservedGeneration: upgrade.Generation,
+				Type:               task.condition,
+				Status:             metav1.ConditionFalse,
+				Reason:             "PGUpgradePostUpgradeFailed",
+				Message:            fmt.Sprintf("Job %s failed, please check its pod logs", job.Name),
+			})
+			return false, ctrl.Result{}, nil
+
+		case job != nil && jobCompleted(job):
+			meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+				ObservedGeneration: upgrade.Generation,
+				Type:               task.condition,
+				Status:             metav1.ConditionTrue,
+				Reason:             task.done,
+				Message:            fmt.Sprintf("Job %s finished", job.Name),
+			})
+
+		default:
+			// The Job reports its progress through the Progressing condition.
+			// Changes to it trigger another reconcile.
+			progressing(task.reason, task.message, upgrade.Spec.PostgresClusterName)
+			return false, ctrl.Result{}, errors.WithStack(r.apply(ctx,
+				r.generatePostUpgradeJob(upgrade, cluster, task.role, task.command)))
+		}
+	}
+
+	return true, ctrl.Result{}, nil
+}
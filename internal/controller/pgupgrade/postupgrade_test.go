@@ -0,0 +1,327 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestPostUpgradeCommands(t *testing.T) {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Spec.ToPostgresVersion = 17
+
+	cluster := v1beta1.NewPostgresCluster()
+	require.UnmarshalInto(t, &cluster.Spec, `{
+		extensions: [{ name: pgaudit, version: "1.7" }],
+		databases: [{ name: app, extensions: [{ name: postgis }] }],
+	}`)
+
+	for _, tt := range []struct {
+		Name    string
+		Command []string
+		Args    []string
+	}{
+		{
+			Name:    "Analyze",
+			Command: analyzeCommand(upgrade),
+			Args:    []string{"analyze", "17"},
+		},
+		{
+			Name:    "UpdateExtensions",
+			Command: updateExtensionsCommand(upgrade, cluster),
+			Args: []string{"update-extensions", "17",
+				`{"cluster":["pgaudit"],"databases":{"app":["postgis"]}}`},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Assert(t, len(tt.Command) > 3)
+			assert.DeepEqual(t, []string{"bash", "-ceu", "--"}, tt.Command[:3])
+			assert.DeepEqual(t, tt.Args, tt.Command[4:])
+
+			script := tt.Command[3]
+
+			t.Run("PrettyYAML", func(t *testing.T) {
+				b, err := yaml.Marshal(script)
+				assert.NilError(t, err)
+				assert.Assert(t, strings.HasPrefix(string(b), `|`),
+					"expected literal block scalar, got:\n%s", b)
+			})
+
+			t.Run("ShellCheck", func(t *testing.T) {
+				shellcheck := require.ShellCheck(t)
+
+				// Write out that inline script.
+				dir := t.TempDir()
+				file := filepath.Join(dir, "script.bash")
+				assert.NilError(t, os.WriteFile(file, []byte(script), 0o600))
+
+				// Expect shellcheck to be happy.
+				cmd := exec.Command(shellcheck, "--enable=all", "--shell=bash", file)
+				output, err := cmd.CombinedOutput()
+				assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+			})
+		})
+	}
+
+	t.Run("NoExtensions", func(t *testing.T) {
+		command := updateExtensionsCommand(upgrade, v1beta1.NewPostgresCluster())
+		assert.Equal(t, command[len(command)-1], `{"cluster":[],"databases":{}}`)
+	})
+
+	t.Run("Script", func(t *testing.T) {
+		script := updateExtensionsCommand(upgrade, cluster)[3]
+
+		// The script generated by pg_upgrade runs first, when it exists.
+		assert.Assert(t, strings.Contains(script, `pg_read_file(:'file')`))
+		assert.Assert(t, strings.Contains(script, `/pgdata/update_extensions.sql`))
+		assert.Assert(t, strings.Index(script, `pg_read_file`) < strings.Index(script, `ALTER EXTENSION`))
+
+		// A row of pg_stat_file is never entirely non-null, so check one field.
+		// - https://www.postgresql.org/docs/current/functions-comparison.html
+		assert.Assert(t, strings.Contains(script,
+			`SELECT pg_catalog.pg_read_file(:'file') WHERE (pg_catalog.pg_stat_file(:'file', true)).size IS NOT NULL`))
+
+		// Extensions in the spec are skipped.
+		assert.Assert(t, strings.Contains(script, `NOT (:'declared'::jsonb -> 'cluster') ? installed.extname`))
+		assert.Assert(t, strings.Contains(script, `\gexec`))
+	})
+}
+
+func TestGeneratePostUpgradeJob(t *testing.T) {
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.UID = "uid3"
+	require.UnmarshalInto(t, &upgrade.Spec, `{
+		image: img4,
+		postgresClusterName: pg5,
+		fromPostgresVersion: 16,
+		toPostgresVersion: 17,
+		postUpgrade: { user: postgres },
+	}`)
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg5"
+	cluster.Spec.Port = initialize.Int32(5432)
+
+	job := reconciler.generatePostUpgradeJob(upgrade, cluster, pgUpgradeAnalyze, []string{"true"})
+	assert.Equal(t, job.Name, "pgu2-pgupgrade-analyze")
+	assert.Equal(t, job.Labels["postgres-operator.crunchydata.com/role"], "pgupgrade-analyze")
+	assert.Equal(t, job.Labels["postgres-operator.crunchydata.com/cluster"], "pg5")
+	assert.Assert(t, job.Spec.BackoffLimit == nil, "expected retries")
+	assert.Equal(t, len(job.OwnerReferences), 1)
+
+	assert.Equal(t, len(job.Spec.Template.Spec.Containers), 1)
+	assert.Assert(t, cmp.MarshalMatches(job.Spec.Template.Spec.Containers[0].Env, `
+- name: PGCONNECT_TIMEOUT
+  value: "10"
+- name: PGHOST
+  value: pg5-primary.ns1.svc
+- name: PGPASSWORD
+  valueFrom:
+    secretKeyRef:
+      key: password
+      name: pg5-pguser-postgres
+- name: PGPORT
+  value: "5432"
+- name: PGSSLMODE
+  value: require
+- name: PGUSER
+  value: postgres
+	`))
+	assert.Equal(t, job.Spec.Template.Spec.Containers[0].Image, "img4")
+}
+
+// applyClient records the objects sent to it by [PGUpgradeReconciler.apply]
+// and the objects it is asked to delete.
+type applyClient struct {
+	client.Client
+	applied []client.Object
+	deleted []client.Object
+}
+
+func (c *applyClient) Patch(
+	_ context.Context, object client.Object, _ client.Patch, _ ...client.PatchOption,
+) error {
+	c.applied = append(c.applied, object)
+	return nil
+}
+
+func (c *applyClient) Delete(
+	_ context.Context, object client.Object, _ ...client.DeleteOption,
+) error {
+	c.deleted = append(c.deleted, object)
+	return nil
+}
+
+func TestReconcilePostUpgrade(t *testing.T) {
+	ctx := context.Background()
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	require.UnmarshalInto(t, &upgrade.Spec, `{
+		postgresClusterName: pg5,
+		fromPostgresVersion: 16,
+		toPostgresVersion: 17,
+		postUpgrade: { analyze: true, updateExtensions: true, user: postgres },
+	}`)
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg5"
+	cluster.Spec.PostgresVersion = 17
+	cluster.Spec.Image = "image-17"
+	cluster.Spec.Port = initialize.Int32(5432)
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "pg5-one-0"
+	pod.Spec.Containers = []corev1.Container{{Name: ContainerDatabase, Image: "image-17"}}
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type: corev1.PodReady, Status: corev1.ConditionTrue,
+	}}
+
+	world := NewWorld()
+	world.Cluster = cluster
+	world.ClusterLeaderPod = pod
+
+	cc := &applyClient{}
+	reconciler := &PGUpgradeReconciler{Client: cc}
+
+	reason := func(upgrade *v1beta1.PGUpgrade) string {
+		return meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeProgressing).Reason
+	}
+	finish := func(world *World, name string, condition batchv1.JobConditionType) {
+		job := &batchv1.Job{}
+		job.Name = name
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type: condition, Status: corev1.ConditionTrue,
+		}}
+		world.Jobs[name] = job
+	}
+
+	t.Run("NotRunning", func(t *testing.T) {
+		upgrade := upgrade.DeepCopy()
+		world := *world
+		world.ClusterLeaderPod = pod.DeepCopy()
+		world.ClusterLeaderPod.Spec.Containers[0].Image = "image-16"
+
+		done, result, err := reconciler.reconcilePostUpgrade(ctx, upgrade, &world)
+		assert.NilError(t, err)
+		assert.Assert(t, !done)
+		assert.Assert(t, result.IsZero())
+		assert.Equal(t, meta.FindStatusCondition(upgrade.Status.Conditions,
+			ConditionPGUpgradeProgressing).Reason, "PGClusterNotRunning")
+		assert.Equal(t, len(cc.applied), 0)
+	})
+
+	t.Run("InstanceSetImage", func(t *testing.T) {
+		upgrade := upgrade.DeepCopy()
+		world := *world
+		world.Cluster = cluster.DeepCopy()
+		world.Cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "one", Image: "image-17-canary"},
+		}
+		world.ClusterLeaderPod = pod.DeepCopy()
+		world.ClusterLeaderPod.Labels = map[string]string{
+			"postgres-operator.crunchydata.com/instance-set": "one",
+		}
+
+		// The leader runs the image of the cluster, not its instance set.
+		_, _, err := reconciler.reconcilePostUpgrade(ctx, upgrade, &world)
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGClusterNotRunning")
+		assert.Equal(t, len(cc.applied), 0)
+
+		world.ClusterLeaderPod.Spec.Containers[0].Image = "image-17-canary"
+		_, _, err = reconciler.reconcilePostUpgrade(ctx, upgrade, &world)
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGUpgradeUpdatingExtensions")
+		assert.Equal(t, len(cc.applied), 1)
+		cc.applied = nil
+	})
+
+	// The extensions Job starts first and runs until it finishes.
+	done, result, err := reconciler.reconcilePostUpgrade(ctx, upgrade, world)
+	assert.NilError(t, err)
+	assert.Assert(t, !done && result.IsZero())
+	assert.Equal(t, reason(upgrade), "PGUpgradeUpdatingExtensions")
+	assert.Equal(t, len(cc.applied), 1)
+	assert.Equal(t, cc.applied[0].GetName(), "pgu2-pgupgrade-extensions")
+
+	done, _, err = reconciler.reconcilePostUpgrade(ctx, upgrade, world)
+	assert.NilError(t, err)
+	assert.Assert(t, !done)
+	assert.Equal(t, len(cc.applied), 2)
+	assert.Equal(t, cc.applied[1].GetName(), "pgu2-pgupgrade-extensions")
+
+	// The analyze Job starts after that.
+	finish(world, "pgu2-pgupgrade-extensions", batchv1.JobComplete)
+	done, _, err = reconciler.reconcilePostUpgrade(ctx, upgrade, world)
+	assert.NilError(t, err)
+	assert.Assert(t, !done)
+	assert.Assert(t, meta.IsStatusConditionTrue(upgrade.Status.Conditions, ConditionPGUpgradeExtensionsUpdated))
+	assert.Equal(t, reason(upgrade), "PGUpgradeAnalyzing")
+	assert.Equal(t, len(cc.applied), 3)
+	assert.Equal(t, cc.applied[2].GetName(), "pgu2-pgupgrade-analyze")
+
+	finish(world, "pgu2-pgupgrade-analyze", batchv1.JobComplete)
+	done, result, err = reconciler.reconcilePostUpgrade(ctx, upgrade, world)
+	assert.NilError(t, err)
+	assert.Assert(t, done && result.IsZero())
+	assert.Assert(t, meta.IsStatusConditionTrue(upgrade.Status.Conditions, ConditionPGUpgradeAnalyzed))
+
+	// Nothing runs again.
+	cc.applied = nil
+	done, _, err = reconciler.reconcilePostUpgrade(ctx, upgrade, world)
+	assert.NilError(t, err)
+	assert.Assert(t, done)
+	assert.Equal(t, len(cc.applied), 0)
+
+	t.Run("Failure", func(t *testing.T) {
+		upgrade := upgrade.DeepCopy()
+		upgrade.Status.Conditions = nil
+		upgrade.Spec.PostUpgrade.UpdateExtensions = initialize.Bool(false)
+
+		world := NewWorld()
+		world.Cluster = cluster
+		world.ClusterLeaderPod = pod
+		finish(world, "pgu2-pgupgrade-analyze", batchv1.JobFailed)
+
+		done, _, err := reconciler.reconcilePostUpgrade(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Assert(t, !done)
+		assert.Equal(t, len(cc.applied), 0)
+
+		analyzed := meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeAnalyzed)
+		assert.Assert(t, analyzed != nil)
+		assert.Equal(t, analyzed.Status, metav1.ConditionFalse)
+		assert.Equal(t, analyzed.Message,
+			"Job pgu2-pgupgrade-analyze failed, please check its pod logs")
+		assert.Assert(t, meta.FindStatusCondition(upgrade.Status.Conditions,
+			ConditionPGUpgradeExtensionsUpdated) == nil)
+	})
+}
@@ -0,0 +1,426 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/kubernetes"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// removeRetainedData removes the data directory of version, and the WAL
+// directory it links to, from the volumes of a running instance. It fails
+// when that directory is the one PostgreSQL is using.
+func removeRetainedData(ctx context.Context, exec postgres.Executor, version int32) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	err := exec(ctx, nil, &stdout, &stderr,
+		"bash", "-ceu", "--", strings.Join([]string{
+			`declare -r directory="/pgdata/pg$1"`,
+			`[[ -d "${directory}" ]] || exit 0`,
+			`if [[ "$(realpath "${PGDATA}")" == "$(realpath "${directory}")" ]] || [[ -f "${directory}/postmaster.pid" ]]`,
+			`then printf >&2 'Directory in use, cannot remove %s\n' "${directory}"; exit 1; fi`,
+
+			// Resolve the WAL directory before removing the link to it. This is
+			// the same as the remove data Job; see [removeDataCommand].
+			`rm -rf "${directory}" "$(realpath "${directory}/pg_wal")"`,
+		}, "\n"),
+		"-", fmt.Sprint(version))
+
+	return stdout.String(), stderr.String(), err
+}
+
+// generateRetainedSnapshot returns a VolumeSnapshot of source using the class
+// in the retention settings of upgrade.
+func (r *PGUpgradeReconciler) generateRetainedSnapshot(
+	upgrade *v1beta1.PGUpgrade, volume string, source string,
+) *volumesnapshotv1.VolumeSnapshot {
+	return r.generateVolumeSnapshot(upgrade, pgUpgradeRetainedVolume(upgrade, volume),
+		pgUpgradeRetained, upgrade.Spec.Retention.VolumeSnapshotClassName, source)
+}
+
+// snapshotReady returns true when snapshot can be used to populate a volume.
+func snapshotReady(snapshot *volumesnapshotv1.VolumeSnapshot) bool {
+	return snapshot != nil && snapshot.Status != nil &&
+		snapshot.Status.ReadyToUse != nil && *snapshot.Status.ReadyToUse
+}
+
+// upgradedInstance returns true when job mounts the volumes of instance.
+func upgradedInstance(job *batchv1.Job, instance *appsv1.StatefulSet) bool {
+	if job == nil || instance == nil {
+		return false
+	}
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		for _, source := range checkSourceVolumes(instance) {
+			if volume.PersistentVolumeClaim != nil &&
+				volume.PersistentVolumeClaim.ClaimName == source.PersistentVolumeClaim.ClaimName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//+kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources="volumesnapshots",verbs={create,patch}
+
+// reconcileRetainedSnapshots takes snapshots of the volumes of the primary in
+// world, one at a time, before it is upgraded. It returns true when they are
+// ready or when the retention settings of upgrade have no VolumeSnapshotClass.
+func (r *PGUpgradeReconciler) reconcileRetainedSnapshots(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, world *World,
+) (bool, ctrl.Result, error) {
+	class := upgrade.Spec.Retention.VolumeSnapshotClassName
+	if class == nil {
+		return true, ctrl.Result{}, nil
+	}
+
+	if !kubernetes.Has(ctx, volumesnapshotv1.SchemeGroupVersion.WithKind("VolumeSnapshot")) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradeInvalid",
+			Message:            "VolumeSnapshots are not installed/enabled in this Kubernetes cluster",
+		})
+		return false, ctrl.Result{}, nil
+	}
+
+	ready := true
+	var err error
+	for _, volume := range checkSourceVolumes(world.ClusterPrimary) {
+		if err == nil && ready {
+			snapshot := world.VolumeSnapshots[pgUpgradeRetainedVolume(upgrade, volume.Name).Name]
+			if snapshot == nil {
+				err = errors.WithStack(r.apply(ctx, r.generateRetainedSnapshot(
+					upgrade, volume.Name, volume.PersistentVolumeClaim.ClaimName)))
+			}
+			ready = snapshotReady(snapshot)
+		}
+	}
+
+	if err == nil && !ready {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionTrue,
+			Reason:             "PGUpgradeSnapshotting",
+			Message: fmt.Sprintf("Taking snapshots of instance %s before the upgrade",
+				world.ClusterPrimary.Name),
+		})
+		return false, runtime.RequeueWithBackoff(), nil
+	}
+
+	setStatusToProgressingIfReasonWas("PGUpgradeSnapshotting", upgrade)
+	return ready, ctrl.Result{}, err
+}
+
+//+kubebuilder:rbac:groups="",resources="pods/exec",verbs={create}
+//+kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources="volumesnapshots",verbs={delete}
+
+// reconcileRetention waits for the user to decide what happens to the data
+// from before the upgrade. When they confirm the upgrade, it removes that data
+// from every instance and deletes the snapshots. It returns true when that is
+// done.
+func (r *PGUpgradeReconciler) reconcileRetention(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, world *World,
+) (bool, ctrl.Result, error) {
+	cluster := world.Cluster
+
+	progressing := func(reason, message string, args ...any) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            fmt.Sprintf(message, args...),
+		})
+	}
+
+	if upgrade.Spec.Retention.Action != v1beta1.PGUpgradeRetentionConfirm {
+		progressing("PGUpgradeAwaitingConfirmation",
+			"Set retention.action to Confirm to remove the data of PostgresCluster %s from before the upgrade, or to Rollback to return to version %d",
+			upgrade.Spec.PostgresClusterName, upgrade.Spec.FromPostgresVersion)
+		return false, ctrl.Result{}, nil
+	}
+
+	// The data is removed through the database container of each instance,
+	// so wait for all of them to be running at the new version.
+	var pods []string
+	for _, pod := range world.InstancePods {
+		if postUpgradeReady(cluster, pod) {
+			pods = append(pods, pod.Name)
+		}
+	}
+	if cluster.Spec.PostgresVersion != int(upgrade.Spec.ToPostgresVersion) ||
+		initialize.FromPointer(cluster.Spec.Shutdown) || len(pods) != world.ReplicasExpected+1 {
+		progressing("PGClusterNotRunning",
+			"Start every instance of PostgresCluster %s at version %d to remove the data from before the upgrade",
+			upgrade.Spec.PostgresClusterName, upgrade.Spec.ToPostgresVersion)
+		return false, ctrl.Result{}, nil
+	}
+
+	// Write the condition then come back to remove the data.
+	current := meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeProgressing)
+	if current == nil || current.Reason != "PGUpgradeRemovingData" {
+		progressing("PGUpgradeRemovingData",
+			"Removing the data of PostgresCluster %s from before the upgrade",
+			upgrade.Spec.PostgresClusterName)
+		return false, runtime.RequeueWithoutBackoff(0), nil
+	}
+
+	for _, pod := range pods {
+		exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+			return r.PodExec(ctx, upgrade.Namespace, pod, ContainerDatabase, stdin, stdout, stderr, command...)
+		}
+
+		_, stderr, err := removeRetainedData(ctx, exec, upgrade.Spec.FromPostgresVersion)
+		if err != nil {
+			meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+				ObservedGeneration: upgrade.Generation,
+				Type:               ConditionPGUpgradeProgressing,
+				Status:             metav1.ConditionFalse,
+				Reason:             "PGUpgradeRemoveDataFailed",
+				Message:            strings.TrimSpace(fmt.Sprintf("%s: %v: %s", pod, err, stderr)),
+			})
+			return false, ctrl.Result{}, err
+		}
+	}
+
+	var err error
+	for _, object := range world.VolumeSnapshots {
+		if err == nil && metav1.IsControlledBy(object, upgrade) &&
+			object.Labels[LabelRole] == pgUpgradeRetained {
+			err = errors.WithStack(client.IgnoreNotFound(r.Client.Delete(ctx, object)))
+		}
+	}
+
+	return err == nil, ctrl.Result{}, err
+}
+
+//+kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={create,patch,delete}
+//+kubebuilder:rbac:groups="batch",resources="jobs",verbs={create,patch}
+
+// reconcileRestoredVolumes replaces the contents of the volumes of the primary
+// in world with those of its retained VolumeSnapshots. It returns true when
+// that is done.
+func (r *PGUpgradeReconciler) reconcileRestoredVolumes(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, world *World,
+) (bool, ctrl.Result, error) {
+	primary := world.ClusterPrimary
+
+	condition := func(status metav1.ConditionStatus, reason, message string, args ...any) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             status,
+			Reason:             reason,
+			Message:            fmt.Sprintf(message, args...),
+		})
+	}
+
+	var err error
+	if job := world.Jobs[pgUpgradeRestoreJob(upgrade).Name]; job != nil {
+		switch {
+		case jobFailed(job):
+			condition(metav1.ConditionFalse, "PGUpgradeRestoreFailed",
+				"Job %s failed to restore the volumes of instance %s; check its Pod logs",
+				job.Name, primary.Name)
+			return false, ctrl.Result{}, nil
+
+		case !jobCompleted(job):
+			condition(metav1.ConditionTrue, "PGUpgradeRestoring",
+				"Restoring the volumes of instance %s from VolumeSnapshots", primary.Name)
+			return false, ctrl.Result{}, nil
+		}
+
+		// The restored data is now on the volumes of the primary.
+		for _, object := range world.Volumes {
+			if err == nil && metav1.IsControlledBy(object, upgrade) &&
+				object.Labels[LabelRole] == pgUpgradeRestore {
+				err = errors.WithStack(client.IgnoreNotFound(r.Client.Delete(ctx, object)))
+			}
+		}
+		return err == nil, ctrl.Result{}, err
+	}
+
+	for _, volume := range checkSourceVolumes(primary) {
+		snapshot := world.VolumeSnapshots[pgUpgradeRetainedVolume(upgrade, volume.Name).Name]
+		source := world.Volumes[volume.PersistentVolumeClaim.ClaimName]
+
+		if !snapshotReady(snapshot) || source == nil {
+			condition(metav1.ConditionFalse, "PGUpgradeSnapshotNotFound",
+				"VolumeSnapshot %s is not ready to restore instance %s",
+				pgUpgradeRetainedVolume(upgrade, volume.Name).Name, primary.Name)
+			return false, ctrl.Result{}, nil
+		}
+		if err == nil {
+			err = errors.WithStack(r.apply(ctx, r.generateVolume(upgrade,
+				pgUpgradeRestoreVolume(upgrade, volume.Name), pgUpgradeRestore,
+				source, snapshotDataSource(snapshot.Name))))
+		}
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, r.generateRestoreJob(ctx, upgrade, primary)))
+	}
+	if err == nil {
+		condition(metav1.ConditionTrue, "PGUpgradeRestoring",
+			"Restoring the volumes of instance %s from VolumeSnapshots", primary.Name)
+	}
+	return false, ctrl.Result{}, err
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={patch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters/status",verbs={patch}
+//+kubebuilder:rbac:groups="",resources="endpoints",verbs={delete}
+
+// reconcileRollback returns a stopped cluster to the version and images it had
+// before the upgrade. It starts from the data directories that were retained
+// on the volumes of its instances, so everything written since the upgrade
+// is lost. After a Link upgrade, the volumes of the primary are first restored
+// from its retained VolumeSnapshots.
+func (r *PGUpgradeReconciler) reconcileRollback(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, world *World,
+) (ctrl.Result, error) {
+	cluster := world.Cluster
+
+	stopped := func(reason, message string, args ...any) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            fmt.Sprintf(message, args...),
+		})
+	}
+
+	// In link mode, pg_upgrade disables the old data directory and the new
+	// version writes to the files they share. Only snapshots taken before the
+	// upgrade have usable data.
+	// - https://www.postgresql.org/docs/current/pgupgrade.html#PGUPGRADE-STEP-REVERT
+	//
+	// NOTE: CRD validation also rejects a rollback without snapshots.
+	link := cmp.Or(upgrade.Spec.TransferMethod, "Link") == "Link"
+	if link && upgrade.Spec.Retention.VolumeSnapshotClassName == nil {
+		stopped("PGUpgradeRollbackUnavailable",
+			"Data from before a Link upgrade cannot be used after version %d starts",
+			upgrade.Spec.ToPostgresVersion)
+		return ctrl.Result{}, nil
+	}
+
+	// Replicas upgraded by rsync copied the disabled data directory of the
+	// primary, and there are no snapshots of their volumes.
+	for _, sts := range world.ClusterReplicas {
+		if link && world.Jobs[replicaUpgradeJob(upgrade, sts).Name] != nil {
+			stopped("PGUpgradeRollbackUnavailable",
+				"Replica %s was upgraded from the disabled data of the primary; remove it from PostgresCluster %s to roll back",
+				sts.Name, upgrade.Spec.PostgresClusterName)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	if upgrade.Status.PreviousImage == nil {
+		stopped("PGUpgradeRollbackUnavailable",
+			"The image of PostgresCluster %s before the upgrade is unknown",
+			upgrade.Spec.PostgresClusterName)
+		return ctrl.Result{}, nil
+	}
+
+	// See the same check in [PGUpgradeReconciler.Reconcile].
+	if cluster.GetAnnotations()[AnnotationAllowUpgrade] != upgrade.Name {
+		stopped("PGClusterMissingRequiredAnnotation",
+			"PostgresCluster %s lacks annotation for upgrade %s",
+			upgrade.Spec.PostgresClusterName, upgrade.GetName())
+		return ctrl.Result{}, nil
+	}
+
+	if !world.ClusterShutdown {
+		stopped("PGClusterNotShutdown", "PostgresCluster instances still running")
+		return ctrl.Result{}, nil
+	}
+
+	// Only the upgraded instance has a data directory that was shut down as
+	// a primary. Replicas must follow it.
+	if !upgradedInstance(world.Jobs[pgUpgradeJob(upgrade).Name], world.ClusterPrimary) {
+		stopped("PGClusterPrimaryChanged",
+			"PostgresCluster %s will not start from the upgraded instance; switch back to it before shutting down",
+			upgrade.Spec.PostgresClusterName)
+		return ctrl.Result{}, nil
+	}
+
+	if link {
+		if done, result, err := r.reconcileRestoredVolumes(ctx, upgrade, world); !done || err != nil {
+			return result, err
+		}
+	}
+
+	// The old data directory has the old system identifier. Clear the new one
+	// from Patroni by deleting its DCS Endpoints.
+	var err error
+	for _, object := range world.PatroniEndpoints {
+		uid := object.GetUID()
+		version := object.GetResourceVersion()
+		exactly := client.Preconditions{UID: &uid, ResourceVersion: &version}
+		if err == nil {
+			err = client.IgnoreNotFound(r.Client.Delete(ctx, object, exactly))
+		}
+	}
+
+	if err == nil {
+		patch := cluster.DeepCopy()
+		patch.Spec.PostgresVersion = int(upgrade.Spec.FromPostgresVersion)
+		patch.Spec.Image = *upgrade.Status.PreviousImage
+		for i := range patch.Spec.InstanceSets {
+			set := &patch.Spec.InstanceSets[i]
+			set.Image = upgrade.Status.PreviousInstanceImages[set.Name]
+		}
+
+		err = errors.WithStack(r.Client.Patch(ctx, patch, client.MergeFrom(cluster), r.Owner))
+	}
+	if err == nil {
+		err = r.deleteReplicaCreateJobs(ctx, world)
+	}
+	if err == nil {
+		patch := cluster.DeepCopy()
+		patch.Status.PostgresVersion = int(upgrade.Spec.FromPostgresVersion)
+		patch.Status.PGBackRest.Repos = []v1beta1.RepoStatus{}
+
+		err = errors.WithStack(r.Client.Status().Patch(ctx, patch, client.MergeFrom(cluster), r.Owner))
+	}
+
+	if err == nil {
+		stopped("PGUpgradeRolledBack",
+			"PostgresCluster %s is ready to start at version %d",
+			upgrade.Spec.PostgresClusterName, upgrade.Spec.FromPostgresVersion)
+
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeSucceeded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradeRolledBack",
+			Message: fmt.Sprintf(
+				"PostgresCluster %s was returned to version %d",
+				upgrade.Spec.PostgresClusterName, upgrade.Spec.FromPostgresVersion),
+		})
+	}
+
+	return ctrl.Result{}, err
+}
@@ -0,0 +1,480 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pgupgrade
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func retentionTestUpgrade(t testing.TB) *v1beta1.PGUpgrade {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.UID = "uid3"
+	require.UnmarshalInto(t, &upgrade.Spec, `{
+		postgresClusterName: pg5,
+		fromPostgresVersion: 16,
+		toPostgresVersion: 17,
+		transferMethod: Copy,
+		retention: { volumeSnapshotClassName: snaps },
+	}`)
+	return upgrade
+}
+
+func TestRemoveRetainedData(t *testing.T) {
+	var command []string
+	_, _, err := removeRetainedData(context.Background(), func(
+		_ context.Context, stdin io.Reader, _, _ io.Writer, args ...string,
+	) error {
+		assert.Assert(t, stdin == nil)
+		command = args
+		return nil
+	}, 16)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+	assert.DeepEqual(t, command[4:], []string{"-", "16"})
+	assert.Assert(t, cmp.Contains(command[3], `declare -r directory="/pgdata/pg$1"`))
+	assert.Assert(t, cmp.Contains(command[3], `"$(realpath "${PGDATA}")"`))
+	assert.Assert(t, cmp.Contains(command[3], `rm -rf "${directory}" "$(realpath "${directory}/pg_wal")"`))
+}
+
+func TestGenerateRetainedSnapshot(t *testing.T) {
+	reconciler := &PGUpgradeReconciler{}
+	upgrade := retentionTestUpgrade(t)
+
+	snapshot := reconciler.generateRetainedSnapshot(upgrade, "postgres-data", "pg5-one-abcd-pgdata")
+	assert.Assert(t, cmp.MarshalMatches(snapshot, `
+apiVersion: snapshot.storage.k8s.io/v1
+kind: VolumeSnapshot
+metadata:
+  creationTimestamp: null
+  labels:
+    postgres-operator.crunchydata.com/pgupgrade: pgu2
+    postgres-operator.crunchydata.com/role: pgupgrade-retained
+  name: pgu2-retained-postgres-data
+  namespace: ns1
+  ownerReferences:
+  - apiVersion: postgres-operator.crunchydata.com/v1beta1
+    blockOwnerDeletion: true
+    controller: true
+    kind: PGUpgrade
+    name: pgu2
+    uid: uid3
+spec:
+  source:
+    persistentVolumeClaimName: pg5-one-abcd-pgdata
+  volumeSnapshotClassName: snaps
+	`))
+}
+
+func TestUpgradedInstance(t *testing.T) {
+	instance := &appsv1.StatefulSet{}
+	instance.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "postgres-data", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pg5-one-abcd-pgdata"},
+		}},
+		{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}
+
+	job := &batchv1.Job{}
+	job.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "postgres-data", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pg5-two-wxyz-pgdata"},
+		}},
+		{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}
+
+	assert.Assert(t, !upgradedInstance(nil, instance))
+	assert.Assert(t, !upgradedInstance(job, nil))
+	assert.Assert(t, !upgradedInstance(job, instance))
+
+	job.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName = "pg5-one-abcd-pgdata"
+	assert.Assert(t, upgradedInstance(job, instance))
+}
+
+func TestReconcileRetention(t *testing.T) {
+	ctx := context.Background()
+	upgrade := retentionTestUpgrade(t)
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Spec.PostgresVersion = 17
+	cluster.Spec.Image = "image-17"
+
+	world := NewWorld()
+	world.Cluster = cluster
+	world.ReplicasExpected = 1
+	for _, name := range []string{"pg5-one-0", "pg5-two-0"} {
+		pod := &corev1.Pod{}
+		pod.Namespace, pod.Name = "ns1", name
+		pod.Spec.Containers = []corev1.Container{{Name: ContainerDatabase, Image: "image-17"}}
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.PodReady, Status: corev1.ConditionTrue,
+		}}
+		world.InstancePods = append(world.InstancePods, pod)
+	}
+
+	var pods []string
+	reconciler := &PGUpgradeReconciler{
+		PodExec: func(
+			_ context.Context, namespace, pod, container string,
+			_ io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			assert.Equal(t, namespace, "ns1")
+			assert.Equal(t, container, "database")
+			assert.Equal(t, command[len(command)-1], "16")
+			pods = append(pods, pod)
+			return nil
+		},
+	}
+
+	reason := func(upgrade *v1beta1.PGUpgrade) string {
+		return meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeProgressing).Reason
+	}
+
+	t.Run("Awaiting", func(t *testing.T) {
+		upgrade := upgrade.DeepCopy()
+
+		done, result, err := reconciler.reconcileRetention(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Assert(t, !done && result.IsZero())
+		assert.Equal(t, reason(upgrade), "PGUpgradeAwaitingConfirmation")
+		assert.Equal(t, len(pods), 0)
+	})
+
+	upgrade.Spec.Retention.Action = v1beta1.PGUpgradeRetentionConfirm
+
+	t.Run("NotRunning", func(t *testing.T) {
+		upgrade := upgrade.DeepCopy()
+		world := *world
+		world.InstancePods = world.InstancePods[:1]
+
+		done, result, err := reconciler.reconcileRetention(ctx, upgrade, &world)
+		assert.NilError(t, err)
+		assert.Assert(t, !done && result.IsZero())
+		assert.Equal(t, reason(upgrade), "PGClusterNotRunning")
+		assert.Equal(t, len(pods), 0)
+	})
+
+	// The first pass announces the removal; the next does it.
+	done, result, err := reconciler.reconcileRetention(ctx, upgrade, world)
+	assert.NilError(t, err)
+	assert.Assert(t, !done && !result.IsZero())
+	assert.Equal(t, reason(upgrade), "PGUpgradeRemovingData")
+	assert.Equal(t, len(pods), 0)
+
+	done, result, err = reconciler.reconcileRetention(ctx, upgrade, world)
+	assert.NilError(t, err)
+	assert.Assert(t, done && result.IsZero())
+	assert.DeepEqual(t, pods, []string{"pg5-one-0", "pg5-two-0"})
+
+	t.Run("Failure", func(t *testing.T) {
+		upgrade := upgrade.DeepCopy()
+		reconciler := &PGUpgradeReconciler{
+			PodExec: func(
+				_ context.Context, _, _, _ string,
+				_ io.Reader, _, stderr io.Writer, _ ...string,
+			) error {
+				_, _ = stderr.Write([]byte("Directory in use, cannot remove /pgdata/pg16\n"))
+				return errors.New("command terminated with exit code 1")
+			},
+		}
+
+		_, _, err := reconciler.reconcileRetention(ctx, upgrade, world)
+		assert.ErrorContains(t, err, "exit code 1")
+
+		progressing := meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeProgressing)
+		assert.Equal(t, progressing.Status, metav1.ConditionFalse)
+		assert.Equal(t, progressing.Reason, "PGUpgradeRemoveDataFailed")
+		assert.Assert(t, strings.HasPrefix(progressing.Message, "pg5-one-0: command terminated"))
+	})
+}
+
+func TestReconcileRollback(t *testing.T) {
+	ctx := context.Background()
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := retentionTestUpgrade(t)
+	upgrade.Spec.Retention.Action = v1beta1.PGUpgradeRetentionRollback
+	upgrade.Status.PreviousImage = initialize.String("image-16")
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Annotations = map[string]string{AnnotationAllowUpgrade: "pgu2"}
+
+	primary := &appsv1.StatefulSet{}
+	primary.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "postgres-data", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pg5-two-wxyz-pgdata"},
+		}},
+	}
+
+	job := &batchv1.Job{}
+	job.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "postgres-data", VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pg5-one-abcd-pgdata"},
+		}},
+	}
+
+	world := NewWorld()
+	world.Cluster = cluster
+	world.ClusterPrimary = primary
+	world.ClusterShutdown = true
+	world.Jobs["pgu2-pgdata"] = job
+
+	for _, tt := range []struct {
+		name    string
+		mutate  func(*v1beta1.PGUpgrade, *World)
+		reason  string
+		message string
+	}{
+		{
+			name: "LinkWithRsyncReplica",
+			mutate: func(upgrade *v1beta1.PGUpgrade, world *World) {
+				upgrade.Spec.TransferMethod = ""
+
+				replica := &appsv1.StatefulSet{}
+				replica.Name = "pg5-one-efgh"
+				world.ClusterReplicas = []*appsv1.StatefulSet{replica}
+				world.Jobs = map[string]*batchv1.Job{"pgu2-rsync-pg5-one-efgh": {}}
+			},
+			reason:  "PGUpgradeRollbackUnavailable",
+			message: "Replica pg5-one-efgh was upgraded from the disabled data",
+		},
+		{
+			name: "LinkWithoutSnapshots",
+			mutate: func(upgrade *v1beta1.PGUpgrade, _ *World) {
+				upgrade.Spec.TransferMethod = "Link"
+				upgrade.Spec.Retention.VolumeSnapshotClassName = nil
+			},
+			reason:  "PGUpgradeRollbackUnavailable",
+			message: "cannot be used after version 17 starts",
+		},
+		{
+			name: "UnknownImage",
+			mutate: func(upgrade *v1beta1.PGUpgrade, _ *World) {
+				upgrade.Status.PreviousImage = nil
+			},
+			reason:  "PGUpgradeRollbackUnavailable",
+			message: "image of PostgresCluster pg5 before the upgrade is unknown",
+		},
+		{
+			name: "NotAnnotated",
+			mutate: func(_ *v1beta1.PGUpgrade, world *World) {
+				world.Cluster = cluster.DeepCopy()
+				world.Cluster.Annotations = nil
+			},
+			reason: "PGClusterMissingRequiredAnnotation",
+		},
+		{
+			name: "NotShutdown",
+			mutate: func(_ *v1beta1.PGUpgrade, world *World) {
+				world.ClusterShutdown = false
+			},
+			reason: "PGClusterNotShutdown",
+		},
+		{
+			name:   "PrimaryChanged",
+			mutate: func(*v1beta1.PGUpgrade, *World) {},
+			reason: "PGClusterPrimaryChanged",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			upgrade := upgrade.DeepCopy()
+			world := *world
+			tt.mutate(upgrade, &world)
+
+			result, err := reconciler.reconcileRollback(ctx, upgrade, &world)
+			assert.NilError(t, err)
+			assert.Assert(t, result.IsZero())
+
+			progressing := meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeProgressing)
+			assert.Assert(t, progressing != nil)
+			assert.Equal(t, progressing.Status, metav1.ConditionFalse)
+			assert.Equal(t, progressing.Reason, tt.reason)
+			assert.Assert(t, cmp.Contains(progressing.Message, tt.message))
+			assert.Assert(t, meta.FindStatusCondition(upgrade.Status.Conditions,
+				ConditionPGUpgradeSucceeded) == nil)
+		})
+	}
+}
+
+func TestReconcileRollbackRestore(t *testing.T) {
+	ctx := context.Background()
+
+	upgrade := retentionTestUpgrade(t)
+	upgrade.Spec.TransferMethod = "Link"
+	upgrade.Spec.Retention.Action = v1beta1.PGUpgradeRetentionRollback
+	upgrade.Status.PreviousImage = initialize.String("image-16")
+	upgrade.Status.PreviousInstanceImages = map[string]string{"one": "image-16-one"}
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace, cluster.Name = "ns1", "pg5"
+	cluster.Annotations = map[string]string{AnnotationAllowUpgrade: "pgu2"}
+	cluster.Spec.PostgresVersion = 17
+	cluster.Spec.Image = "image-17"
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "one", Image: "image-17-one"},
+		{Name: "two", Image: "image-17-two"},
+	}
+	cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{}
+
+	primary := &appsv1.StatefulSet{}
+	primary.Namespace, primary.Name = "ns1", "pg5-one-abcd"
+	primary.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: ContainerDatabase,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "postgres-data", MountPath: "/pgdata"},
+			{Name: "postgres-wal", MountPath: "/pgwal"},
+		},
+	}}
+
+	newWorld := func() *World {
+		world := NewWorld()
+		world.Cluster = cluster.DeepCopy()
+		world.ClusterPrimary = primary.DeepCopy()
+		world.ClusterShutdown = true
+
+		for _, volume := range []string{"postgres-data", "postgres-wal"} {
+			pvc := &corev1.PersistentVolumeClaim{}
+			pvc.Namespace, pvc.Name = "ns1", "pg5-one-abcd-"+volume
+			world.Volumes[pvc.Name] = pvc
+
+			world.ClusterPrimary.Spec.Template.Spec.Volumes = append(
+				world.ClusterPrimary.Spec.Template.Spec.Volumes, corev1.Volume{
+					Name: volume,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvc.Name,
+						},
+					},
+				})
+		}
+
+		job := &batchv1.Job{}
+		job.Spec.Template.Spec.Volumes = world.ClusterPrimary.Spec.Template.Spec.Volumes
+		world.Jobs["pgu2-pgdata"] = job
+		return world
+	}
+
+	newReconciler := func() (*PGUpgradeReconciler, *applyClient) {
+		cc := &applyClient{Client: fake.NewClientBuilder().WithScheme(runtime.Scheme).
+			WithObjects(cluster.DeepCopy()).WithStatusSubresource(cluster).Build()}
+		return &PGUpgradeReconciler{Client: cc}, cc
+	}
+
+	reason := func(upgrade *v1beta1.PGUpgrade) string {
+		return meta.FindStatusCondition(upgrade.Status.Conditions, ConditionPGUpgradeProgressing).Reason
+	}
+
+	t.Run("SnapshotsNotReady", func(t *testing.T) {
+		reconciler, cc := newReconciler()
+		upgrade := upgrade.DeepCopy()
+
+		_, err := reconciler.reconcileRollback(ctx, upgrade, newWorld())
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGUpgradeSnapshotNotFound")
+		assert.Equal(t, len(cc.applied), 0)
+	})
+
+	t.Run("Restoring", func(t *testing.T) {
+		reconciler, cc := newReconciler()
+		upgrade := upgrade.DeepCopy()
+
+		world := newWorld()
+		for _, volume := range []string{"postgres-data", "postgres-wal"} {
+			snapshot := &volumesnapshotv1.VolumeSnapshot{}
+			snapshot.Name = "pgu2-retained-" + volume
+			snapshot.Status = &volumesnapshotv1.VolumeSnapshotStatus{ReadyToUse: initialize.Bool(true)}
+			world.VolumeSnapshots[snapshot.Name] = snapshot
+		}
+
+		_, err := reconciler.reconcileRollback(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGUpgradeRestoring")
+		assert.Equal(t, len(cc.applied), 3, "expected two volumes and a Job")
+
+		pvc, ok := cc.applied[0].(*corev1.PersistentVolumeClaim)
+		assert.Assert(t, ok)
+		assert.Equal(t, pvc.Name, "pgu2-restore-postgres-data")
+		assert.Equal(t, pvc.Spec.DataSource.Name, "pgu2-retained-postgres-data")
+
+		job, ok := cc.applied[2].(*batchv1.Job)
+		assert.Assert(t, ok)
+		assert.Equal(t, job.Name, "pgu2-restore")
+		assert.DeepEqual(t, job.Spec.Template.Spec.Containers[0].Command[5:],
+			[]string{"16", "/pgdata", "/pgwal"})
+		assert.Assert(t, cmp.MarshalContains(job.Spec.Template.Spec.Containers[0].VolumeMounts, `
+- mountPath: /restore/pgdata
+  name: restore-postgres-data
+- mountPath: /restore/pgwal
+  name: restore-postgres-wal`))
+
+		// The cluster does not change until the Job completes.
+		assert.Equal(t, meta.FindStatusCondition(upgrade.Status.Conditions,
+			ConditionPGUpgradeSucceeded) == nil, true)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		reconciler, cc := newReconciler()
+		upgrade := upgrade.DeepCopy()
+
+		world := newWorld()
+		world.Jobs["pgu2-restore"] = &batchv1.Job{Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}},
+		}}
+
+		_, err := reconciler.reconcileRollback(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGUpgradeRestoreFailed")
+		assert.Equal(t, len(cc.applied), 0)
+	})
+
+	t.Run("Restored", func(t *testing.T) {
+		reconciler, cc := newReconciler()
+		upgrade := upgrade.DeepCopy()
+
+		world := newWorld()
+		world.Jobs["pgu2-restore"] = &batchv1.Job{Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+		}}
+		for _, volume := range []string{"postgres-data", "postgres-wal"} {
+			pvc := reconciler.generateVolume(upgrade, pgUpgradeRestoreVolume(upgrade, volume),
+				pgUpgradeRestore, world.Volumes["pg5-one-abcd-"+volume], nil)
+			world.Volumes[pvc.Name] = pvc
+		}
+
+		_, err := reconciler.reconcileRollback(ctx, upgrade, world)
+		assert.NilError(t, err)
+		assert.Equal(t, reason(upgrade), "PGUpgradeRolledBack")
+		assert.Equal(t, len(cc.deleted), 2, "expected the restore volumes")
+
+		assert.Equal(t, len(cc.applied), 1)
+		patch, ok := cc.applied[0].(*v1beta1.PostgresCluster)
+		assert.Assert(t, ok)
+		assert.Equal(t, patch.Spec.PostgresVersion, 16)
+		assert.Equal(t, patch.Spec.Image, "image-16")
+		assert.Equal(t, patch.Spec.InstanceSets[0].Image, "image-16-one")
+		assert.Equal(t, patch.Spec.InstanceSets[1].Image, "")
+	})
+}
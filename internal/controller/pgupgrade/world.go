@@ -7,6 +7,7 @@ package pgupgrade
 import (
 	"context"
 
+	volumesnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -15,6 +16,8 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/crunchydata/postgres-operator/internal/kubernetes"
+	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -26,6 +29,9 @@ import (
 //+kubebuilder:rbac:groups="",resources="endpoints",verbs={list,watch}
 //+kubebuilder:rbac:groups="batch",resources="jobs",verbs={list,watch}
 //+kubebuilder:rbac:groups="apps",resources="statefulsets",verbs={list,watch}
+//+kubebuilder:rbac:groups="",resources="pods",verbs={list,watch}
+//+kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={list,watch}
+//+kubebuilder:rbac:groups="snapshot.storage.k8s.io",resources="volumesnapshots",verbs={list,watch}
 
 func (r *PGUpgradeReconciler) observeWorld(
 	ctx context.Context, upgrade *v1beta1.PGUpgrade,
@@ -45,6 +51,17 @@ func (r *PGUpgradeReconciler) observeWorld(
 		}, cluster))
 	err = world.populateCluster(cluster, err)
 
+	if err == nil && upgrade.Spec.LogicalReplication != nil &&
+		upgrade.Spec.Method == v1beta1.PGUpgradeMethodLogicalReplication {
+		target := v1beta1.NewPostgresCluster()
+		err = errors.WithStack(
+			r.Client.Get(ctx, client.ObjectKey{
+				Namespace: upgrade.Namespace,
+				Name:      upgrade.Spec.LogicalReplication.ClusterName,
+			}, target))
+		err = world.populateTargetCluster(target, err)
+	}
+
 	if err == nil {
 		var endpoints corev1.EndpointsList
 		err = errors.WithStack(
@@ -67,8 +84,8 @@ func (r *PGUpgradeReconciler) observeWorld(
 		}
 	}
 
+	var statefulsets appsv1.StatefulSetList
 	if err == nil {
-		var statefulsets appsv1.StatefulSetList
 		err = errors.WithStack(
 			r.Client.List(ctx, &statefulsets,
 				client.InNamespace(upgrade.Namespace),
@@ -77,6 +94,57 @@ func (r *PGUpgradeReconciler) observeWorld(
 		world.populateStatefulSets(statefulsets.Items)
 	}
 
+	// Only a check, the tasks after an upgrade, and retention need running
+	// instances. Only a check and rsync need the Pods of their Jobs.
+	if err == nil && (upgrade.Spec.Check != nil ||
+		upgrade.Spec.PostUpgrade != nil || upgrade.Spec.Retention != nil ||
+		upgrade.Spec.ReplicaMethod == v1beta1.PGUpgradeReplicaMethodRsync) {
+		var pods corev1.PodList
+		err = errors.WithStack(
+			r.Client.List(ctx, &pods,
+				client.InNamespace(upgrade.Namespace),
+				client.MatchingLabelsSelector{Selector: selectCluster},
+			))
+		world.populatePods(pods.Items, statefulsets.Items)
+	}
+
+	selectUpgrade := labels.SelectorFromSet(labels.Set{
+		LabelPGUpgrade: upgrade.Name,
+	})
+
+	// Only a check copies volumes.
+	if err == nil && upgrade.Spec.Check != nil {
+		// The volumes of instances have the cluster label while their copies
+		// have only the upgrade label.
+		for _, selector := range []labels.Selector{selectCluster, selectUpgrade} {
+			var volumes corev1.PersistentVolumeClaimList
+			if err == nil {
+				err = errors.WithStack(
+					r.Client.List(ctx, &volumes,
+						client.InNamespace(upgrade.Namespace),
+						client.MatchingLabelsSelector{Selector: selector},
+					))
+			}
+			for i := range volumes.Items {
+				world.Volumes[volumes.Items[i].Name] = &volumes.Items[i]
+			}
+		}
+	}
+
+	// Only a check and retention take snapshots.
+	if err == nil && (upgrade.Spec.Check != nil || upgrade.Spec.Retention != nil) &&
+		kubernetes.Has(ctx, volumesnapshotv1.SchemeGroupVersion.WithKind("VolumeSnapshot")) {
+		var snapshots volumesnapshotv1.VolumeSnapshotList
+		err = errors.WithStack(
+			r.Client.List(ctx, &snapshots,
+				client.InNamespace(upgrade.Namespace),
+				client.MatchingLabelsSelector{Selector: selectUpgrade},
+			))
+		for i := range snapshots.Items {
+			world.VolumeSnapshots[snapshots.Items[i].Name] = &snapshots.Items[i]
+		}
+	}
+
 	if err == nil {
 		world.populateShutdown()
 	}
@@ -97,6 +165,18 @@ func (w *World) populateCluster(cluster *v1beta1.PostgresCluster, err error) err
 	return err
 }
 
+// populateTargetCluster assigns the cluster that replaces Cluster during a
+// logical replication upgrade. NotFound is not an error.
+func (w *World) populateTargetCluster(cluster *v1beta1.PostgresCluster, err error) error {
+	if err == nil {
+		w.TargetCluster = cluster
+	} else if apierrors.IsNotFound(err) {
+		w.TargetCluster = nil
+		err = nil
+	}
+	return err
+}
+
 func (w *World) populatePatroniEndpoints(endpoints []corev1.Endpoints) {
 	for index, endpoint := range endpoints {
 		if endpoint.Labels[LabelPatroni] != "" {
@@ -130,6 +210,27 @@ func (w *World) populateStatefulSets(statefulSets []appsv1.StatefulSet) {
 	}
 }
 
+// populatePods assigns the Pod that Patroni labeled as the leader, its
+// StatefulSet, the Pods of each instance, and the Pods of each Job.
+func (w *World) populatePods(pods []corev1.Pod, statefulSets []appsv1.StatefulSet) {
+	for i := range pods {
+		if job := pods[i].Labels[batchv1.JobNameLabel]; job != "" {
+			w.JobPods[job] = append(w.JobPods[job], &pods[i])
+		} else if pods[i].Labels[LabelInstance] != "" {
+			w.InstancePods = append(w.InstancePods, &pods[i])
+		}
+		if pods[i].Labels[LabelRole] != naming.RolePatroniLeader {
+			continue
+		}
+		w.ClusterLeaderPod = &pods[i]
+		for j := range statefulSets {
+			if name := pods[i].Labels[LabelInstance]; name != "" && name == statefulSets[j].Name {
+				w.ClusterLeader = &statefulSets[j]
+			}
+		}
+	}
+}
+
 func (w *World) populateShutdown() {
 	if w.Cluster != nil {
 		status := w.Cluster.Status
@@ -158,18 +259,30 @@ type World struct {
 	Cluster *v1beta1.PostgresCluster
 	Upgrade *v1beta1.PGUpgrade
 
+	ClusterLeader    *appsv1.StatefulSet
+	ClusterLeaderPod *corev1.Pod
 	ClusterNotFound  error
 	ClusterPrimary   *appsv1.StatefulSet
 	ClusterReplicas  []*appsv1.StatefulSet
 	ClusterShutdown  bool
+	InstancePods     []*corev1.Pod
 	ReplicasExpected int
 
+	TargetCluster *v1beta1.PostgresCluster
+
 	PatroniEndpoints []*corev1.Endpoints
 	Jobs             map[string]*batchv1.Job
+	JobPods          map[string][]*corev1.Pod
+
+	Volumes         map[string]*corev1.PersistentVolumeClaim
+	VolumeSnapshots map[string]*volumesnapshotv1.VolumeSnapshot
 }
 
 func NewWorld() *World {
 	return &World{
-		Jobs: make(map[string]*batchv1.Job),
+		Jobs:            make(map[string]*batchv1.Job),
+		JobPods:         make(map[string][]*corev1.Pod),
+		Volumes:         make(map[string]*corev1.PersistentVolumeClaim),
+		VolumeSnapshots: make(map[string]*volumesnapshotv1.VolumeSnapshot),
 	}
 }
@@ -57,6 +57,36 @@ func TestPopulateCluster(t *testing.T) {
 	})
 }
 
+func TestPopulateTargetCluster(t *testing.T) {
+	t.Run("Found", func(t *testing.T) {
+		cluster := v1beta1.NewPostgresCluster()
+		cluster.SetName("target")
+
+		world := NewWorld()
+		assert.NilError(t, world.populateTargetCluster(cluster, nil))
+		assert.Equal(t, world.TargetCluster, cluster)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		cluster := v1beta1.NewPostgresCluster()
+		expected := apierrors.NewNotFound(runtime.GR{}, "name")
+
+		world := NewWorld()
+		assert.NilError(t, world.populateTargetCluster(cluster, expected),
+			"NotFound is handled")
+		assert.Assert(t, world.TargetCluster == nil)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		cluster := v1beta1.NewPostgresCluster()
+		expected := fmt.Errorf("danger")
+
+		world := NewWorld()
+		assert.Equal(t, world.populateTargetCluster(cluster, expected), expected)
+		assert.Assert(t, world.TargetCluster == nil)
+	})
+}
+
 func TestPopulatePatroniEndpoint(t *testing.T) {
 	endpoints := []corev1.Endpoints{
 		{
@@ -228,3 +258,47 @@ func TestPopulateStatefulSets(t *testing.T) {
 		assert.Assert(t, world.ReplicasExpected == 1)
 	})
 }
+
+func TestPopulatePods(t *testing.T) {
+	one := appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "pg5-one"}}
+	two := appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "pg5-two"}}
+
+	replica := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "pg5-one-0",
+		Labels: map[string]string{LabelInstance: "pg5-one", LabelRole: "replica"},
+	}}
+	leader := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "pg5-two-0",
+		Labels: map[string]string{LabelInstance: "pg5-two", LabelRole: "master"},
+	}}
+	job := corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "pgu2-check-xyz",
+		Labels: map[string]string{"batch.kubernetes.io/job-name": "pgu2-check"},
+	}}
+
+	t.Run("NoLeader", func(t *testing.T) {
+		world := NewWorld()
+		world.populatePods([]corev1.Pod{replica}, []appsv1.StatefulSet{one, two})
+
+		assert.Assert(t, world.ClusterLeader == nil)
+		assert.Assert(t, world.ClusterLeaderPod == nil)
+		assert.Equal(t, len(world.InstancePods), 1)
+		assert.Equal(t, len(world.JobPods), 0)
+	})
+
+	t.Run("Leader", func(t *testing.T) {
+		world := NewWorld()
+		world.populatePods([]corev1.Pod{replica, leader, job}, []appsv1.StatefulSet{one, two})
+
+		assert.Assert(t, world.ClusterLeader != nil)
+		assert.Equal(t, world.ClusterLeader.Name, "pg5-two")
+		assert.Equal(t, world.ClusterLeaderPod.Name, "pg5-two-0")
+		assert.Equal(t, len(world.JobPods["pgu2-check"]), 1)
+		assert.Equal(t, world.JobPods["pgu2-check"][0].Name, "pgu2-check-xyz")
+
+		// Job Pods are not instances.
+		assert.Equal(t, len(world.InstancePods), 2)
+		assert.Equal(t, world.InstancePods[0].Name, "pg5-one-0")
+		assert.Equal(t, world.InstancePods[1].Name, "pg5-two-0")
+	})
+}
@@ -11,9 +11,11 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crunchydata/postgres-operator/internal/collector"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
@@ -194,11 +196,34 @@ func (r *Reconciler) generateClusterPrimaryService(
 // - https://github.com/openshift/origin/pull/9383
 // +kubebuilder:rbac:groups="",resources="endpoints/restricted",verbs={create}
 
+// +kubebuilder:rbac:groups="",resources="services",verbs={get}
+
 // reconcileClusterPrimaryService writes the Service and Endpoints that resolve
-// to the PostgreSQL primary instance.
+// to the PostgreSQL primary instance. When cluster is redirected to another
+// cluster, they resolve to the primary instance of that cluster instead.
 func (r *Reconciler) reconcileClusterPrimaryService(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, leader *corev1.Service,
 ) (*corev1.Service, error) {
+	if other := cluster.GetAnnotations()[naming.PostgresPrimaryRedirect]; other != "" {
+		redirect := &corev1.Service{ObjectMeta: naming.PatroniLeaderEndpoints(
+			&v1beta1.PostgresCluster{ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace, Name: other,
+			}})}
+
+		// Keep resolving to this cluster when the other one is missing.
+		err := errors.WithStack(
+			r.Client.Get(ctx, client.ObjectKeyFromObject(redirect), redirect))
+
+		if err == nil {
+			leader = redirect
+		} else if apierrors.IsNotFound(err) {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "PrimaryRedirectNotFound",
+				"Unable to redirect the primary Service to PostgresCluster %q: %v", other, err)
+		} else {
+			return nil, err
+		}
+	}
+
 	service, endpoints, err := r.generateClusterPrimaryService(cluster, leader)
 
 	if err == nil {
@@ -213,7 +238,7 @@ func (r *Reconciler) reconcileClusterPrimaryService(
 // generateClusterReplicaService returns a v1.Service that exposes PostgreSQL
 // replica instances.
 func (r *Reconciler) generateClusterReplicaService(
-	cluster *v1beta1.PostgresCluster) (*corev1.Service, error,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances) (*corev1.Service, error,
 ) {
 	service := &corev1.Service{ObjectMeta: naming.ClusterReplicaService(cluster)}
 	service.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Service"))
@@ -288,20 +313,93 @@ func (r *Reconciler) generateClusterReplicaService(
 		naming.LabelRole:    naming.RolePatroniReplica,
 	}
 
+	// When some instance sets are excluded, select only Pods of the others.
+	// Wait until every one of those Pods is labeled so that replicas remain
+	// reachable while the label is added. See [Reconciler.reconcileLoadBalanceLabels].
+	if replicaServiceExcludesInstances(cluster) && loadBalanceLabelsComplete(instances) {
+		service.Spec.Selector[naming.LabelLoadBalance] = "true"
+	}
+
 	err := errors.WithStack(r.setControllerReference(cluster, service))
 
 	return service, err
 }
 
+// loadBalanceLabelsComplete returns true when every Pod of instances that
+// belongs in the replica Service has the load-balance label.
+func loadBalanceLabelsComplete(instances *observedInstances) bool {
+	if instances == nil {
+		return true
+	}
+	for _, instance := range instances.forCluster {
+		for _, pod := range instance.Pods {
+			if instance.Spec != nil && !instance.Spec.Tags.NoLoadBalanceOrDelay() &&
+				pod.Labels[naming.LabelLoadBalance] != "true" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// +kubebuilder:rbac:groups="",resources="pods",verbs={patch}
+
+// reconcileLoadBalanceLabels adds the load-balance label to instance Pods that
+// belong in the replica Service and removes it from the others. Pods are
+// labeled directly rather than through their StatefulSet so that they do not
+// restart when instance sets are excluded.
+func (r *Reconciler) reconcileLoadBalanceLabels(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	excludes := replicaServiceExcludesInstances(cluster)
+
+	for _, instance := range instances.forCluster {
+		want := excludes && instance.Spec != nil && !instance.Spec.Tags.NoLoadBalanceOrDelay()
+
+		for _, pod := range instance.Pods {
+			if (pod.Labels[naming.LabelLoadBalance] == "true") == want {
+				continue
+			}
+
+			value := "null"
+			if want {
+				value = `"true"`
+			}
+			patch := client.RawPatch(client.Merge.Type(), []byte(fmt.Sprintf(
+				`{"metadata":{"labels":{%q:%s}}}`, naming.LabelLoadBalance, value)))
+
+			if err := errors.WithStack(r.patch(ctx, pod, patch)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replicaServiceExcludesInstances returns true when any instance set of
+// cluster is excluded from the replica Service.
+func replicaServiceExcludesInstances(cluster *v1beta1.PostgresCluster) bool {
+	for i := range cluster.Spec.InstanceSets {
+		if cluster.Spec.InstanceSets[i].Tags.NoLoadBalanceOrDelay() {
+			return true
+		}
+	}
+	return false
+}
+
 // +kubebuilder:rbac:groups="",resources="services",verbs={create,patch}
 
 // reconcileClusterReplicaService writes the Service that exposes PostgreSQL
 // replica instances.
 func (r *Reconciler) reconcileClusterReplicaService(
-	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
 ) (*corev1.Service, error) {
-	service, err := r.generateClusterReplicaService(cluster)
+	err := r.reconcileLoadBalanceLabels(ctx, cluster, instances)
 
+	var service *corev1.Service
+	if err == nil {
+		service, err = r.generateClusterReplicaService(cluster, instances)
+	}
 	if err == nil {
 		err = errors.WithStack(r.apply(ctx, service))
 	}
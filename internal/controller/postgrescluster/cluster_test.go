@@ -18,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
@@ -673,6 +674,27 @@ func TestReconcileClusterPrimaryService(t *testing.T) {
 	service, err := reconciler.reconcileClusterPrimaryService(ctx, cluster, leader)
 	assert.NilError(t, err)
 	assert.Assert(t, service != nil && service.UID != "", "expected created service")
+
+	t.Run("Redirect", func(t *testing.T) {
+		other := &corev1.Service{}
+		other.Namespace = cluster.Namespace
+		other.Name = "other-ha"
+		other.Spec.Ports = []corev1.ServicePort{{Port: 5432}}
+		assert.NilError(t, cc.Create(ctx, other))
+
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{
+			naming.PostgresPrimaryRedirect: "other",
+		}
+
+		_, err := reconciler.reconcileClusterPrimaryService(ctx, cluster, leader)
+		assert.NilError(t, err)
+
+		endpoints := &corev1.Endpoints{ObjectMeta: naming.ClusterPrimaryService(cluster)}
+		assert.NilError(t, cc.Get(ctx, client.ObjectKeyFromObject(endpoints), endpoints))
+		assert.Assert(t, other.Spec.ClusterIP != "")
+		assert.Equal(t, endpoints.Subsets[0].Addresses[0].IP, other.Spec.ClusterIP)
+	})
 }
 
 func TestGenerateClusterReplicaServiceIntent(t *testing.T) {
@@ -686,7 +708,7 @@ func TestGenerateClusterReplicaServiceIntent(t *testing.T) {
 	cluster.Name = "pg2"
 	cluster.Spec.Port = initialize.Int32(9876)
 
-	service, err := reconciler.generateClusterReplicaService(cluster)
+	service, err := reconciler.generateClusterReplicaService(cluster, nil)
 	assert.NilError(t, err)
 
 	alwaysExpect := func(t testing.TB, service *corev1.Service) {
@@ -744,7 +766,7 @@ type: ClusterIP
 			cluster := cluster.DeepCopy()
 			cluster.Spec.ReplicaService = &v1beta1.ServiceSpec{Type: test.Type}
 
-			service, err := reconciler.generateClusterReplicaService(cluster)
+			service, err := reconciler.generateClusterReplicaService(cluster, nil)
 			assert.NilError(t, err)
 			alwaysExpect(t, service)
 			test.Expect(t, service)
@@ -764,7 +786,7 @@ type: ClusterIP
 			Labels:      map[string]string{"happy": "label"},
 		}
 
-		service, err := reconciler.generateClusterReplicaService(cluster)
+		service, err := reconciler.generateClusterReplicaService(cluster, nil)
 		assert.NilError(t, err)
 
 		// Annotations present in the metadata.
@@ -785,6 +807,93 @@ postgres-operator.crunchydata.com/cluster: pg2
 postgres-operator.crunchydata.com/role: replica
 		`))
 	})
+
+	t.Run("NoLoadBalance", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "00"},
+			{Name: "reporting", Tags: &v1beta1.PatroniTagsSpec{
+				NoLoadBalance: initialize.Bool(true),
+			}},
+		}
+
+		service, err := reconciler.generateClusterReplicaService(cluster, nil)
+		assert.NilError(t, err)
+
+		assert.Assert(t, cmp.MarshalMatches(service.Spec.Selector, `
+postgres-operator.crunchydata.com/cluster: pg2
+postgres-operator.crunchydata.com/load-balance: "true"
+postgres-operator.crunchydata.com/role: replica
+		`))
+	})
+}
+
+func TestReconcileLoadBalanceLabels(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Name = "hippo"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "00"},
+		{Name: "reporting", Tags: &v1beta1.PatroniTagsSpec{
+			NoLoadBalance: initialize.Bool(true),
+		}},
+	}
+
+	// These Pods were created before the "reporting" set was tagged; one of
+	// them has a stale label.
+	pod := func(name string, labels map[string]string) *corev1.Pod {
+		pod := new(corev1.Pod)
+		pod.Namespace, pod.Name, pod.Labels = "ns", name, labels
+		return pod
+	}
+	pods := []*corev1.Pod{
+		pod("hippo-00-abcd-0", nil),
+		pod("hippo-reporting-efgh-0", map[string]string{naming.LabelLoadBalance: "true"}),
+	}
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: pods[:1], Spec: &cluster.Spec.InstanceSets[0]},
+		{Name: "hippo-reporting-efgh", Pods: pods[1:], Spec: &cluster.Spec.InstanceSets[1]},
+	}}
+
+	reconciler := &Reconciler{Client: fake.NewClientBuilder().
+		WithScheme(runtime.Scheme).WithObjects(pods[0], pods[1]).Build()}
+
+	// The replica Service does not require the label until Pods have it.
+	service, err := reconciler.generateClusterReplicaService(cluster, instances)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.MarshalMatches(service.Spec.Selector, `
+postgres-operator.crunchydata.com/cluster: hippo
+postgres-operator.crunchydata.com/role: replica
+	`))
+
+	assert.NilError(t, reconciler.reconcileLoadBalanceLabels(ctx, cluster, instances))
+
+	stored := new(corev1.Pod)
+	assert.NilError(t, reconciler.Client.Get(ctx, client.ObjectKeyFromObject(pods[0]), stored))
+	assert.Equal(t, stored.Labels[naming.LabelLoadBalance], "true")
+	assert.NilError(t, reconciler.Client.Get(ctx, client.ObjectKeyFromObject(pods[1]), stored))
+	assert.Assert(t, stored.Labels[naming.LabelLoadBalance] == "")
+
+	// Once labeled, the replica Service selects only those Pods.
+	service, err = reconciler.generateClusterReplicaService(cluster, instances)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.MarshalMatches(service.Spec.Selector, `
+postgres-operator.crunchydata.com/cluster: hippo
+postgres-operator.crunchydata.com/load-balance: "true"
+postgres-operator.crunchydata.com/role: replica
+	`))
+
+	t.Run("NoneExcluded", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.InstanceSets[1].Tags = nil
+		instances.forCluster[1].Spec = &cluster.Spec.InstanceSets[1]
+
+		assert.NilError(t, reconciler.reconcileLoadBalanceLabels(ctx, cluster, instances))
+		assert.NilError(t, reconciler.Client.Get(ctx, client.ObjectKeyFromObject(pods[0]), stored))
+		assert.Assert(t, stored.Labels[naming.LabelLoadBalance] == "")
+	})
 }
 
 func TestPatroniLogSize(t *testing.T) {
@@ -30,6 +30,7 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/collector"
 	"github.com/crunchydata/postgres-operator/internal/config"
 	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/feature"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/kubernetes"
 	"github.com/crunchydata/postgres-operator/internal/logging"
@@ -47,8 +48,14 @@ const (
 
 // Reconciler holds resources for the PostgresCluster reconciler
 type Reconciler struct {
-	Client  client.Client
-	Owner   client.FieldOwner
+	Client client.Client
+	Owner  client.FieldOwner
+
+	// Reader reads objects directly from the Kubernetes API, bypassing the
+	// cache of Client. It is used for cluster-scoped objects that are not
+	// otherwise watched, such as Nodes.
+	Reader client.Reader
+
 	PodExec func(
 		ctx context.Context, namespace, pod, container string,
 		stdin io.Reader, stdout, stderr io.Writer, command ...string,
@@ -128,6 +135,13 @@ func (r *Reconciler) Reconcile(
 			return runtime.ErrorWithBackoff(tracing.Escape(span, err))
 		}
 	}
+	// warn when the image of an instance set has another major version;
+	// those instances would halt at startup. Warn once per change to the spec.
+	if err := config.VerifyInstanceImages(cluster); err != nil &&
+		cluster.Status.ObservedGeneration != cluster.GetGeneration() {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "InstanceImageVersionMismatch",
+			err.Error())
+	}
 	// Issue Warning Event if postgres version is EOL according to PostgreSQL:
 	// https://www.postgresql.org/support/versioning/
 	currentTime := time.Now()
@@ -269,9 +283,37 @@ func (r *Reconciler) Reconcile(
 			result.RequeueAfter = requeue
 		}
 	}
+	if err == nil {
+		result.RequeueAfter = shorter(result.RequeueAfter, r.observeReplication(ctx, cluster, instances))
+	}
 	if err == nil {
 		err = r.reconcilePatroniSwitchover(ctx, cluster, instances)
 	}
+	if err == nil {
+		err = r.reconcilePatroniCordonSwitchover(ctx, cluster, instances)
+	}
+	if err == nil {
+		var requeue time.Duration
+		if requeue, err = r.reconcilePatroniPreferredPrimary(ctx, cluster, instances); err == nil {
+			result.RequeueAfter = shorter(result.RequeueAfter, requeue)
+		}
+	}
+	if err == nil {
+		var requeue time.Duration
+		if requeue, err = r.reconcilePatroniReinitialize(ctx, cluster, instances); err == nil {
+			result.RequeueAfter = shorter(result.RequeueAfter, requeue)
+		}
+	}
+	if err == nil {
+		var requeue time.Duration
+		if requeue, err = r.reconcilePatroniSlotRetention(ctx, cluster, instances); err == nil {
+			result.RequeueAfter = shorter(result.RequeueAfter, requeue)
+		}
+	}
+	if err == nil {
+		// Return when a scheduled switchover should happen.
+		result.RequeueAfter = shorter(result.RequeueAfter, scheduledSwitchoverDelay(cluster, time.Now()))
+	}
 	// reconcile the Pod service before reconciling any data source in case it is necessary
 	// to start Pods during data source reconciliation that require network connections (e.g.
 	// if it is necessary to start a dedicated repo host to bootstrap a new cluster using its
@@ -314,7 +356,7 @@ func (r *Reconciler) Reconcile(
 		primaryService, err = r.reconcileClusterPrimaryService(ctx, cluster, patroniLeaderService)
 	}
 	if err == nil {
-		replicaService, err = r.reconcileClusterReplicaService(ctx, cluster)
+		replicaService, err = r.reconcileClusterReplicaService(ctx, cluster, instances)
 	}
 	if err == nil {
 		primaryCertificate, err = r.reconcileClusterCertificate(ctx, rootCA, cluster, primaryService, replicaService)
@@ -347,7 +389,10 @@ func (r *Reconciler) Reconcile(
 		err = r.reconcilePostgresDatabases(ctx, cluster, instances)
 	}
 	if err == nil {
-		err = r.reconcilePostgresUsers(ctx, cluster, instances)
+		var requeue time.Duration
+		if requeue, err = r.reconcilePostgresUsers(ctx, cluster, instances); err == nil {
+			result.RequeueAfter = shorter(result.RequeueAfter, requeue)
+		}
 	}
 
 	if err == nil {
@@ -355,9 +400,7 @@ func (r *Reconciler) Reconcile(
 		if next, err = r.reconcilePGBackRest(ctx, cluster,
 			instances, rootCA, backupsSpecFound); err == nil && !next.IsZero() {
 			result.Requeue = result.Requeue || next.Requeue
-			if next.RequeueAfter > 0 {
-				result.RequeueAfter = next.RequeueAfter
-			}
+			result.RequeueAfter = shorter(result.RequeueAfter, next.RequeueAfter)
 		}
 	}
 	if err == nil {
@@ -383,6 +426,21 @@ func (r *Reconciler) Reconcile(
 		// Pods takes precedence.
 		err = r.handlePatroniRestarts(ctx, cluster, instances)
 	}
+	if err == nil {
+		// This is after [Reconciler.reconcileDatabaseInitSQL] so that published
+		// tables can be created there. Subscriptions depend on other clusters,
+		// so do this last to avoid blocking anything else.
+		var requeue time.Duration
+		if requeue, err = r.reconcilePostgresLogicalReplication(ctx, cluster, instances); err == nil {
+			result.RequeueAfter = shorter(result.RequeueAfter, requeue)
+		}
+	}
+	if err == nil {
+		// Return when the maintenance window opens to apply any restarts or
+		// rollouts that were deferred.
+		_, requeue := cluster.Spec.MaintenanceWindow.Open(time.Now())
+		result.RequeueAfter = shorter(result.RequeueAfter, requeue)
+	}
 
 	// at this point everything reconciled successfully, and we can update the
 	// observedGeneration
@@ -393,6 +451,15 @@ func (r *Reconciler) Reconcile(
 	return result, tracing.Escape(span, errors.Join(err, patchClusterStatus()))
 }
 
+// shorter returns the shorter of two requeue delays. A zero delay means there
+// is nothing to requeue.
+func shorter(a, b time.Duration) time.Duration {
+	if a == 0 || (0 < b && b < a) {
+		return b
+	}
+	return a
+}
+
 // deleteControlled safely deletes object when it is controlled by cluster.
 func (r *Reconciler) deleteControlled(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, object client.Object,
@@ -459,7 +526,7 @@ func (r *Reconciler) setOwnerReference(
 // +kubebuilder:rbac:groups="policy",resources="poddisruptionbudgets",verbs={get,list,watch}
 
 // SetupWithManager adds the PostgresCluster controller to the provided runtime manager
-func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
+func (r *Reconciler) SetupWithManager(ctx context.Context, mgr manager.Manager) error {
 	if r.PodExec == nil {
 		var err error
 		r.PodExec, err = runtime.NewPodExecutor(mgr.GetConfig())
@@ -467,8 +534,19 @@ func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
 			return err
 		}
 	}
+	if r.Reader == nil {
+		r.Reader = mgr.GetAPIReader()
+	}
+
+	b := builder.ControllerManagedBy(mgr)
+
+	// Watching Nodes requires permission across the whole cluster. Do so only
+	// when the feature that needs it is enabled.
+	if feature.Enabled(ctx, feature.SwitchoverOnCordon) {
+		b = b.Watches(&corev1.Node{}, r.watchNodes())
+	}
 
-	return builder.ControllerManagedBy(mgr).
+	return b.
 		For(&v1beta1.PostgresCluster{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Endpoints{}).
@@ -484,6 +562,7 @@ func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
 		Owns(&batchv1.CronJob{}).
 		Owns(&policyv1.PodDisruptionBudget{}).
 		Watches(&corev1.Pod{}, r.watchPods()).
+		Watches(&corev1.Secret{}, r.watchSecrets()).
 		Watches(&appsv1.StatefulSet{},
 			r.controllerRefHandlerFuncs()). // watch all StatefulSets
 		Complete(r)
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -616,3 +617,11 @@ spec:
 		})
 	})
 })
+
+func TestShorter(t *testing.T) {
+	assert.Equal(t, shorter(0, 0), time.Duration(0))
+	assert.Equal(t, shorter(0, time.Minute), time.Minute)
+	assert.Equal(t, shorter(time.Minute, 0), time.Minute)
+	assert.Equal(t, shorter(time.Minute, time.Second), time.Second)
+	assert.Equal(t, shorter(time.Second, time.Minute), time.Second)
+}
@@ -6,8 +6,10 @@ package postgrescluster
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -328,6 +330,14 @@ func (r *Reconciler) observeInstances(
 		}
 	}
 
+	// Keep the replication details of members; see [Reconciler.observeReplication].
+	previousMembers := make(map[string]v1beta1.PostgresMemberStatus)
+	for _, statusIS := range cluster.Status.InstanceSets {
+		for _, member := range statusIS.Members {
+			previousMembers[member.Name] = member
+		}
+	}
+
 	// Fill out status sorted by set name.
 	cluster.Status.InstanceSets = cluster.Status.InstanceSets[:0]
 	for _, name := range sets.List(observed.setNames) {
@@ -344,6 +354,33 @@ func (r *Reconciler) observeInstances(
 			if matches, known := instance.PodMatchesPodTemplate(); known && matches {
 				status.UpdatedReplicas++
 			}
+			for _, pod := range instance.Pods {
+				member := v1beta1.PostgresMemberStatus{Name: pod.Name}
+				if previous, ok := previousMembers[pod.Name]; ok {
+					member.ObservedTime = previous.ObservedTime
+					member.Synchronous = previous.Synchronous
+					member.WriteLagBytes = previous.WriteLagBytes
+					member.ReplayLagBytes = previous.ReplayLagBytes
+					member.WriteLagSeconds = previous.WriteLagSeconds
+					member.ReplayLagSeconds = previous.ReplayLagSeconds
+					member.Reinitialize = previous.Reinitialize
+				}
+				for _, container := range pod.Spec.Containers {
+					if container.Name == naming.ContainerDatabase {
+						member.Image = container.Image
+					}
+				}
+				if patroniStatus, ok := patroni.GetPodStatus(pod); ok {
+					member.Role = patroniStatus.Role
+					member.State = patroniStatus.State
+					member.PendingRestart = patroniStatus.PendingRestart
+					member.PendingRestartParameters = patroniStatus.PendingRestartParameters
+					if patroniStatus.Timeline > 0 {
+						member.Timeline = initialize.Int64(patroniStatus.Timeline)
+					}
+				}
+				status.Members = append(status.Members, member)
+			}
 			if autogrow {
 				// Store desired pgData volume size for each instance Pod.
 				// The 'suggested-pgdata-pvc-size' annotation value is stored in the PostgresCluster
@@ -366,12 +403,116 @@ func (r *Reconciler) observeInstances(
 			}
 		}
 
+		slices.SortFunc(status.Members, func(a, b v1beta1.PostgresMemberStatus) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		// Report the image of the set only when every Pod is running it.
+		for i, member := range status.Members {
+			if i == 0 {
+				status.Image = member.Image
+			} else if member.Image != status.Image {
+				status.Image = ""
+				break
+			}
+		}
+
 		cluster.Status.InstanceSets = append(cluster.Status.InstanceSets, status)
 	}
 
 	return observed, err
 }
 
+// replicationObservationInterval is the least amount of time between queries
+// of replication statistics. Every change to status triggers another reconcile,
+// and replication lag changes constantly.
+const replicationObservationInterval = 2 * time.Minute
+
+// observationDelay returns how long to wait after last before querying
+// statistics again. It returns zero when they can be queried now.
+func observationDelay(last *metav1.Time, now time.Time) time.Duration {
+	if last != nil {
+		if next := last.Add(replicationObservationInterval); next.After(now) {
+			return next.Sub(now)
+		}
+	}
+	return 0
+}
+
+// observeReplication fills in the replication details of members in
+// cluster.Status using statistics from the primary. It returns how long to wait
+// before doing so again. Problems are logged but otherwise ignored; these
+// details are informational.
+func (r *Reconciler) observeReplication(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) time.Duration {
+	const container = naming.ContainerDatabase
+	var podExecutor postgres.Executor
+
+	now := time.Now()
+	if wait := observationDelay(cluster.Status.ReplicationObservedTime, now); wait > 0 {
+		return wait
+	}
+
+	// Find the PostgreSQL instance that is streaming to replicas. When there
+	// is none, return early.
+
+	pod, _ := instances.writablePod(container)
+	if pod == nil {
+		return 0
+	}
+
+	// Record this attempt whether or not it succeeds so that the next one
+	// waits, even when there are no standbys to report.
+	observed := metav1.NewTime(now)
+	cluster.Status.ReplicationObservedTime = &observed
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+	podExecutor = func(
+		ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(ctx, pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+	}
+
+	standbys, err := postgres.ReplicationStatusInPostgreSQL(ctx, podExecutor)
+	if err != nil {
+		logging.FromContext(ctx).Error(err, "unable to query replication status")
+		return replicationObservationInterval
+	}
+
+	seconds := func(v *float64) *int64 {
+		if v == nil {
+			return nil
+		}
+		return initialize.Int64(int64(*v))
+	}
+
+	byName := make(map[string]postgres.ReplicationStatus, len(standbys))
+	for _, standby := range standbys {
+		byName[standby.Name] = standby
+	}
+	for i := range cluster.Status.InstanceSets {
+		for j := range cluster.Status.InstanceSets[i].Members {
+			member := &cluster.Status.InstanceSets[i].Members[j]
+			member.ObservedTime = nil
+			member.Synchronous = false
+			member.WriteLagBytes, member.ReplayLagBytes = nil, nil
+			member.WriteLagSeconds, member.ReplayLagSeconds = nil, nil
+
+			if standby, ok := byName[member.Name]; ok {
+				member.ObservedTime = &observed
+				member.Synchronous = standby.SyncState == "sync" || standby.SyncState == "quorum"
+				member.WriteLagBytes = standby.WriteLagBytes
+				member.ReplayLagBytes = standby.ReplayLagBytes
+				member.WriteLagSeconds = seconds(standby.WriteLagSeconds)
+				member.ReplayLagSeconds = seconds(standby.ReplayLagSeconds)
+			}
+		}
+	}
+
+	return replicationObservationInterval
+}
+
 // storeDesiredRequest saves the appropriate request value to the PostgresCluster
 // status. If the value has grown, create an Event.
 func (r *Reconciler) storeDesiredRequest(
@@ -656,6 +797,11 @@ func (r *Reconciler) reconcileInstanceSets(
 		return err
 	}
 
+	err = r.reconcilePrimaryPodDisruptionBudget(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
 	// Rollout changes to instances by calling rolloutInstance.
 	err = r.rolloutInstances(ctx, cluster, instances,
 		func(ctx context.Context, instance *Instance) error {
@@ -806,7 +952,16 @@ func (r *Reconciler) rolloutInstance(
 		ctx, span := tracing.Start(ctx, "patroni-change-primary")
 		defer span.End()
 
-		success, err := patroni.Executor(exec).ChangePrimaryAndWait(ctx, pod.Name, "")
+		// The "ReplicasFirst" strategy chooses a replica that is already
+		// redeployed; see [Reconciler.rolloutInstances].
+		candidate := ""
+		if rolloutReplicasFirst(cluster) {
+			if next := rolloutCandidate(cluster, instances); next != nil {
+				candidate = next.Pods[0].Name
+			}
+		}
+
+		success, err := patroni.Executor(exec).ChangePrimaryAndWait(ctx, pod.Name, candidate)
 		if err = errors.WithStack(err); err == nil && !success {
 			err = errors.New("unable to switchover")
 		}
@@ -892,11 +1047,14 @@ func (r *Reconciler) rolloutInstances(
 	var err error
 	var consider []*Instance
 	var numAvailable int
+	var numBehind int
 	var numSpecified int
 
 	ctx, span := tracing.Start(ctx, "rollout-instances")
 	defer span.End()
 
+	replicasFirst := rolloutReplicasFirst(cluster)
+
 	for _, set := range cluster.Spec.InstanceSets {
 		numSpecified += int(*set.Replicas)
 	}
@@ -922,10 +1080,22 @@ func (r *Reconciler) rolloutInstances(
 			consider = append(consider, instance)
 			continue
 		}
+
+		// The "ReplicasFirst" strategy counts a redeployed replica as
+		// unavailable until it catches up to the primary.
+		if available, known := instance.IsAvailable(); replicasFirst && known && available {
+			if primary, known := instance.IsPrimary(); known && !primary && !rolloutCaughtUp(cluster, instances, instance) {
+				numBehind++
+			}
+		}
 	}
 
 	const maxUnavailable = 1
-	numUnavailable := numSpecified - numAvailable
+	numUnavailable := numSpecified - numAvailable + numBehind
+
+	// Outside the maintenance window, only redeploy instances that are
+	// already unavailable.
+	open, _ := cluster.Spec.MaintenanceWindow.Open(time.Now())
 
 	// When multiple instances need to redeploy, sort them so the lowest
 	// priority instances are first.
@@ -937,6 +1107,19 @@ func (r *Reconciler) rolloutInstances(
 	tracing.Int(span, "specified", numSpecified)
 	tracing.Int(span, "available", numAvailable)
 	tracing.Int(span, "considering", len(consider))
+	tracing.Bool(span, "maintenance-window", open)
+	tracing.Bool(span, "replicas-first", replicasFirst)
+	tracing.Int(span, "behind", numBehind)
+
+	// The "ReplicasFirst" strategy redeploys an available primary only after
+	// every replica and only when there is a replica to switch over to.
+	waitForReplicas := func(instance *Instance) bool {
+		if primary, known := instance.IsPrimary(); !replicasFirst || !known || !primary ||
+			len(instances.forCluster) < 2 {
+			return false
+		}
+		return len(consider) > 1 || rolloutCandidate(cluster, instances) == nil
+	}
 
 	// Redeploy instances up to the allowed maximum while "rolling over" any
 	// unavailable instances.
@@ -945,7 +1128,7 @@ func (r *Reconciler) rolloutInstances(
 		if err == nil {
 			if available, known := instance.IsAvailable(); known && !available {
 				err = redeploy(ctx, instance)
-			} else if numUnavailable < maxUnavailable {
+			} else if open && numUnavailable < maxUnavailable && !waitForReplicas(instance) {
 				err = redeploy(ctx, instance)
 				numUnavailable++
 			}
@@ -955,6 +1138,106 @@ func (r *Reconciler) rolloutInstances(
 	return tracing.Escape(span, err)
 }
 
+// rolloutReplicasFirst returns true when cluster uses the "ReplicasFirst"
+// strategy to redeploy instances.
+func rolloutReplicasFirst(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Rollout != nil &&
+		cluster.Spec.Rollout.Strategy == v1beta1.RolloutReplicasFirst
+}
+
+// rolloutReplayLag returns the replication lag, in bytes, of instance. It
+// prefers the lag that the primary last reported after the instance Pod was
+// created. Otherwise, it compares the WAL positions that Patroni reports for
+// instance and the leader in instances; this is the only source of lag in a
+// standby cluster. It returns nil when the lag is unknown.
+func rolloutReplayLag(
+	cluster *v1beta1.PostgresCluster, instances *observedInstances, instance *Instance,
+) *int64 {
+	if len(instance.Pods) != 1 {
+		return nil
+	}
+
+	pod := instance.Pods[0]
+	for _, set := range cluster.Status.InstanceSets {
+		for _, member := range set.Members {
+			if member.Name == pod.Name && member.ObservedTime != nil &&
+				member.ObservedTime.After(pod.CreationTimestamp.Time) &&
+				member.ReplayLagBytes != nil {
+				return member.ReplayLagBytes
+			}
+		}
+	}
+
+	// Patroni v3.0.4 and later report "streaming" rather than "running" for
+	// a replica that is receiving WAL from its upstream.
+	replica, ok := patroni.GetPodStatus(pod)
+	if !ok || replica.XLogLocation == nil ||
+		(replica.State != "running" && replica.State != "streaming") {
+		return nil
+	}
+	for _, other := range instances.forCluster {
+		if primary, known := other.IsPrimary(); known && primary {
+			leader, ok := patroni.GetPodStatus(other.Pods[0])
+			if !ok || leader.XLogLocation == nil {
+				return nil
+			}
+			lag := max(0, *leader.XLogLocation-*replica.XLogLocation)
+			return &lag
+		}
+	}
+	return nil
+}
+
+// rolloutCaughtUp returns true when instance is a replica with no more than
+// the maximum replay lag in the rollout settings of cluster.
+func rolloutCaughtUp(
+	cluster *v1beta1.PostgresCluster, instances *observedInstances, instance *Instance,
+) bool {
+	maxLag := int64(16 << 20) // one WAL segment
+	if rollout := cluster.Spec.Rollout; rollout != nil && rollout.MaxReplayLag != nil {
+		maxLag = rollout.MaxReplayLag.Value()
+	}
+
+	lag := rolloutReplayLag(cluster, instances, instance)
+	return lag != nil && *lag <= maxLag
+}
+
+// rolloutCandidate returns the redeployed replica that should become primary
+// before the primary is redeployed. It prefers the least replication lag. It
+// returns nil when no replica is ready, redeployed, and caught up.
+func rolloutCandidate(cluster *v1beta1.PostgresCluster, instances *observedInstances) *Instance {
+	var best *Instance
+	var bestLag int64
+
+	for _, instance := range instances.forCluster {
+		if instance.Spec == nil || instance.Spec.Tags.NoFailoverOrDelay() {
+			continue
+		}
+		if primary, known := instance.IsPrimary(); primary || !known {
+			continue
+		}
+		if terminating, known := instance.IsTerminating(); terminating || !known {
+			continue
+		}
+		if ready, known := instance.IsReady(); !ready || !known {
+			continue
+		}
+		if matches, known := instance.PodMatchesPodTemplate(); !matches || !known {
+			continue
+		}
+
+		lag := rolloutReplayLag(cluster, instances, instance)
+		if lag == nil || !rolloutCaughtUp(cluster, instances, instance) {
+			continue
+		}
+		if best == nil || *lag < bestLag || (*lag == bestLag && instance.Name < best.Name) {
+			best, bestLag = instance, *lag
+		}
+	}
+
+	return best
+}
+
 // scaleDownInstances removes extra instances from a cluster until it matches
 // the spec. This function can delete the primary instance and force the
 // cluster to failover under two conditions:
@@ -1045,6 +1328,26 @@ func podsToKeep(instances []corev1.Pod, want map[string]int) []corev1.Pod {
 
 }
 
+// instanceReplicationSource returns the name of the Patroni member from which
+// instances of set should stream changes, if any. This is the first Pod, by
+// name, of the instance set named in the tags of set.
+func instanceReplicationSource(observed *observedInstances, set *v1beta1.PostgresInstanceSetSpec) string {
+	if set.Tags == nil || set.Tags.ReplicateFrom == "" || set.Tags.ReplicateFrom == set.Name {
+		return ""
+	}
+
+	var names []string
+	for _, instance := range observed.bySet[set.Tags.ReplicateFrom] {
+		for _, pod := range instance.Pods {
+			names = append(names, pod.Name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return slices.Min(names)
+}
+
 // +kubebuilder:rbac:groups="apps",resources="statefulsets",verbs={list}
 
 // scaleUpInstances updates the cluster until the number of instances matches
@@ -1106,6 +1409,7 @@ func (r *Reconciler) scaleUpInstances(
 	}
 
 	var err error
+	replicateFrom := instanceReplicationSource(observed, set)
 	for i := range instances {
 		err = r.reconcileInstance(
 			ctx, cluster, observed.byName[instances[i].Name], set,
@@ -1113,7 +1417,7 @@ func (r *Reconciler) scaleUpInstances(
 			rootCA, clusterPodService, instanceServiceAccount,
 			patroniLeaderService, primaryCertificate, instances[i],
 			numInstancePods, clusterVolumes, exporterQueriesConfig, exporterWebConfig,
-			backupsSpecFound, otelConfig,
+			backupsSpecFound, otelConfig, replicateFrom,
 		)
 	}
 	if err == nil {
@@ -1145,6 +1449,7 @@ func (r *Reconciler) reconcileInstance(
 	exporterQueriesConfig, exporterWebConfig *corev1.ConfigMap,
 	backupsSpecFound bool,
 	otelConfig *collector.Config,
+	replicateFrom string,
 ) error {
 	log := logging.FromContext(ctx).WithValues("instance", instance.Name)
 	ctx = logging.NewContext(ctx, log)
@@ -1169,7 +1474,8 @@ func (r *Reconciler) reconcileInstance(
 	)
 
 	if err == nil {
-		instanceConfigMap, err = r.reconcileInstanceConfigMap(ctx, cluster, spec, instance, otelConfig)
+		instanceConfigMap, err = r.reconcileInstanceConfigMap(
+			ctx, cluster, spec, instance, otelConfig, replicateFrom)
 	}
 	if err == nil {
 		instanceCertificates, err = r.reconcileInstanceCertificates(
@@ -1238,7 +1544,7 @@ func (r *Reconciler) reconcileInstance(
 	// containers
 	if err == nil {
 		addNSSWrapper(
-			config.PostgresContainerImage(cluster),
+			config.PostgresInstanceImage(cluster, spec),
 			cluster.Spec.ImagePullPolicy,
 			&instance.Spec.Template)
 
@@ -1308,6 +1614,16 @@ func generateInstanceStatefulSetIntent(_ context.Context,
 			naming.LabelData:        naming.DataPostgres,
 		})
 
+	// Patroni reads its local configuration only when it starts. Restart
+	// instances when their settings change. This annotation is absent when
+	// there are no settings so that existing Pods do not restart.
+	if spec.Config != nil || spec.Tags != nil {
+		hash, _ := safeHash32(func(w io.Writer) error {
+			return json.NewEncoder(w).Encode([]any{spec.Config, spec.Tags})
+		})
+		sts.Spec.Template.Annotations[naming.PatroniInstanceHash] = hash
+	}
+
 	// Don't clutter the namespace with extra ControllerRevisions.
 	// The "controller-revision-hash" label still exists on the Pod.
 	sts.Spec.RevisionHistoryLimit = initialize.Int32(0)
@@ -1409,10 +1725,11 @@ func addPGBackRestToInstancePodSpec(
 // +kubebuilder:rbac:groups="",resources="configmaps",verbs={create,patch}
 
 // reconcileInstanceConfigMap writes the ConfigMap that contains generated
-// files (etc) that apply to instance of cluster.
+// files (etc) that apply to instance of cluster. The replicateFrom argument
+// is the Patroni member, if any, from which instance should stream changes.
 func (r *Reconciler) reconcileInstanceConfigMap(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, spec *v1beta1.PostgresInstanceSetSpec,
-	instance *appsv1.StatefulSet, otelConfig *collector.Config,
+	instance *appsv1.StatefulSet, otelConfig *collector.Config, replicateFrom string,
 ) (*corev1.ConfigMap, error) {
 	instanceConfigMap := &corev1.ConfigMap{ObjectMeta: naming.InstanceConfigMap(instance)}
 	instanceConfigMap.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
@@ -1453,7 +1770,7 @@ func (r *Reconciler) reconcileInstanceConfigMap(
 		}
 	}
 	if err == nil {
-		err = patroni.InstanceConfigMap(ctx, cluster, spec, instanceConfigMap)
+		err = patroni.InstanceConfigMap(ctx, cluster, spec, replicateFrom, instanceConfigMap)
 	}
 	if err == nil {
 		err = errors.WithStack(r.apply(ctx, instanceConfigMap))
@@ -1552,7 +1869,18 @@ func (r *Reconciler) reconcileInstanceSetPodDisruptionBudget(
 	meta.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil(),
 		spec.Metadata.GetAnnotationsOrNil())
 
+	// The eviction API refuses Pods that match more than one budget, so leave
+	// the primary to its own budget when it should move before it is evicted.
+	// - https://docs.k8s.io/tasks/run-application/configure-pdb/#arbitrary-controllers-and-selectors
 	selector := naming.ClusterInstanceSet(cluster.Name, spec.Name)
+	if switchoverOnCordon(ctx, cluster) {
+		selector.MatchExpressions = append(selector.MatchExpressions,
+			metav1.LabelSelectorRequirement{
+				Key:      naming.LabelRole,
+				Operator: metav1.LabelSelectorOpNotIn,
+				Values:   []string{naming.RolePatroniLeader},
+			})
+	}
 	pdb, err := r.generatePodDisruptionBudget(cluster, meta, minAvailable, selector)
 
 	// If 'minAvailable' is set to '0', we will not reconcile the PDB. If one
@@ -1574,3 +1902,42 @@ func (r *Reconciler) reconcileInstanceSetPodDisruptionBudget(
 	}
 	return err
 }
+
+// +kubebuilder:rbac:groups="policy",resources="poddisruptionbudgets",verbs={create,patch,get,delete}
+
+// reconcilePrimaryPodDisruptionBudget creates a PDB that keeps the primary
+// from being evicted when it should move away from a cordoned Node first.
+// Once Patroni moves the primary, its role label moves to another Pod and the
+// previous primary can be evicted. See [Reconciler.reconcilePatroniCordonSwitchover].
+func (r *Reconciler) reconcilePrimaryPodDisruptionBudget(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	meta := naming.ClusterPrimaryPodDisruptionBudget(cluster)
+	meta.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RolePatroniLeader,
+		})
+	meta.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+
+	minAvailable := initialize.Pointer(intstr.FromInt32(1))
+	selector := naming.ClusterPrimary(cluster.Name)
+	pdb, err := r.generatePodDisruptionBudget(cluster, meta, minAvailable, selector)
+
+	if err == nil && !switchoverOnCordon(ctx, cluster) {
+		err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(pdb), pdb))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, pdb))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	// A primary that is not running should not hold up a drain.
+	// - https://docs.k8s.io/tasks/run-application/configure-pdb/#unhealthy-pod-eviction-policy
+	if err == nil {
+		pdb.Spec.UnhealthyPodEvictionPolicy =
+			initialize.Pointer(policyv1.AlwaysAllow)
+		err = errors.WithStack(r.apply(ctx, pdb))
+	}
+	return err
+}
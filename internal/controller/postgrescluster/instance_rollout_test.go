@@ -9,6 +9,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/trace"
@@ -17,6 +18,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -286,6 +288,55 @@ func TestReconcilerRolloutInstances(t *testing.T) {
 		assert.Equal(t, redeploys[0].Name, "one")
 	})
 
+	// Single healthy instance, Pod does not match PodTemplate, maintenance
+	// window is closed; nothing to do.
+	t.Run("SingletonOutdatedOutsideWindow", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "00", Replicas: initialize.Int32(1)},
+		}
+		cluster.Spec.MaintenanceWindow = &v1beta1.MaintenanceWindowSpec{
+			Days: []string{time.Now().UTC().Add(48 * time.Hour).Weekday().String()[:3]},
+		}
+		instances := []*Instance{
+			{
+				Name: "one",
+				Spec: &cluster.Spec.InstanceSets[0],
+				Pods: []*corev1.Pod{{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							"controller-revision-hash":               "beta",
+							"postgres-operator.crunchydata.com/role": "master",
+						},
+					},
+					Status: corev1.PodStatus{
+						Conditions: []corev1.PodCondition{{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						}},
+					},
+				}},
+				Runner: &appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Status: appsv1.StatefulSetStatus{
+						ObservedGeneration: 1,
+						UpdateRevision:     "gamma",
+					},
+				},
+			},
+		}
+		observed := &observedInstances{forCluster: instances}
+
+		ctx := logSpanAttributes(t, ctx)
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed,
+			func(context.Context, *Instance) error {
+				t.Fatal("expected no redeploys")
+				return nil
+			}))
+	})
+
 	// Two ready instances do not match PodTemplate, no primary.
 	t.Run("ManyOutdated", func(t *testing.T) {
 		cluster := new(v1beta1.PostgresCluster)
@@ -637,3 +688,193 @@ func TestReconcilerRolloutInstances(t *testing.T) {
 			}))
 	})
 }
+
+func TestReconcilerRolloutInstancesReplicasFirst(t *testing.T) {
+	ctx := context.Background()
+	reconciler := &Reconciler{}
+	created := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	accumulate := func(on *[]string) func(context.Context, *Instance) error {
+		return func(_ context.Context, i *Instance) error { *on = append(*on, i.Name); return nil }
+	}
+
+	// setup returns a cluster of three ready instances where "one" is the
+	// primary. The primary reports lag for every replica in lags.
+	setup := func(revisions map[string]string, lags map[string]int64) (
+		*v1beta1.PostgresCluster, *observedInstances,
+	) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Rollout = &v1beta1.RolloutSpec{Strategy: v1beta1.RolloutReplicasFirst}
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "00", Replicas: initialize.Int32(3)},
+		}
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{Name: "00"}}
+
+		observed := new(observedInstances)
+		for _, name := range []string{"one", "three", "two"} {
+			pod := &corev1.Pod{}
+			pod.Name = name + "-0"
+			pod.CreationTimestamp = created
+			pod.Labels = map[string]string{"controller-revision-hash": revisions[name]}
+			pod.Status.Conditions = []corev1.PodCondition{{
+				Type: corev1.PodReady, Status: corev1.ConditionTrue,
+			}}
+			if name == "one" {
+				pod.Labels["postgres-operator.crunchydata.com/role"] = "master"
+			}
+
+			member := v1beta1.PostgresMemberStatus{Name: pod.Name}
+			if lag, ok := lags[name]; ok {
+				member.ObservedTime = &metav1.Time{Time: created.Add(time.Minute)}
+				member.ReplayLagBytes = initialize.Int64(lag)
+			}
+			cluster.Status.InstanceSets[0].Members = append(
+				cluster.Status.InstanceSets[0].Members, member)
+
+			observed.forCluster = append(observed.forCluster, &Instance{
+				Name: name,
+				Spec: &cluster.Spec.InstanceSets[0],
+				Pods: []*corev1.Pod{pod},
+				Runner: &appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{Generation: 1},
+					Status: appsv1.StatefulSetStatus{
+						ObservedGeneration: 1,
+						UpdateRevision:     "gamma",
+					},
+				},
+			})
+		}
+		return cluster, observed
+	}
+
+	t.Run("ReplicaFirst", func(t *testing.T) {
+		cluster, observed := setup(map[string]string{
+			"one": "beta", "two": "beta", "three": "beta",
+		}, nil)
+
+		var redeploys []string
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed, accumulate(&redeploys)))
+		assert.DeepEqual(t, redeploys, []string{"three"})
+	})
+
+	t.Run("WaitForLag", func(t *testing.T) {
+		// "three" is updated but has not caught up.
+		cluster, observed := setup(map[string]string{
+			"one": "beta", "two": "beta", "three": "gamma",
+		}, map[string]int64{"three": 32 << 20})
+
+		var redeploys []string
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed, accumulate(&redeploys)))
+		assert.Assert(t, len(redeploys) == 0)
+
+		// A larger maximum allows the rollout to continue.
+		cluster.Spec.Rollout.MaxReplayLag = resource.NewQuantity(64<<20, resource.BinarySI)
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed, accumulate(&redeploys)))
+		assert.DeepEqual(t, redeploys, []string{"two"})
+	})
+
+	t.Run("WaitForObservation", func(t *testing.T) {
+		// "three" is updated, but its lag was reported before its Pod existed.
+		cluster, observed := setup(map[string]string{
+			"one": "beta", "two": "beta", "three": "gamma",
+		}, map[string]int64{"three": 0})
+		observed.forCluster[1].Pods[0].CreationTimestamp = metav1.Now()
+
+		var redeploys []string
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed, accumulate(&redeploys)))
+		assert.Assert(t, len(redeploys) == 0)
+	})
+
+	t.Run("PatroniLag", func(t *testing.T) {
+		// The primary of a standby cluster reports no lag, so Patroni's WAL
+		// positions are compared instead.
+		cluster, observed := setup(map[string]string{
+			"one": "beta", "two": "beta", "three": "gamma",
+		}, nil)
+		observed.forCluster[0].Pods[0].Annotations = map[string]string{
+			"status": `{"role":"standby_leader","state":"running","xlog_location":50000000}`,
+		}
+		observed.forCluster[1].Pods[0].Annotations = map[string]string{
+			"status": `{"role":"replica","state":"streaming","xlog_location":10000000}`,
+		}
+
+		var redeploys []string
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed, accumulate(&redeploys)))
+		assert.Assert(t, len(redeploys) == 0, "expected to wait for %q", "three")
+
+		observed.forCluster[1].Pods[0].Annotations["status"] =
+			`{"role":"replica","state":"streaming","xlog_location":49999000}`
+		assert.Equal(t, *rolloutReplayLag(cluster, observed, observed.forCluster[1]), int64(1000))
+
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed, accumulate(&redeploys)))
+		assert.DeepEqual(t, redeploys, []string{"two"})
+
+		// A replica that is not running is behind.
+		observed.forCluster[1].Pods[0].Annotations["status"] =
+			`{"role":"replica","state":"starting","xlog_location":49999000}`
+		assert.Assert(t, rolloutReplayLag(cluster, observed, observed.forCluster[1]) == nil)
+	})
+
+	t.Run("PrimaryLast", func(t *testing.T) {
+		cluster, observed := setup(map[string]string{
+			"one": "beta", "two": "gamma", "three": "gamma",
+		}, map[string]int64{"two": 0, "three": 0})
+
+		var redeploys []string
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed, accumulate(&redeploys)))
+		assert.DeepEqual(t, redeploys, []string{"one"})
+
+		t.Run("NoCandidate", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Status.InstanceSets[0].Members[1].ReplayLagBytes = initialize.Int64(32 << 20)
+			cluster.Status.InstanceSets[0].Members[2].ReplayLagBytes = initialize.Int64(32 << 20)
+
+			var redeploys []string
+			assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed, accumulate(&redeploys)))
+			assert.Assert(t, len(redeploys) == 0)
+		})
+	})
+
+	t.Run("Candidate", func(t *testing.T) {
+		cluster, observed := setup(map[string]string{
+			"one": "beta", "two": "gamma", "three": "gamma",
+		}, map[string]int64{"two": 100, "three": 200})
+
+		candidate := rolloutCandidate(cluster, observed)
+		assert.Assert(t, candidate != nil)
+		assert.Equal(t, candidate.Name, "two", "expected the least lag")
+
+		// Instances that cannot fail over are not candidates.
+		observed.forCluster[2].Spec = &v1beta1.PostgresInstanceSetSpec{
+			Name: "01", Tags: &v1beta1.PatroniTagsSpec{NoFailover: initialize.Bool(true)},
+		}
+
+		candidate = rolloutCandidate(cluster, observed)
+		assert.Assert(t, candidate != nil)
+		assert.Equal(t, candidate.Name, "three")
+
+		// Outdated instances are not candidates.
+		observed.forCluster[1].Pods[0].Labels["controller-revision-hash"] = "beta"
+		assert.Assert(t, rolloutCandidate(cluster, observed) == nil)
+	})
+
+	t.Run("Switchover", func(t *testing.T) {
+		cluster, observed := setup(map[string]string{
+			"one": "beta", "two": "gamma", "three": "gamma",
+		}, map[string]int64{"two": 100, "three": 0})
+		observed.forCluster[0].Pods[0].Namespace = "ns1"
+
+		reconciler := &Reconciler{}
+		reconciler.PodExec = func(
+			_ context.Context, _, _, _ string, _ io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			assert.DeepEqual(t, command[:2], []string{"patronictl", "switchover"})
+			assert.Assert(t, sets.NewString(command...).Has("--candidate=three-0"))
+
+			_, _ = stdout.Write([]byte("switched over"))
+			return nil
+		}
+
+		assert.NilError(t, reconciler.rolloutInstance(ctx, cluster, observed, observed.forCluster[0]))
+	})
+}
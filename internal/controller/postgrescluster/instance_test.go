@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -30,9 +31,11 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/feature"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
@@ -1362,6 +1365,107 @@ func TestGenerateInstanceStatefulSetIntent(t *testing.T) {
 		run: func(t *testing.T, ss *appsv1.StatefulSet) {
 			assert.Assert(t, ss.Spec.Template.Spec.Affinity != nil)
 		},
+	}, {
+		name: "load balanced among excluded sets, labeled directly",
+		ip: intentParams{
+			cluster: func() *v1beta1.PostgresCluster {
+				cluster := testCluster()
+				cluster.Spec.InstanceSets = append(cluster.Spec.InstanceSets,
+					v1beta1.PostgresInstanceSetSpec{Name: "reporting", Tags: &v1beta1.PatroniTagsSpec{
+						NoLoadBalance: initialize.Bool(true),
+					}})
+				return cluster
+			}(),
+		},
+		run: func(t *testing.T, ss *appsv1.StatefulSet) {
+			// The label is added to Pods directly so that they do not restart.
+			// See [Reconciler.reconcileLoadBalanceLabels].
+			_, found := ss.Spec.Template.Labels[naming.LabelLoadBalance]
+			assert.Assert(t, !found)
+		},
+	}, {
+		name: "excluded from load balancing",
+		ip: intentParams{
+			cluster: func() *v1beta1.PostgresCluster {
+				cluster := testCluster()
+				cluster.Spec.InstanceSets = append(cluster.Spec.InstanceSets,
+					v1beta1.PostgresInstanceSetSpec{Name: "reporting", Tags: &v1beta1.PatroniTagsSpec{
+						NoLoadBalance: initialize.Bool(true),
+					}})
+				return cluster
+			}(),
+			spec: &v1beta1.PostgresInstanceSetSpec{
+				Name: "reporting",
+				Tags: &v1beta1.PatroniTagsSpec{NoLoadBalance: initialize.Bool(true)},
+			},
+		},
+		run: func(t *testing.T, ss *appsv1.StatefulSet) {
+			_, found := ss.Spec.Template.Labels[naming.LabelLoadBalance]
+			assert.Assert(t, !found)
+		},
+	}, {
+		name: "no sets excluded from load balancing",
+		run: func(t *testing.T, ss *appsv1.StatefulSet) {
+			_, found := ss.Spec.Template.Labels[naming.LabelLoadBalance]
+			assert.Assert(t, !found)
+		},
+	}, {
+		name: "instance tags",
+		ip: intentParams{
+			spec: &v1beta1.PostgresInstanceSetSpec{
+				Name: "reporting",
+				Tags: &v1beta1.PatroniTagsSpec{NoFailover: initialize.Bool(true)},
+			},
+		},
+		run: func(t *testing.T, ss *appsv1.StatefulSet) {
+			hash := ss.Spec.Template.Annotations[naming.PatroniInstanceHash]
+			assert.Assert(t, hash != "")
+
+			// The hash changes with the tags.
+			other := ss.DeepCopy()
+			generateInstanceStatefulSetIntent(context.Background(), testCluster(),
+				&v1beta1.PostgresInstanceSetSpec{
+					Name: "reporting",
+					Tags: &v1beta1.PatroniTagsSpec{NoFailover: initialize.Bool(false)},
+				}, "", "", other, 0)
+			assert.Assert(t, other.Spec.Template.Annotations[naming.PatroniInstanceHash] != hash)
+		},
+	}, {
+		name: "no instance tags",
+		run: func(t *testing.T, ss *appsv1.StatefulSet) {
+			_, found := ss.Spec.Template.Annotations[naming.PatroniInstanceHash]
+			assert.Assert(t, !found)
+		}}, {
+		name: "instance configuration",
+		ip: intentParams{
+			spec: &v1beta1.PostgresInstanceSetSpec{
+				Name: "reporting",
+				Config: &v1beta1.PostgresInstanceConfigSpec{
+					Parameters: map[string]intstr.IntOrString{"work_mem": intstr.FromString("64MB")},
+				},
+			},
+		},
+		run: func(t *testing.T, ss *appsv1.StatefulSet) {
+			hash := ss.Spec.Template.Annotations[naming.PatroniInstanceHash]
+			assert.Assert(t, hash != "")
+
+			// The hash changes with the parameters.
+			other := ss.DeepCopy()
+			generateInstanceStatefulSetIntent(context.Background(), testCluster(),
+				&v1beta1.PostgresInstanceSetSpec{
+					Name: "reporting",
+					Config: &v1beta1.PostgresInstanceConfigSpec{
+						Parameters: map[string]intstr.IntOrString{"work_mem": intstr.FromString("1GB")},
+					},
+				}, "", "", other, 0)
+			assert.Assert(t, other.Spec.Template.Annotations[naming.PatroniInstanceHash] != hash)
+		},
+	}, {
+		name: "no instance configuration",
+		run: func(t *testing.T, ss *appsv1.StatefulSet) {
+			_, found := ss.Spec.Template.Annotations[naming.PatroniInstanceHash]
+			assert.Assert(t, !found)
+		},
 	}, {
 		name: "custom tolerations",
 		ip: intentParams{
@@ -1949,6 +2053,60 @@ func TestReconcileInstanceSetPodDisruptionBudget(t *testing.T) {
 	})
 }
 
+func TestReconcilePrimaryPodDisruptionBudget(t *testing.T) {
+	gate := feature.NewGate()
+	assert.NilError(t, gate.SetFromMap(map[string]bool{
+		feature.SwitchoverOnCordon: true,
+	}))
+	ctx := feature.NewContext(context.Background(), gate)
+
+	_, cc := setupKubernetes(t)
+	require.ParallelCapacity(t, 1)
+
+	r := &Reconciler{
+		Client: cc,
+		Owner:  client.FieldOwner(t.Name()),
+	}
+	ns := setupNamespace(t, cc)
+
+	cluster := testCluster()
+	cluster.Namespace = ns.Name
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{SwitchoverOnCordon: initialize.Bool(true)}
+	spec := &cluster.Spec.InstanceSets[0]
+	spec.MinAvailable = initialize.Pointer(intstr.FromInt32(1))
+
+	assert.NilError(t, r.Client.Create(ctx, cluster))
+	t.Cleanup(func() { assert.Check(t, r.Client.Delete(ctx, cluster)) })
+
+	assert.NilError(t, r.reconcilePrimaryPodDisruptionBudget(ctx, cluster))
+	assert.NilError(t, r.reconcileInstanceSetPodDisruptionBudget(ctx, cluster, spec))
+
+	primary := &policyv1.PodDisruptionBudget{}
+	assert.NilError(t, r.Client.Get(ctx,
+		naming.AsObjectKey(naming.ClusterPrimaryPodDisruptionBudget(cluster)), primary))
+	assert.DeepEqual(t, primary.Spec.MinAvailable, initialize.Pointer(intstr.FromInt32(1)))
+	assert.DeepEqual(t, primary.Spec.Selector.MatchLabels[naming.LabelRole], naming.RolePatroniLeader)
+
+	// The primary matches only its own budget.
+	set := &policyv1.PodDisruptionBudget{}
+	assert.NilError(t, r.Client.Get(ctx,
+		naming.AsObjectKey(naming.InstanceSet(cluster, spec)), set))
+	assert.DeepEqual(t, set.Spec.Selector.MatchExpressions, []metav1.LabelSelectorRequirement{{
+		Key:      naming.LabelRole,
+		Operator: metav1.LabelSelectorOpNotIn,
+		Values:   []string{naming.RolePatroniLeader},
+	}})
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni = nil
+
+		assert.NilError(t, r.reconcilePrimaryPodDisruptionBudget(ctx, cluster))
+		err := r.Client.Get(ctx, client.ObjectKeyFromObject(primary), primary)
+		assert.Assert(t, apierrors.IsNotFound(err), "expected NotFound, got %v", err)
+	})
+}
+
 func TestCleanupDisruptionBudgets(t *testing.T) {
 	ctx := context.Background()
 	_, cc := setupKubernetes(t)
@@ -2045,3 +2203,224 @@ func TestCleanupDisruptionBudgets(t *testing.T) {
 		})
 	})
 }
+
+func TestObserveReplication(t *testing.T) {
+	ctx := context.Background()
+
+	primary := &corev1.Pod{}
+	primary.Namespace, primary.Name = "ns1", "hippo-00-abcd-0"
+	primary.Annotations = map[string]string{"status": `{"role":"primary"}`}
+	primary.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{primary}},
+	}}
+
+	newCluster := func() *v1beta1.PostgresCluster {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{
+			Name: "00",
+			Members: []v1beta1.PostgresMemberStatus{
+				{Name: "hippo-00-abcd-0", Role: "primary"},
+				{Name: "hippo-00-efgh-0", Role: "replica", ReplayLagBytes: initialize.Int64(99)},
+			},
+		}, {
+			Name: "01",
+			Members: []v1beta1.PostgresMemberStatus{
+				{Name: "hippo-01-ijkl-0", Role: "replica"},
+			},
+		}}
+		return cluster
+	}
+
+	t.Run("Lag", func(t *testing.T) {
+		calls := 0
+		r := &Reconciler{PodExec: func(
+			_ context.Context, namespace, pod, container string,
+			_ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			calls++
+			assert.Equal(t, namespace, "ns1")
+			assert.Equal(t, pod, "hippo-00-abcd-0")
+			assert.Equal(t, container, naming.ContainerDatabase)
+
+			_, _ = io.WriteString(stdout, `
+{"name":"hippo-01-ijkl-0","sync_state":"sync","write_lag_bytes":10,"replay_lag_bytes":20,"write_lag_seconds":0.5,"replay_lag_seconds":3.7}
+`)
+			return nil
+		}}
+
+		cluster := newCluster()
+		requeue := r.observeReplication(ctx, cluster, instances)
+		assert.Equal(t, calls, 1)
+		assert.Equal(t, requeue, replicationObservationInterval)
+
+		// Replicas no longer streaming lose their details.
+		stale := cluster.Status.InstanceSets[0].Members[1]
+		assert.Assert(t, stale.ObservedTime == nil)
+		assert.Assert(t, stale.ReplayLagBytes == nil)
+
+		streaming := cluster.Status.InstanceSets[1].Members[0]
+		assert.Assert(t, streaming.ObservedTime != nil)
+		assert.Assert(t, streaming.Synchronous)
+		assert.DeepEqual(t, streaming.WriteLagBytes, initialize.Int64(10))
+		assert.DeepEqual(t, streaming.ReplayLagBytes, initialize.Int64(20))
+		assert.DeepEqual(t, streaming.WriteLagSeconds, initialize.Int64(0))
+		assert.DeepEqual(t, streaming.ReplayLagSeconds, initialize.Int64(3))
+
+		// Another call soon after does nothing.
+		requeue = r.observeReplication(ctx, cluster, instances)
+		assert.Equal(t, calls, 1)
+		assert.Assert(t, requeue > 0 && requeue <= replicationObservationInterval)
+	})
+
+	t.Run("NoStandbys", func(t *testing.T) {
+		calls := 0
+		r := &Reconciler{PodExec: func(
+			context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			calls++
+			return nil
+		}}
+
+		cluster := newCluster()
+		requeue := r.observeReplication(ctx, cluster, instances)
+		assert.Equal(t, calls, 1)
+		assert.Equal(t, requeue, replicationObservationInterval)
+		assert.Assert(t, cluster.Status.ReplicationObservedTime != nil)
+
+		// Another call soon after does nothing, though no member was observed.
+		requeue = r.observeReplication(ctx, cluster, instances)
+		assert.Equal(t, calls, 1)
+		assert.Assert(t, requeue > 0 && requeue <= replicationObservationInterval)
+
+		// A call after the interval queries again.
+		cluster.Status.ReplicationObservedTime = &metav1.Time{
+			Time: time.Now().Add(-replicationObservationInterval),
+		}
+		_ = r.observeReplication(ctx, cluster, instances)
+		assert.Equal(t, calls, 2)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		calls := 0
+		r := &Reconciler{PodExec: func(
+			context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			calls++
+			return errors.New("boom")
+		}}
+
+		cluster := newCluster()
+		requeue := r.observeReplication(ctx, cluster, instances)
+		assert.Equal(t, requeue, replicationObservationInterval)
+		assert.DeepEqual(t, cluster.Status.InstanceSets[0].Members[1].ReplayLagBytes, initialize.Int64(99))
+
+		// Failures wait, too.
+		_ = r.observeReplication(ctx, cluster, instances)
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("NoPrimary", func(t *testing.T) {
+		r := &Reconciler{}
+
+		cluster := newCluster()
+		requeue := r.observeReplication(ctx, cluster, new(observedInstances))
+		assert.Equal(t, requeue, time.Duration(0))
+	})
+}
+
+func TestObserveInstancesMembers(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "00", Image: "postgres:17.3"}}
+
+	pod := func(name, status string) *corev1.Pod {
+		pod := &corev1.Pod{}
+		pod.Namespace, pod.Name = "ns1", name+"-0"
+		pod.Labels = map[string]string{
+			naming.LabelCluster:     "hippo",
+			naming.LabelInstanceSet: "00",
+			naming.LabelInstance:    name,
+		}
+		pod.Annotations = map[string]string{"status": status}
+		pod.Spec.Containers = []corev1.Container{
+			{Name: naming.ContainerDatabase, Image: "postgres:17.2"},
+			{Name: "other", Image: "other"},
+		}
+		return pod
+	}
+
+	r := &Reconciler{Client: fake.NewClientBuilder().WithObjects(
+		pod("hippo-00-efgh", `{"role":"replica","state":"streaming","timeline":2}`),
+		pod("hippo-00-abcd", `{"role":"master","state":"running","timeline":2,"pending_restart":true,"pending_restart_reason":{"shared_buffers":{}}}`),
+	).Build()}
+
+	// Replication details of existing members are kept.
+	cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{
+		Name: "00",
+		Members: []v1beta1.PostgresMemberStatus{
+			{Name: "hippo-00-efgh-0", ReplayLagBytes: initialize.Int64(7)},
+			{Name: "gone", ReplayLagBytes: initialize.Int64(8)},
+		},
+	}}
+
+	_, err := r.observeInstances(ctx, cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, len(cluster.Status.InstanceSets), 1)
+	assert.Equal(t, cluster.Status.InstanceSets[0].Image, "postgres:17.2",
+		"expected the image of the Pods, not the spec")
+	assert.DeepEqual(t, cluster.Status.InstanceSets[0].Members, []v1beta1.PostgresMemberStatus{
+		{
+			Name: "hippo-00-abcd-0", Role: "primary", State: "running",
+			Image: "postgres:17.2", Timeline: initialize.Int64(2), PendingRestart: true,
+			PendingRestartParameters: []string{"shared_buffers"},
+		},
+		{
+			Name: "hippo-00-efgh-0", Role: "replica", State: "streaming",
+			Image: "postgres:17.2", Timeline: initialize.Int64(2), ReplayLagBytes: initialize.Int64(7),
+		},
+	})
+
+	t.Run("MixedImages", func(t *testing.T) {
+		third := pod("hippo-00-ijkl", `{"role":"replica","state":"streaming","timeline":2}`)
+		third.Spec.Containers[0].Image = "postgres:17.3"
+		assert.NilError(t, r.Client.Create(ctx, third))
+
+		_, err := r.observeInstances(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Equal(t, len(cluster.Status.InstanceSets[0].Members), 3)
+		assert.Equal(t, cluster.Status.InstanceSets[0].Image, "",
+			"expected no image while Pods differ")
+	})
+}
+
+func TestInstanceReplicationSource(t *testing.T) {
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	observed := &observedInstances{bySet: map[string][]*Instance{
+		"00":      {{Name: "hippo-00-wxyz", Pods: []*corev1.Pod{pod("hippo-00-wxyz-0")}}},
+		"reports": {{Name: "hippo-reports-wxyz"}, {Name: "hippo-reports-abcd", Pods: []*corev1.Pod{pod("hippo-reports-abcd-0")}}},
+	}}
+
+	set := &v1beta1.PostgresInstanceSetSpec{Name: "delayed"}
+	assert.Equal(t, instanceReplicationSource(observed, set), "")
+
+	// The first Pod of the named set.
+	set.Tags = &v1beta1.PatroniTagsSpec{ReplicateFrom: "reports"}
+	assert.Equal(t, instanceReplicationSource(observed, set), "hippo-reports-abcd-0")
+
+	// Nothing when the named set has no Pods.
+	set.Tags.ReplicateFrom = "missing"
+	assert.Equal(t, instanceReplicationSource(observed, set), "")
+
+	// Nothing when the set names itself.
+	set.Name, set.Tags.ReplicateFrom = "00", "00"
+	assert.Equal(t, instanceReplicationSource(observed, set), "")
+}
@@ -5,17 +5,24 @@
 package postgrescluster
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/crunchydata/postgres-operator/internal/feature"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
@@ -52,6 +59,13 @@ func (r *Reconciler) handlePatroniRestarts(
 	const container = naming.ContainerDatabase
 	var primaryNeedsRestart, replicaNeedsRestart *Instance
 
+	// Leave pending restarts alone until the maintenance window opens. Patroni
+	// continues to report them, and [Reconciler.Reconcile] returns when the
+	// window opens.
+	if open, _ := cluster.Spec.MaintenanceWindow.Open(time.Now()); !open {
+		return nil
+	}
+
 	// Look for one primary and one replica that need to restart. Ignore
 	// containers that are terminating or not running; Kubernetes will start
 	// them again, and calls to their Patroni API will likely be interrupted anyway.
@@ -126,6 +140,177 @@ func (r *Reconciler) handlePatroniRestarts(
 	return nil
 }
 
+// reinitializeDeadline is how long an instance can take to reinitialize before
+// the request is considered failed. Copying a large data directory from the
+// pgBackRest repository can take hours.
+const reinitializeDeadline = 12 * time.Hour
+
+// reconcilePatroniReinitialize looks for instance Pods with a new value in
+// their reinitialize annotation and asks Patroni to recreate their data
+// directories, one at a time. Patroni uses the same replica methods it uses
+// for new instances, so data comes from the pgBackRest repository when one is
+// available. It refuses to reinitialize the primary. Progress is reported in
+// member status, the "InstanceReinitializing" condition, and events.
+// A request fails when PostgreSQL does not start or is not running again
+// before [reinitializeDeadline].
+func (r *Reconciler) reconcilePatroniReinitialize(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (time.Duration, error) {
+	const container = naming.ContainerDatabase
+	now := metav1.Now()
+
+	// Patroni updates the state of each member once every "loop_wait".
+	loopWait := 10 * time.Second
+	if cluster.Spec.Patroni != nil && cluster.Spec.Patroni.SyncPeriodSeconds != nil {
+		loopWait = time.Duration(*cluster.Spec.Patroni.SyncPeriodSeconds) * time.Second
+	}
+
+	members := make(map[string]*v1beta1.PostgresMemberStatus)
+	for i := range cluster.Status.InstanceSets {
+		for j := range cluster.Status.InstanceSets[i].Members {
+			member := &cluster.Status.InstanceSets[i].Members[j]
+			members[member.Name] = member
+		}
+	}
+
+	condition := func(status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:    v1beta1.InstanceReinitializing,
+			Status:  status,
+			Reason:  reason,
+			Message: message,
+
+			ObservedGeneration: cluster.GetGeneration(),
+		})
+	}
+
+	// Check on any instance that is already reinitializing. Its state in DCS
+	// can be stale for one "loop_wait" after the request, so wait for two
+	// before trusting that PostgreSQL is running again or has failed.
+	// - https://patroni.readthedocs.io/en/latest/rest_api.html#health-check-endpoints
+	inProgress := false
+	for _, member := range members {
+		if member.Reinitialize == nil || member.Reinitialize.CompletionTime != nil {
+			continue
+		}
+
+		var elapsed time.Duration
+		if member.Reinitialize.StartTime != nil {
+			elapsed = now.Sub(member.Reinitialize.StartTime.Time)
+		}
+
+		switch {
+		case elapsed <= 2*loopWait:
+			inProgress = true
+
+		case member.State == "running" || member.State == "streaming":
+			member.Reinitialize.CompletionTime = now.DeepCopy()
+			member.Reinitialize.Outcome = v1beta1.ReinitializeSucceeded
+
+			condition(metav1.ConditionFalse, v1beta1.ReinitializeSucceeded,
+				fmt.Sprintf("Reinitialized %s", member.Name))
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "Reinitialized",
+				"Instance %s is running again", member.Name)
+
+		case member.State == "crashed" || member.State == "start failed" ||
+			elapsed > reinitializeDeadline:
+			member.Reinitialize.CompletionTime = now.DeepCopy()
+			member.Reinitialize.Outcome = v1beta1.ReinitializeFailed
+
+			message := fmt.Sprintf("Instance %s is %q after reinitializing for %s",
+				member.Name, member.State, elapsed.Round(time.Second))
+			condition(metav1.ConditionFalse, v1beta1.ReinitializeFailed, message)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "ReinitializeFailed", message)
+
+		default:
+			inProgress = true
+		}
+	}
+	if inProgress {
+		return loopWait, nil
+	}
+
+	// The instance that was reinitializing may have been removed from the
+	// cluster. Nothing is in progress, so clear the condition.
+	if meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.InstanceReinitializing) {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.InstanceReinitializing)
+	}
+
+	for _, instance := range instances.forCluster {
+		if len(instance.Pods) != 1 {
+			continue
+		}
+
+		pod := instance.Pods[0]
+		member := members[pod.Name]
+		trigger := pod.GetAnnotations()[naming.PatroniReinitialize]
+
+		if trigger == "" || member == nil ||
+			(member.Reinitialize != nil && member.Reinitialize.Trigger == trigger) {
+			continue
+		}
+
+		// Wait until Patroni reports the role of this instance and its API
+		// can be reached.
+		primary, known := instance.IsPrimary()
+		if !known {
+			continue
+		}
+		if primary {
+			member.Reinitialize = &v1beta1.PostgresMemberReinitializeStatus{
+				Trigger:        trigger,
+				CompletionTime: now.DeepCopy(),
+				Outcome:        v1beta1.ReinitializeRefused,
+			}
+
+			message := fmt.Sprintf("Refusing to reinitialize %s because it is the primary", pod.Name)
+			condition(metav1.ConditionFalse, v1beta1.ReinitializeRefused, message)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "ReinitializeRefused", message)
+			continue
+		}
+		if running, known := instance.IsRunning(container); !running || !known {
+			continue
+		}
+
+		exec := patroni.Executor(func(
+			ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			return r.PodExec(ctx, pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+		})
+
+		accepted, err := exec.ReinitializeMember(ctx, naming.PatroniScope(cluster), pod.Name)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+
+		member.Reinitialize = &v1beta1.PostgresMemberReinitializeStatus{
+			Trigger:   trigger,
+			StartTime: now.DeepCopy(),
+			Outcome:   v1beta1.ReinitializeInProgress,
+		}
+
+		if !accepted {
+			member.Reinitialize.CompletionTime = now.DeepCopy()
+			member.Reinitialize.Outcome = v1beta1.ReinitializeFailed
+
+			message := fmt.Sprintf("Patroni did not reinitialize %s", pod.Name)
+			condition(metav1.ConditionFalse, v1beta1.ReinitializeFailed, message)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "ReinitializeFailed", message)
+			continue
+		}
+
+		condition(metav1.ConditionTrue, v1beta1.ReinitializeInProgress,
+			fmt.Sprintf("Reinitializing %s", pod.Name))
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "Reinitializing",
+			"Patroni is recreating the data directory of %s", pod.Name)
+
+		// Reinitialize one instance at a time.
+		return loopWait, nil
+	}
+
+	return 0, nil
+}
+
 // +kubebuilder:rbac:groups="",resources="services",verbs={create,patch}
 
 // reconcilePatroniDistributedConfiguration sets labels and ownership on the
@@ -175,6 +360,8 @@ func (r *Reconciler) reconcilePatroniDynamicConfiguration(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
 	pgHBAs *postgres.OrderedHBAs, pgParameters *postgres.ParameterSet,
 ) error {
+	r.validatePatroniSynchronous(cluster)
+
 	if !patroni.ClusterBootstrapped(cluster) {
 		// Patroni has not yet bootstrapped. Dynamic configuration happens through
 		// configuration files during bootstrap, so there's nothing to do here.
@@ -343,9 +530,78 @@ func (r *Reconciler) reconcilePatroniStatus(
 		}
 	}
 
+	// When synchronous replication is enabled, Patroni writes the names of
+	// its synchronous standbys to DCS.
+	// - https://patroni.readthedocs.io/en/latest/replication_modes.html
+	if err == nil {
+		sync := &corev1.Endpoints{ObjectMeta: naming.PatroniSynchronousState(cluster)}
+		err = errors.WithStack(client.IgnoreNotFound(
+			r.Client.Get(ctx, client.ObjectKeyFromObject(sync), sync)))
+
+		var standbys []string
+		if err == nil && cluster.Spec.Patroni != nil && cluster.Spec.Patroni.Synchronous != nil {
+			for _, name := range strings.Split(sync.Annotations["sync_standby"], ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					standbys = append(standbys, name)
+				}
+			}
+		}
+		cluster.Status.Patroni.SynchronousStandbys = standbys
+	}
+
 	return requeue, err
 }
 
+// validatePatroniSynchronous reports in the "SynchronousReplication" condition
+// whether or not cluster has as many replicas as the synchronous standbys it
+// asks for. Patroni tolerates fewer, but transactions may wait indefinitely
+// when strict mode is enabled. A warning event is emitted when there become
+// too few.
+func (r *Reconciler) validatePatroniSynchronous(cluster *v1beta1.PostgresCluster) {
+	if cluster.Spec.Patroni == nil || cluster.Spec.Patroni.Synchronous == nil {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.SynchronousReplication)
+		return
+	}
+
+	var members int32
+	for _, set := range cluster.Spec.InstanceSets {
+		if set.Replicas != nil {
+			members += *set.Replicas
+		} else {
+			members++
+		}
+	}
+
+	standbys := int32(1)
+	if cluster.Spec.Patroni.Synchronous.Standbys != nil {
+		standbys = *cluster.Spec.Patroni.Synchronous.Standbys
+	}
+
+	condition := metav1.Condition{
+		ObservedGeneration: cluster.Generation,
+		Type:               v1beta1.SynchronousReplication,
+		Status:             metav1.ConditionTrue,
+		Reason:             "EnoughReplicas",
+		Message:            fmt.Sprintf("The cluster has replicas for %d synchronous standbys", standbys),
+	}
+
+	// One member of the cluster is always the primary.
+	if replicas := members - 1; standbys > replicas {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "TooFewReplicas"
+		condition.Message = fmt.Sprintf(
+			"Patroni is configured for %d synchronous standbys, but the cluster has %d replicas",
+			standbys, replicas)
+
+		if !meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.SynchronousReplication) {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidSynchronousStandbys",
+				condition.Message)
+		}
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+}
+
 // reconcileReplicationSecret creates a secret containing the TLS
 // certificate, key and CA certificate for use with the replication and
 // pg_rewind accounts in Postgres.
@@ -473,6 +729,20 @@ func (r *Reconciler) reconcilePatroniSwitchover(ctx context.Context,
 		return nil
 	}
 
+	// Wait until the scheduled time, if any. Patroni can schedule a switchover
+	// too, but it needs a candidate right away. Waiting here lets the operator
+	// choose a candidate when the time arrives. See [Reconciler.Reconcile].
+	if wait := scheduledSwitchoverDelay(cluster, time.Now()); wait > 0 {
+		cluster.Status.Patroni.LastSwitchover = &v1beta1.PatroniSwitchoverStatus{
+			Trigger:       annotation,
+			Type:          spec.Type,
+			ScheduledTime: spec.ScheduledTime.DeepCopy(),
+			Outcome:       v1beta1.PatroniSwitchoverScheduled,
+		}
+		cluster.Status.Patroni.SwitchoverTimeline = nil
+		return nil
+	}
+
 	// If we've reached this point, we assume a switchover request or in progress
 	// and need to make sure the prerequisites are met, e.g., more than one pod,
 	// a running instance to issue the switchover command to, etc.
@@ -585,10 +855,23 @@ func (r *Reconciler) reconcilePatroniSwitchover(ctx context.Context,
 		}
 	}
 
-	// If target instance has not been provided, we will pass in an empty string to patronictl
+	// If target instance has not been provided, choose one. When there are
+	// no good candidates, we will pass in an empty string to patronictl.
 	nextPrimary := ""
 	if targetInstance != nil {
 		nextPrimary = targetInstance.Pods[0].Name
+	} else if candidate, _, err := r.switchoverCandidate(ctx, cluster, instances); err != nil {
+		return err
+	} else if candidate != nil {
+		nextPrimary = candidate.Pods[0].Name
+	}
+
+	last := &v1beta1.PatroniSwitchoverStatus{
+		Trigger:       annotation,
+		Type:          spec.Type,
+		Candidate:     nextPrimary,
+		ScheduledTime: spec.ScheduledTime.DeepCopy(),
+		StartTime:     initialize.Pointer(metav1.Now()),
 	}
 
 	success, err := action(ctx, exec, nextPrimary)
@@ -596,6 +879,16 @@ func (r *Reconciler) reconcilePatroniSwitchover(ctx context.Context,
 		err = errors.New("unable to switchover")
 	}
 
+	// Patroni returns after the election completes, so the switchover is
+	// finished here whether or not it succeeded.
+	last.CompletionTime = initialize.Pointer(metav1.Now())
+	last.Outcome = v1beta1.PatroniSwitchoverSucceeded
+	if err != nil {
+		last.Outcome = v1beta1.PatroniSwitchoverFailed
+		last.Message = err.Error()
+	}
+	cluster.Status.Patroni.LastSwitchover = last
+
 	// If we've reached this point, a switchover has successfully been triggered
 	// and we set the status accordingly.
 	if err == nil {
@@ -605,3 +898,420 @@ func (r *Reconciler) reconcilePatroniSwitchover(ctx context.Context,
 
 	return err
 }
+
+// switchoverOnCordon returns whether or not the primary of cluster should move
+// away from a Node that is unschedulable. See [Reconciler.reconcilePatroniCordonSwitchover].
+func switchoverOnCordon(ctx context.Context, cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Patroni != nil &&
+		initialize.FromPointer(cluster.Spec.Patroni.SwitchoverOnCordon) &&
+		feature.Enabled(ctx, feature.SwitchoverOnCordon)
+}
+
+// reconcilePatroniCordonSwitchover moves the primary to a replica on another
+// Node when the Node of the primary is unschedulable. Nodes are cordoned
+// before they are drained, so this changes the primary in a controlled way
+// before its Pod is evicted. See [Reconciler.watchNodes] and
+// [Reconciler.reconcilePrimaryPodDisruptionBudget].
+func (r *Reconciler) reconcilePatroniCordonSwitchover(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	const container = naming.ContainerDatabase
+
+	if cluster.Spec.Patroni == nil ||
+		!initialize.FromPointer(cluster.Spec.Patroni.SwitchoverOnCordon) {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.SwitchoverOnCordon)
+		return nil
+	}
+
+	// setCondition reports whether or not the primary can move away from
+	// a cordoned Node. Problems are reported in an event once, when they
+	// first appear.
+	setCondition := func(reason, message string) {
+		condition := metav1.Condition{
+			ObservedGeneration: cluster.Generation,
+			Type:               v1beta1.SwitchoverOnCordon,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            message,
+		}
+		if reason != "Ready" {
+			condition.Status = metav1.ConditionFalse
+
+			previous := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.SwitchoverOnCordon)
+			if previous == nil || previous.Reason != reason || previous.Message != message {
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, reason, message)
+			}
+		}
+		meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	}
+
+	// Nodes are watched only when the feature is enabled.
+	if !feature.Enabled(ctx, feature.SwitchoverOnCordon) {
+		setCondition("FeatureDisabled",
+			"spec.patroni.switchoverOnCordon requires the SwitchoverOnCordon feature gate")
+		return nil
+	}
+
+	// Leave the primary alone while a requested switchover is in progress.
+	if cluster.Status.Patroni.SwitchoverTimeline != nil {
+		return nil
+	}
+
+	pod, _ := instances.writablePod(container)
+	if pod == nil || pod.Spec.NodeName == "" {
+		return nil
+	}
+
+	node, err := r.getNode(ctx, pod.Spec.NodeName)
+	if err != nil {
+		return err
+	}
+	if !node.Spec.Unschedulable {
+		setCondition("Ready", "The primary can move when its Node is cordoned")
+		return nil
+	}
+
+	candidate, _, err := r.switchoverCandidate(ctx, cluster, instances)
+	if err != nil {
+		return err
+	}
+	if candidate == nil {
+		setCondition("NoSwitchoverCandidate", fmt.Sprintf(
+			"Node %s of the primary is unschedulable, but there is no ready replica on another Node",
+			node.Name))
+		return nil
+	}
+	setCondition("Ready", "The primary can move when its Node is cordoned")
+
+	exec := patroni.Executor(func(
+		ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(ctx, pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+	})
+
+	next := candidate.Pods[0].Name
+	success, err := exec.SwitchoverAndWait(ctx, next)
+	if err = errors.WithStack(err); err == nil && !success {
+		err = errors.New("unable to switchover")
+	}
+	if err == nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "SwitchedOver",
+			"Moved the primary from %s on unschedulable Node %s to %s",
+			pod.Name, node.Name, next)
+	}
+	return err
+}
+
+// reconcilePatroniPreferredPrimary moves the primary back to where it is
+// preferred, such as after a failover. It switches over at most once per
+// interval and only during the maintenance window. It returns how long until
+// it should be called again.
+func (r *Reconciler) reconcilePatroniPreferredPrimary(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (time.Duration, error) {
+	const container = naming.ContainerDatabase
+
+	if cluster.Spec.Patroni == nil || cluster.Spec.Patroni.PreferredPrimary == nil {
+		cluster.Status.Patroni.PreferredPrimarySwitchoverTime = nil
+		return 0, nil
+	}
+	preference := cluster.Spec.Patroni.PreferredPrimary
+
+	// Leave the primary alone while a requested switchover is in progress.
+	if cluster.Status.Patroni.SwitchoverTimeline != nil {
+		return 0, nil
+	}
+
+	pod, _ := instances.writablePod(container)
+	if pod == nil || pod.Spec.NodeName == "" {
+		return 0, nil
+	}
+
+	node, err := r.getNode(ctx, pod.Spec.NodeName)
+	if err != nil || node.Name == "" || preference.Matches(node.Labels) {
+		return 0, err
+	}
+
+	now := time.Now()
+	if open, next := cluster.Spec.MaintenanceWindow.Open(now); !open {
+		return next, nil
+	}
+
+	interval := time.Hour
+	if preference.Interval != nil {
+		interval = preference.Interval.AsDuration().Duration
+	}
+	if last := cluster.Status.Patroni.PreferredPrimarySwitchoverTime; last != nil {
+		if wait := last.Add(interval).Sub(now); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	// Switch over only to a ready replica where the primary is preferred.
+	candidate, preferred, err := r.switchoverCandidate(ctx, cluster, instances)
+	if err != nil || candidate == nil || !preferred {
+		return 0, err
+	}
+
+	exec := patroni.Executor(func(
+		ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(ctx, pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+	})
+
+	// Record the attempt whether or not it succeeds so that failures are
+	// also limited by the interval.
+	cluster.Status.Patroni.PreferredPrimarySwitchoverTime = initialize.Pointer(metav1.NewTime(now))
+
+	next := candidate.Pods[0].Name
+	success, err := exec.SwitchoverAndWait(ctx, next)
+	if err = errors.WithStack(err); err == nil && !success {
+		err = errors.New("unable to switchover")
+	}
+	if err == nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "PreferredPrimary",
+			"Moved the primary from %s on Node %s to %s", pod.Name, node.Name, next)
+	} else {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "PreferredPrimaryFailed",
+			"Unable to move the primary from %s to %s: %v", pod.Name, next, err)
+	}
+	return interval, nil
+}
+
+// scheduledSwitchoverDelay returns how long until the switchover requested on
+// cluster is scheduled to happen. It returns zero when no switchover is
+// requested or it should happen now.
+func scheduledSwitchoverDelay(cluster *v1beta1.PostgresCluster, now time.Time) time.Duration {
+	if cluster.Spec.Patroni == nil ||
+		cluster.Spec.Patroni.Switchover == nil ||
+		!cluster.Spec.Patroni.Switchover.Enabled ||
+		cluster.Spec.Patroni.Switchover.ScheduledTime == nil {
+		return 0
+	}
+
+	annotation := cluster.GetAnnotations()[naming.PatroniSwitchover]
+	status := cluster.Status.Patroni.Switchover
+	if annotation == "" || (status != nil && *status == annotation) {
+		return 0
+	}
+
+	return max(0, cluster.Spec.Patroni.Switchover.ScheduledTime.Sub(now))
+}
+
+// +kubebuilder:rbac:groups="",resources="nodes",verbs={get}
+
+// getNode reads the Node called name directly from the Kubernetes API so that
+// Nodes are not cached or watched. It returns an empty Node when the Node does
+// not exist or the operator is not allowed to read it.
+func (r *Reconciler) getNode(ctx context.Context, name string) (*corev1.Node, error) {
+	reader := r.Reader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	node := &corev1.Node{}
+	err := reader.Get(ctx, client.ObjectKey{Name: name}, node)
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		logging.FromContext(ctx).V(1).Info("unable to read Node", "node", name, "reason", err.Error())
+		return &corev1.Node{}, nil
+	}
+	return node, errors.WithStack(err)
+}
+
+// switchoverCandidate returns the replica that should become primary when a
+// switchover has no target and whether or not it is where the primary is
+// preferred. It prefers ready replicas that match preferredPrimary with the
+// least replication lag. It returns nil when there are no ready replicas on
+// schedulable Nodes that can be promoted.
+func (r *Reconciler) switchoverCandidate(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (*Instance, bool, error) {
+	var preference *v1beta1.PatroniPreferredPrimarySpec
+	if cluster.Spec.Patroni != nil {
+		preference = cluster.Spec.Patroni.PreferredPrimary
+	}
+
+	// Index the replication lag reported in status by Pod name.
+	lag := make(map[string]*int64)
+	for _, set := range cluster.Status.InstanceSets {
+		for _, member := range set.Members {
+			lag[member.Name] = member.ReplayLagBytes
+		}
+	}
+
+	type candidate struct {
+		instance  *Instance
+		preferred bool
+		lag       *int64
+	}
+	var candidates []candidate
+
+	for _, instance := range instances.forCluster {
+		if instance.Spec == nil || instance.Spec.Tags.NoFailoverOrDelay() {
+			continue
+		}
+		if primary, known := instance.IsPrimary(); primary || !known {
+			continue
+		}
+		if terminating, known := instance.IsTerminating(); terminating || !known {
+			continue
+		}
+		if ready, known := instance.IsReady(); !ready || !known {
+			continue
+		}
+
+		pod := instance.Pods[0]
+		c := candidate{instance: instance, lag: lag[pod.Name]}
+
+		// Skip replicas on Nodes that are cordoned or draining; they are
+		// about to be evicted.
+		if pod.Spec.NodeName != "" {
+			node, err := r.getNode(ctx, pod.Spec.NodeName)
+			if err != nil {
+				return nil, false, err
+			}
+			if node.Spec.Unschedulable {
+				continue
+			}
+			c.preferred = preference.Matches(node.Labels)
+		}
+
+		candidates = append(candidates, c)
+	}
+
+	// Sort preferred candidates first, then by replication lag. Those with
+	// unknown lag go last.
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.preferred != b.preferred && a.preferred:
+			return -1
+		case a.preferred != b.preferred:
+			return 1
+		case a.lag != nil && b.lag != nil:
+			return cmp.Compare(*a.lag, *b.lag)
+		case a.lag != nil:
+			return -1
+		case b.lag != nil:
+			return 1
+		}
+		return strings.Compare(a.instance.Name, b.instance.Name)
+	})
+
+	if len(candidates) == 0 {
+		return nil, false, nil
+	}
+	return candidates[0].instance, candidates[0].preferred, nil
+}
+
+// reconcilePatroniSlotRetention lists the replication slots on the primary
+// that hold more WAL than spec.patroni.slotRetention allows. When its policy
+// is "Drop", it drops those that have no client connected. It returns how long
+// to wait before doing so again.
+func (r *Reconciler) reconcilePatroniSlotRetention(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (time.Duration, error) {
+	const container = naming.ContainerDatabase
+
+	if cluster.Spec.Patroni == nil || cluster.Spec.Patroni.SlotRetention == nil ||
+		cluster.Spec.Patroni.SlotRetention.MaxWAL == nil {
+		cluster.Status.Patroni.SlotsRetainingWAL = nil
+		cluster.Status.Patroni.SlotsObservedTime = nil
+		return 0, nil
+	}
+	retention := cluster.Spec.Patroni.SlotRetention
+
+	// Every change to status triggers another reconcile, and the WAL held by
+	// slots changes constantly.
+	now := time.Now()
+	if wait := observationDelay(cluster.Status.Patroni.SlotsObservedTime, now); wait > 0 {
+		return wait, nil
+	}
+	previous := make(map[string]bool, len(cluster.Status.Patroni.SlotsRetainingWAL))
+	for _, slot := range cluster.Status.Patroni.SlotsRetainingWAL {
+		previous[slot.Name] = true
+	}
+
+	// Find the PostgreSQL instance that holds WAL for slots. When there is
+	// none, return early.
+	pod, _ := instances.writablePod(container)
+	if pod == nil {
+		return 0, nil
+	}
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+	exec := func(
+		ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(ctx, pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+	}
+
+	// Record this attempt whether or not it succeeds so that the next one
+	// waits, even when no slot holds too much WAL.
+	observed := metav1.NewTime(now)
+	cluster.Status.Patroni.SlotsObservedTime = &observed
+
+	// Problems querying slots are logged but otherwise ignored, like those
+	// in [Reconciler.observeReplication].
+	slots, err := postgres.ReplicationSlotsInPostgreSQL(ctx, exec)
+	if err != nil {
+		logging.FromContext(ctx).Error(err, "unable to query replication slots")
+		return replicationObservationInterval, nil
+	}
+
+	limit := retention.MaxWAL.Value()
+
+	// Patroni keeps a physical slot on the primary for every other member and
+	// creates it again right away. Dropping one only breaks replication to
+	// a replica that is down or far behind, so those slots are reported only.
+	// Patroni names them after the member, which is the Pod name.
+	members := make(map[string]bool)
+	for _, instance := range instances.forCluster {
+		members[strings.ReplaceAll(instance.Name+"-0", "-", "_")] = true
+		for _, pod := range instance.Pods {
+			members[strings.ReplaceAll(pod.Name, "-", "_")] = true
+		}
+	}
+
+	var excessive []v1beta1.PatroniSlotStatus
+	var inactive []string
+	for _, slot := range slots {
+		if slot.RetainedBytes != nil && *slot.RetainedBytes > limit {
+			excessive = append(excessive, v1beta1.PatroniSlotStatus{
+				Name:          slot.Name,
+				Type:          slot.Type,
+				Active:        slot.Active,
+				RetainedBytes: slot.RetainedBytes,
+				ObservedTime:  &observed,
+			})
+			if !slot.Active && !members[slot.Name] {
+				inactive = append(inactive, slot.Name)
+			}
+		}
+	}
+
+	var dropped []string
+	if retention.Policy == v1beta1.PatroniSlotRetentionDrop && len(inactive) > 0 {
+		dropped, err = postgres.DropReplicationSlotsInPostgreSQL(ctx, exec, inactive)
+	}
+
+	cluster.Status.Patroni.SlotsRetainingWAL = nil
+	for _, slot := range excessive {
+		retained := resource.NewQuantity(*slot.RetainedBytes, resource.BinarySI)
+
+		if slices.Contains(dropped, slot.Name) {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "DroppedSlot",
+				"Dropped replication slot %q that held %v of WAL, more than %v",
+				slot.Name, retained, retention.MaxWAL)
+			continue
+		}
+		if !previous[slot.Name] {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "SlotRetainingWAL",
+				"Replication slot %q holds %v of WAL, more than %v",
+				slot.Name, retained, retention.MaxWAL)
+		}
+		cluster.Status.Patroni.SlotsRetainingWAL = append(
+			cluster.Status.Patroni.SlotsRetainingWAL, slot)
+	}
+
+	return replicationObservationInterval, err
+}
@@ -19,14 +19,19 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/feature"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/events"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -525,6 +530,859 @@ func TestReconcilePatroniStatus(t *testing.T) {
 	}
 }
 
+func TestReconcilePatroniStatusSynchronous(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.Patroni = new(v1beta1.PatroniSpec)
+
+	sync := &corev1.Endpoints{ObjectMeta: naming.PatroniSynchronousState(cluster)}
+	sync.Annotations = map[string]string{
+		"leader":       "hippo-00-abcd-0",
+		"sync_standby": "hippo-00-efgh-0, hippo-01-ijkl-0,",
+	}
+
+	r := &Reconciler{Client: fake.NewClientBuilder().WithObjects(sync).Build()}
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster.Status.Patroni.SynchronousStandbys = []string{"stale"}
+
+		_, err := r.reconcilePatroniStatus(ctx, cluster, new(observedInstances))
+		assert.NilError(t, err)
+		assert.Assert(t, cluster.Status.Patroni.SynchronousStandbys == nil)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		cluster.Spec.Patroni.Synchronous = new(v1beta1.PatroniSynchronousSpec)
+
+		_, err := r.reconcilePatroniStatus(ctx, cluster, new(observedInstances))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, cluster.Status.Patroni.SynchronousStandbys,
+			[]string{"hippo-00-efgh-0", "hippo-01-ijkl-0"})
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		r := &Reconciler{Client: fake.NewClientBuilder().Build()}
+
+		_, err := r.reconcilePatroniStatus(ctx, cluster, new(observedInstances))
+		assert.NilError(t, err)
+		assert.Assert(t, cluster.Status.Patroni.SynchronousStandbys == nil)
+	})
+}
+
+func TestValidatePatroniSynchronous(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.Patroni = new(v1beta1.PatroniSpec)
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "00", Replicas: initialize.Int32(2)},
+		{Name: "01"},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{Recorder: recorder}
+
+		r.validatePatroniSynchronous(cluster)
+		assert.Equal(t, len(recorder.Events), 0)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.SynchronousReplication) == nil)
+	})
+
+	t.Run("Enough", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{Recorder: recorder}
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.Synchronous = &v1beta1.PatroniSynchronousSpec{
+			Standbys: initialize.Int32(2),
+		}
+
+		r.validatePatroniSynchronous(cluster)
+		assert.Equal(t, len(recorder.Events), 0)
+		assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions,
+			v1beta1.SynchronousReplication))
+	})
+
+	t.Run("TooMany", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{Recorder: recorder}
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.Synchronous = &v1beta1.PatroniSynchronousSpec{
+			Standbys: initialize.Int32(3),
+		}
+
+		r.validatePatroniSynchronous(cluster)
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Type, "Warning")
+		assert.Equal(t, recorder.Events[0].Reason, "InvalidSynchronousStandbys")
+		assert.Assert(t, cmp.Contains(recorder.Events[0].Note, "3 synchronous standbys"))
+		assert.Assert(t, cmp.Contains(recorder.Events[0].Note, "2 replicas"))
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.SynchronousReplication)
+		if assert.Check(t, condition != nil) {
+			assert.Equal(t, condition.Status, metav1.ConditionFalse)
+			assert.Equal(t, condition.Reason, "TooFewReplicas")
+		}
+
+		// The event is not repeated while the condition is false.
+		r.validatePatroniSynchronous(cluster)
+		assert.Equal(t, len(recorder.Events), 1)
+
+		// The condition clears when there are enough replicas.
+		cluster.Spec.InstanceSets[1].Replicas = initialize.Int32(2)
+		r.validatePatroniSynchronous(cluster)
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions,
+			v1beta1.SynchronousReplication))
+	})
+}
+
+func TestHandlePatroniRestarts(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+
+	instances := &observedInstances{forCluster: []*Instance{{
+		Name: "hippo-00-abcd",
+		Pods: []*corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "hippo-00-abcd-0", Namespace: "ns1",
+				Annotations: map[string]string{
+					"status": `{"role":"primary","state":"running","pending_restart":true}`,
+				},
+				Labels: map[string]string{naming.LabelRole: naming.RolePatroniLeader},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  naming.ContainerDatabase,
+					State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+				}},
+			},
+		}},
+	}}}
+
+	t.Run("NoWindow", func(t *testing.T) {
+		var calls []string
+		r := &Reconciler{PodExec: func(
+			_ context.Context, _, pod, _ string, _ io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls = append(calls, pod+" "+strings.Join(command, " "))
+			return nil
+		}}
+
+		assert.NilError(t, r.handlePatroniRestarts(ctx, cluster, instances))
+		assert.Equal(t, len(calls), 1)
+		assert.Assert(t, cmp.Contains(calls[0], "hippo-00-abcd-0 "))
+		assert.Assert(t, cmp.Contains(calls[0], "restart"))
+	})
+
+	t.Run("OutsideWindow", func(t *testing.T) {
+		r := &Reconciler{PodExec: func(
+			context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			t.Fatal("expected no restarts")
+			return nil
+		}}
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.MaintenanceWindow = &v1beta1.MaintenanceWindowSpec{
+			Days: []string{time.Now().UTC().Add(48 * time.Hour).Weekday().String()[:3]},
+		}
+
+		assert.NilError(t, r.handlePatroniRestarts(ctx, cluster, instances))
+	})
+}
+
+func TestReconcilePatroniReinitialize(t *testing.T) {
+	ctx := context.Background()
+
+	pod := func(name, role, trigger string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "ns1",
+				Labels: map[string]string{naming.LabelRole: role},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  naming.ContainerDatabase,
+					State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+				}},
+			},
+		}
+		if trigger != "" {
+			pod.Annotations = map[string]string{naming.PatroniReinitialize: trigger}
+		}
+		return pod
+	}
+
+	setup := func(primaryTrigger, replicaTrigger string) (*v1beta1.PostgresCluster, *observedInstances) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Name, cluster.Namespace = "hippo", "ns1"
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{
+			Name: "00",
+			Members: []v1beta1.PostgresMemberStatus{
+				{Name: "hippo-00-abcd-0", Role: "primary", State: "running"},
+				{Name: "hippo-00-efgh-0", Role: "replica", State: "streaming"},
+			},
+		}}
+		return cluster, &observedInstances{forCluster: []*Instance{
+			{Name: "hippo-00-abcd", Pods: []*corev1.Pod{
+				pod("hippo-00-abcd-0", naming.RolePatroniLeader, primaryTrigger)}},
+			{Name: "hippo-00-efgh", Pods: []*corev1.Pod{
+				pod("hippo-00-efgh-0", naming.RolePatroniReplica, replicaTrigger)}},
+		}}
+	}
+
+	noExec := func(t *testing.T) func(
+		context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+	) error {
+		return func(
+			context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			t.Fatal("expected no exec")
+			return nil
+		}
+	}
+
+	t.Run("NoAnnotation", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{Recorder: recorder, PodExec: noExec(t)}
+		cluster, instances := setup("", "")
+
+		requeue, err := r.reconcilePatroniReinitialize(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Duration(0))
+		assert.Equal(t, len(recorder.Events), 0)
+		assert.Equal(t, len(cluster.Status.Conditions), 0)
+	})
+
+	t.Run("Primary", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{Recorder: recorder, PodExec: noExec(t)}
+		cluster, instances := setup("one", "")
+
+		_, err := r.reconcilePatroniReinitialize(ctx, cluster, instances)
+		assert.NilError(t, err)
+
+		member := cluster.Status.InstanceSets[0].Members[0]
+		assert.Equal(t, member.Reinitialize.Trigger, "one")
+		assert.Equal(t, member.Reinitialize.Outcome, "Refused")
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Type, "Warning")
+		assert.Equal(t, recorder.Events[0].Reason, "ReinitializeRefused")
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, "InstanceReinitializing")
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "Refused")
+
+		// The same trigger is not handled again.
+		recorder.Events = nil
+		_, err = r.reconcilePatroniReinitialize(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Replica", func(t *testing.T) {
+		var calls []string
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{Recorder: recorder, PodExec: func(
+			_ context.Context, _, pod, _ string, _ io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			calls = append(calls, pod+" "+strings.Join(command, " "))
+			_, _ = stdout.Write([]byte("Success: reinitialize for member " + pod))
+			return nil
+		}}
+		cluster, instances := setup("", "two")
+
+		requeue, err := r.reconcilePatroniReinitialize(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, 10*time.Second)
+		assert.DeepEqual(t, calls, []string{
+			"hippo-00-efgh-0 patronictl reinit --force hippo-ha hippo-00-efgh-0",
+		})
+
+		member := &cluster.Status.InstanceSets[0].Members[1]
+		assert.Equal(t, member.Reinitialize.Trigger, "two")
+		assert.Equal(t, member.Reinitialize.Outcome, "InProgress")
+		assert.Assert(t, member.Reinitialize.StartTime != nil)
+		assert.Assert(t, member.Reinitialize.CompletionTime == nil)
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "Reinitializing")
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, "InstanceReinitializing")
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+
+		t.Run("InProgress", func(t *testing.T) {
+			member.State = "creating replica"
+
+			requeue, err := r.reconcilePatroniReinitialize(ctx, cluster, instances)
+			assert.NilError(t, err)
+			assert.Equal(t, requeue, 10*time.Second)
+			assert.Equal(t, len(calls), 1)
+			assert.Assert(t, member.Reinitialize.CompletionTime == nil)
+		})
+
+		t.Run("Succeeded", func(t *testing.T) {
+			member.State = "streaming"
+			member.Reinitialize.StartTime = initialize.Pointer(metav1.NewTime(time.Now().Add(-time.Minute)))
+
+			requeue, err := r.reconcilePatroniReinitialize(ctx, cluster, instances)
+			assert.NilError(t, err)
+			assert.Equal(t, requeue, time.Duration(0))
+			assert.Equal(t, len(calls), 1)
+			assert.Equal(t, member.Reinitialize.Outcome, "Succeeded")
+			assert.Assert(t, member.Reinitialize.CompletionTime != nil)
+
+			condition := meta.FindStatusCondition(cluster.Status.Conditions, "InstanceReinitializing")
+			assert.Equal(t, condition.Status, metav1.ConditionFalse)
+			assert.Equal(t, condition.Reason, "Succeeded")
+		})
+	})
+
+	t.Run("NotRunning", func(t *testing.T) {
+		for _, tt := range []struct {
+			name, state string
+			started     time.Duration
+		}{
+			{name: "Crashed", state: "crashed", started: time.Minute},
+			{name: "Deadline", state: "creating replica", started: 13 * time.Hour},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				recorder := events.NewRecorder(t, runtime.Scheme)
+				r := &Reconciler{Recorder: recorder, PodExec: noExec(t)}
+				cluster, instances := setup("", "four")
+
+				member := &cluster.Status.InstanceSets[0].Members[1]
+				member.State = tt.state
+				member.Reinitialize = &v1beta1.PostgresMemberReinitializeStatus{
+					Trigger:   "four",
+					StartTime: initialize.Pointer(metav1.NewTime(time.Now().Add(-tt.started))),
+					Outcome:   "InProgress",
+				}
+				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+					Type: "InstanceReinitializing", Status: metav1.ConditionTrue, Reason: "InProgress",
+				})
+
+				requeue, err := r.reconcilePatroniReinitialize(ctx, cluster, instances)
+				assert.NilError(t, err)
+				assert.Equal(t, requeue, time.Duration(0))
+				assert.Equal(t, member.Reinitialize.Outcome, "Failed")
+				assert.Assert(t, member.Reinitialize.CompletionTime != nil)
+
+				assert.Equal(t, len(recorder.Events), 1)
+				assert.Equal(t, recorder.Events[0].Type, "Warning")
+				assert.Equal(t, recorder.Events[0].Reason, "ReinitializeFailed")
+
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, "InstanceReinitializing")
+				assert.Equal(t, condition.Status, metav1.ConditionFalse)
+				assert.Equal(t, condition.Reason, "Failed")
+			})
+		}
+	})
+
+	t.Run("MemberRemoved", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{Recorder: recorder, PodExec: noExec(t)}
+		cluster, instances := setup("", "")
+
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: "InstanceReinitializing", Status: metav1.ConditionTrue, Reason: "InProgress",
+		})
+
+		_, err := r.reconcilePatroniReinitialize(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions, "InstanceReinitializing") == nil)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{Recorder: recorder, PodExec: func(
+			_ context.Context, _, _, _ string, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = stdout.Write([]byte("Failed: reinitialize for member, status code=503"))
+			return nil
+		}}
+		cluster, instances := setup("", "three")
+
+		requeue, err := r.reconcilePatroniReinitialize(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Duration(0))
+
+		member := cluster.Status.InstanceSets[0].Members[1]
+		assert.Equal(t, member.Reinitialize.Outcome, "Failed")
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "ReinitializeFailed")
+	})
+}
+
+func TestReconcilePatroniCordonSwitchover(t *testing.T) {
+	gate := feature.NewGate()
+	assert.NilError(t, gate.SetFromMap(map[string]bool{
+		feature.SwitchoverOnCordon: true,
+	}))
+	ctx := feature.NewContext(context.Background(), gate)
+
+	node := func(name string, unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		}
+	}
+	instance := func(name, node, role, status string) *Instance {
+		return &Instance{
+			Name: name,
+			Spec: &v1beta1.PostgresInstanceSetSpec{Name: "00"},
+			Pods: []*corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name + "-0", Namespace: "ns1",
+					Annotations: map[string]string{"status": status},
+					Labels:      map[string]string{naming.LabelRole: role},
+				},
+				Spec: corev1.PodSpec{NodeName: node},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{{
+						Type: corev1.PodReady, Status: corev1.ConditionTrue,
+					}},
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:  naming.ContainerDatabase,
+						State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+					}},
+				},
+			}},
+		}
+	}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		instance("hippo-00-abcd", "n1", naming.RolePatroniLeader, `{"role":"master","state":"running"}`),
+		instance("hippo-00-efgh", "n2", naming.RolePatroniReplica, `{"role":"replica","state":"streaming"}`),
+	}}
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Name, cluster.Namespace = "hippo", "ns1"
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{SwitchoverOnCordon: initialize.Bool(true)}
+
+	t.Run("Disabled", func(t *testing.T) {
+		r := &Reconciler{
+			Client: fake.NewClientBuilder().WithObjects(node("n1", true), node("n2", false)).Build(),
+			PodExec: func(
+				context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+			) error {
+				t.Fatal("expected no exec")
+				return nil
+			},
+		}
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.SwitchoverOnCordon = nil
+		cluster.Status.Conditions = []metav1.Condition{{Type: v1beta1.SwitchoverOnCordon}}
+		assert.NilError(t, r.reconcilePatroniCordonSwitchover(ctx, cluster, instances))
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.SwitchoverOnCordon) == nil)
+	})
+
+	t.Run("FeatureDisabled", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{
+			Client:   fake.NewClientBuilder().WithObjects(node("n1", true), node("n2", false)).Build(),
+			Recorder: recorder,
+			PodExec: func(
+				context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+			) error {
+				t.Fatal("expected no exec")
+				return nil
+			},
+		}
+
+		ctx := feature.NewContext(context.Background(), feature.NewGate())
+		cluster := cluster.DeepCopy()
+		assert.NilError(t, r.reconcilePatroniCordonSwitchover(ctx, cluster, instances))
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "FeatureDisabled")
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.SwitchoverOnCordon)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "FeatureDisabled")
+
+		// The event is not repeated.
+		assert.NilError(t, r.reconcilePatroniCordonSwitchover(ctx, cluster, instances))
+		assert.Equal(t, len(recorder.Events), 1)
+	})
+
+	t.Run("DirectRead", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{
+			// Nodes are read through Reader rather than the cached Client.
+			Client:   fake.NewClientBuilder().Build(),
+			Reader:   fake.NewClientBuilder().WithObjects(node("n1", true), node("n2", true)).Build(),
+			Recorder: recorder,
+			PodExec: func(
+				context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+			) error {
+				t.Fatal("expected no exec")
+				return nil
+			},
+		}
+
+		cluster := cluster.DeepCopy()
+		assert.NilError(t, r.reconcilePatroniCordonSwitchover(ctx, cluster, instances))
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "NoSwitchoverCandidate")
+	})
+
+	t.Run("Schedulable", func(t *testing.T) {
+		r := &Reconciler{
+			Client: fake.NewClientBuilder().WithObjects(node("n1", false), node("n2", false)).Build(),
+			PodExec: func(
+				context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+			) error {
+				t.Fatal("expected no exec")
+				return nil
+			},
+		}
+
+		cluster := cluster.DeepCopy()
+		assert.NilError(t, r.reconcilePatroniCordonSwitchover(ctx, cluster, instances))
+		assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.SwitchoverOnCordon))
+	})
+
+	t.Run("Cordoned", func(t *testing.T) {
+		var calls []string
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{
+			Client:   fake.NewClientBuilder().WithObjects(node("n1", true), node("n2", false)).Build(),
+			Recorder: recorder,
+			PodExec: func(
+				_ context.Context, _, pod, _ string, _ io.Reader, stdout, _ io.Writer, command ...string,
+			) error {
+				calls = append(calls, pod+" "+strings.Join(command, " "))
+				_, _ = stdout.Write([]byte("switched over"))
+				return nil
+			},
+		}
+
+		cluster := cluster.DeepCopy()
+		assert.NilError(t, r.reconcilePatroniCordonSwitchover(ctx, cluster, instances))
+		assert.DeepEqual(t, calls, []string{
+			"hippo-00-abcd-0 patronictl switchover --scheduled=now --force --candidate=hippo-00-efgh-0",
+		})
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "SwitchedOver")
+	})
+
+	t.Run("NoCandidate", func(t *testing.T) {
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{
+			Client:   fake.NewClientBuilder().WithObjects(node("n1", true), node("n2", true)).Build(),
+			Recorder: recorder,
+			PodExec: func(
+				context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+			) error {
+				t.Fatal("expected no exec")
+				return nil
+			},
+		}
+
+		cluster := cluster.DeepCopy()
+		assert.NilError(t, r.reconcilePatroniCordonSwitchover(ctx, cluster, instances))
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Type, "Warning")
+		assert.Equal(t, recorder.Events[0].Reason, "NoSwitchoverCandidate")
+		assert.Assert(t, meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.SwitchoverOnCordon))
+
+		// The event is not repeated while the Node stays cordoned.
+		assert.NilError(t, r.reconcilePatroniCordonSwitchover(ctx, cluster, instances))
+		assert.Equal(t, len(recorder.Events), 1)
+	})
+}
+
+func TestScheduledSwitchoverDelay(t *testing.T) {
+	now := time.Date(2024, time.January, 2, 14, 30, 0, 0, time.UTC)
+
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Equal(t, scheduledSwitchoverDelay(cluster, now), time.Duration(0))
+
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		Switchover: &v1beta1.PatroniSwitchover{
+			Enabled:       true,
+			ScheduledTime: initialize.Pointer(metav1.NewTime(now.Add(90 * time.Minute))),
+		},
+	}
+	assert.Equal(t, scheduledSwitchoverDelay(cluster, now), time.Duration(0),
+		"expected no delay without a trigger")
+
+	cluster.Annotations = map[string]string{naming.PatroniSwitchover: "trigger"}
+	assert.Equal(t, scheduledSwitchoverDelay(cluster, now), 90*time.Minute)
+	assert.Equal(t, scheduledSwitchoverDelay(cluster, now.Add(2*time.Hour)), time.Duration(0),
+		"expected no delay after the scheduled time")
+
+	cluster.Status.Patroni.Switchover = initialize.String("trigger")
+	assert.Equal(t, scheduledSwitchoverDelay(cluster, now), time.Duration(0),
+		"expected no delay after the switchover")
+}
+
+func TestSwitchoverCandidate(t *testing.T) {
+	ctx := context.Background()
+
+	node := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone},
+		}}
+	}
+	instance := func(name, node, role string, ready bool, spec *v1beta1.PostgresInstanceSetSpec) *Instance {
+		condition := corev1.ConditionFalse
+		if ready {
+			condition = corev1.ConditionTrue
+		}
+		return &Instance{
+			Name: name,
+			Spec: spec,
+			Pods: []*corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name + "-0",
+					Labels: map[string]string{naming.LabelRole: role},
+				},
+				Spec: corev1.PodSpec{NodeName: node},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: condition}},
+				},
+			}},
+		}
+	}
+
+	spec := &v1beta1.PostgresInstanceSetSpec{Name: "00"}
+	delayed := &v1beta1.PostgresInstanceSetSpec{Name: "01", Tags: &v1beta1.PatroniTagsSpec{
+		NoFailover: initialize.Bool(true),
+	}}
+
+	cordoned := node("n4", "west")
+	cordoned.Spec.Unschedulable = true
+
+	r := &Reconciler{Client: fake.NewClientBuilder().WithObjects(
+		node("n1", "east"), node("n2", "west"), node("n3", "west"), cordoned,
+	).Build()}
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{
+		Name: "00",
+		Members: []v1beta1.PostgresMemberStatus{
+			{Name: "a-0", ReplayLagBytes: initialize.Int64(0)},
+			{Name: "b-0", ReplayLagBytes: initialize.Int64(500)},
+			{Name: "c-0", ReplayLagBytes: initialize.Int64(100)},
+			{Name: "e-0", ReplayLagBytes: initialize.Int64(0)},
+			{Name: "f-0", ReplayLagBytes: initialize.Int64(0)},
+		},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		instance("a", "n1", naming.RolePatroniLeader, true, spec),
+		instance("b", "n2", naming.RolePatroniReplica, true, spec),
+		instance("c", "n1", naming.RolePatroniReplica, true, spec),
+		instance("d", "n3", naming.RolePatroniReplica, false, spec),
+		instance("e", "n3", naming.RolePatroniReplica, true, delayed),
+		instance("f", "n4", naming.RolePatroniReplica, true, spec),
+	}}
+
+	t.Run("LowestLag", func(t *testing.T) {
+		candidate, preferred, err := r.switchoverCandidate(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, candidate.Name, "c")
+		assert.Assert(t, !preferred)
+	})
+
+	t.Run("PreferredZone", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			PreferredPrimary: &v1beta1.PatroniPreferredPrimarySpec{Zone: "west"},
+		}
+
+		candidate, preferred, err := r.switchoverCandidate(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, candidate.Name, "b")
+		assert.Assert(t, preferred)
+	})
+
+	t.Run("PreferredNodes", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			PreferredPrimary: &v1beta1.PatroniPreferredPrimarySpec{
+				NodeSelector: map[string]string{corev1.LabelTopologyZone: "mars"},
+			},
+		}
+
+		candidate, preferred, err := r.switchoverCandidate(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, candidate.Name, "c")
+		assert.Assert(t, !preferred)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		instances := &observedInstances{forCluster: instances.forCluster[:1]}
+
+		candidate, _, err := r.switchoverCandidate(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, candidate == nil)
+	})
+}
+
+func TestReconcilePatroniPreferredPrimary(t *testing.T) {
+	ctx := context.Background()
+
+	node := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone},
+		}}
+	}
+	instance := func(name, node, role, status string) *Instance {
+		return &Instance{
+			Name: name,
+			Spec: &v1beta1.PostgresInstanceSetSpec{Name: "00"},
+			Pods: []*corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name + "-0", Namespace: "ns1",
+					Annotations: map[string]string{"status": status},
+					Labels:      map[string]string{naming.LabelRole: role},
+				},
+				Spec: corev1.PodSpec{NodeName: node},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{{
+						Type: corev1.PodReady, Status: corev1.ConditionTrue,
+					}},
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:  naming.ContainerDatabase,
+						State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+					}},
+				},
+			}},
+		}
+	}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		instance("hippo-00-abcd", "n1", naming.RolePatroniLeader, `{"role":"master","state":"running"}`),
+		instance("hippo-00-efgh", "n2", naming.RolePatroniReplica, `{"role":"replica","state":"streaming"}`),
+	}}
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Name, cluster.Namespace = "hippo", "ns1"
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		PreferredPrimary: &v1beta1.PatroniPreferredPrimarySpec{Zone: "west"},
+	}
+
+	reconciler := func(t *testing.T, calls *[]string) *Reconciler {
+		return &Reconciler{
+			Client:   fake.NewClientBuilder().WithObjects(node("n1", "east"), node("n2", "west")).Build(),
+			Recorder: events.NewRecorder(t, runtime.Scheme),
+			PodExec: func(
+				_ context.Context, _, pod, _ string, _ io.Reader, stdout, _ io.Writer, command ...string,
+			) error {
+				*calls = append(*calls, pod+" "+strings.Join(command, " "))
+				_, _ = stdout.Write([]byte("switched over"))
+				return nil
+			},
+		}
+	}
+
+	t.Run("NoPreference", func(t *testing.T) {
+		var calls []string
+		r := reconciler(t, &calls)
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.PreferredPrimary = nil
+		cluster.Status.Patroni.PreferredPrimarySwitchoverTime = initialize.Pointer(metav1.Now())
+
+		requeue, err := r.reconcilePatroniPreferredPrimary(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Duration(0))
+		assert.Equal(t, len(calls), 0)
+		assert.Assert(t, cluster.Status.Patroni.PreferredPrimarySwitchoverTime == nil)
+	})
+
+	t.Run("AlreadyPreferred", func(t *testing.T) {
+		var calls []string
+		r := reconciler(t, &calls)
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.PreferredPrimary.Zone = "east"
+
+		requeue, err := r.reconcilePatroniPreferredPrimary(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Duration(0))
+		assert.Equal(t, len(calls), 0)
+	})
+
+	t.Run("SwitchBack", func(t *testing.T) {
+		var calls []string
+		r := reconciler(t, &calls)
+		cluster := cluster.DeepCopy()
+
+		requeue, err := r.reconcilePatroniPreferredPrimary(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Hour)
+		assert.DeepEqual(t, calls, []string{
+			"hippo-00-abcd-0 patronictl switchover --scheduled=now --force --candidate=hippo-00-efgh-0",
+		})
+		assert.Assert(t, cluster.Status.Patroni.PreferredPrimarySwitchoverTime != nil)
+
+		// Another attempt waits for the interval.
+		requeue, err = r.reconcilePatroniPreferredPrimary(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, requeue > 59*time.Minute && requeue <= time.Hour, "got %v", requeue)
+		assert.Equal(t, len(calls), 1)
+	})
+
+	t.Run("OutsideWindow", func(t *testing.T) {
+		var calls []string
+		r := reconciler(t, &calls)
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.MaintenanceWindow = &v1beta1.MaintenanceWindowSpec{
+			Days: []string{time.Now().UTC().Add(48 * time.Hour).Weekday().String()[:3]},
+		}
+
+		requeue, err := r.reconcilePatroniPreferredPrimary(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, requeue > 0)
+		assert.Equal(t, len(calls), 0)
+	})
+
+	t.Run("NoPreferredReplica", func(t *testing.T) {
+		var calls []string
+		r := reconciler(t, &calls)
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.PreferredPrimary.Zone = "north"
+
+		requeue, err := r.reconcilePatroniPreferredPrimary(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Duration(0))
+		assert.Equal(t, len(calls), 0)
+	})
+
+	t.Run("PrimaryTerminating", func(t *testing.T) {
+		var calls []string
+		r := reconciler(t, &calls)
+
+		instances := &observedInstances{forCluster: []*Instance{
+			instance("hippo-00-abcd", "n1", naming.RolePatroniLeader, `{"role":"master","state":"running"}`),
+			instances.forCluster[1],
+		}}
+		now := metav1.Now()
+		instances.forCluster[0].Pods[0].DeletionTimestamp = &now
+
+		requeue, err := r.reconcilePatroniPreferredPrimary(ctx, cluster.DeepCopy(), instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Duration(0))
+		assert.Equal(t, len(calls), 0)
+	})
+}
+
 func TestReconcilePatroniSwitchover(t *testing.T) {
 	_, client := setupKubernetes(t)
 	require.ParallelCapacity(t, 0)
@@ -902,6 +1760,9 @@ func TestReconcilePatroniSwitchover(t *testing.T) {
 		assert.Assert(t, called)
 		assert.Assert(t, cluster.Status.Patroni.Switchover == nil)
 		assert.Equal(t, *cluster.Status.Patroni.SwitchoverTimeline, int64(4))
+		assert.Equal(t, cluster.Status.Patroni.LastSwitchover.Trigger, "trigger")
+		assert.Equal(t, cluster.Status.Patroni.LastSwitchover.Outcome, "Failed")
+		assert.Equal(t, cluster.Status.Patroni.LastSwitchover.Message, "unable to switchover")
 	})
 
 	t.Run("switchover call errors", func(t *testing.T) {
@@ -950,6 +1811,33 @@ func TestReconcilePatroniSwitchover(t *testing.T) {
 		assert.Assert(t, called)
 		assert.Equal(t, *cluster.Status.Patroni.Switchover, "trigger")
 		assert.Assert(t, cluster.Status.Patroni.SwitchoverTimeline == nil)
+
+		last := cluster.Status.Patroni.LastSwitchover
+		assert.Assert(t, last != nil)
+		assert.Equal(t, last.Trigger, "trigger")
+		assert.Equal(t, last.Outcome, "Succeeded")
+		assert.Assert(t, last.StartTime != nil)
+		assert.Assert(t, last.CompletionTime != nil)
+	})
+
+	t.Run("scheduled switchover waits", func(t *testing.T) {
+		cluster := testCluster()
+		cluster.Annotations = map[string]string{
+			naming.PatroniSwitchover: "trigger",
+		}
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			Switchover: &v1beta1.PatroniSwitchover{
+				Enabled:       true,
+				ScheduledTime: initialize.Pointer(metav1.NewTime(time.Now().Add(time.Hour))),
+			},
+		}
+		cluster.Status.Patroni.SwitchoverTimeline = initialize.Int64(4)
+		called = false
+		assert.NilError(t, r.reconcilePatroniSwitchover(ctx, cluster, getObserved()))
+		assert.Assert(t, !called)
+		assert.Assert(t, cluster.Status.Patroni.Switchover == nil)
+		assert.Assert(t, cluster.Status.Patroni.SwitchoverTimeline == nil)
+		assert.Equal(t, cluster.Status.Patroni.LastSwitchover.Outcome, "Scheduled")
 	})
 
 	t.Run("targeted switchover called", func(t *testing.T) {
@@ -1003,3 +1891,185 @@ func TestReconcilePatroniSwitchover(t *testing.T) {
 		assert.Assert(t, cluster.Status.Patroni.SwitchoverTimeline == nil)
 	})
 }
+
+func TestReconcilePatroniSlotRetention(t *testing.T) {
+	ctx := context.Background()
+
+	primary := &corev1.Pod{}
+	primary.Namespace, primary.Name = "ns1", "hippo-00-abcd-0"
+	primary.Annotations = map[string]string{"status": `{"role":"primary"}`}
+	primary.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{primary}},
+	}}
+
+	newCluster := func(policy string) *v1beta1.PostgresCluster {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Namespace, cluster.Name = "ns1", "hippo"
+		require.UnmarshalInto(t, &cluster.Spec, `{
+			patroni: { slotRetention: { maxWAL: 1Ki, policy: `+policy+` } },
+		}`)
+		return cluster
+	}
+
+	slots := `
+{"name":"active","type":"logical","active":true,"retained_bytes":4096}
+{"name":"idle","type":"physical","active":false,"retained_bytes":2048}
+{"name":"small","type":"logical","active":false,"retained_bytes":10}
+`
+
+	t.Run("Unset", func(t *testing.T) {
+		r := &Reconciler{PodExec: func(
+			context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			t.Fatal("expected no exec")
+			return nil
+		}}
+
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Status.Patroni.SlotsRetainingWAL = []v1beta1.PatroniSlotStatus{{Name: "old"}}
+		cluster.Status.Patroni.SlotsObservedTime = initialize.Pointer(metav1.Now())
+
+		requeue, err := r.reconcilePatroniSlotRetention(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Duration(0))
+		assert.Assert(t, cluster.Status.Patroni.SlotsRetainingWAL == nil)
+		assert.Assert(t, cluster.Status.Patroni.SlotsObservedTime == nil)
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		var scripts []string
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{
+			Recorder: recorder,
+			PodExec: func(
+				_ context.Context, _, _, _ string, stdin io.Reader, stdout, _ io.Writer, _ ...string,
+			) error {
+				b, err := io.ReadAll(stdin)
+				scripts = append(scripts, string(b))
+				_, _ = io.WriteString(stdout, slots)
+				return err
+			},
+		}
+
+		cluster := newCluster("Report")
+		requeue, err := r.reconcilePatroniSlotRetention(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, replicationObservationInterval)
+		assert.Equal(t, len(scripts), 1, "expected only a query")
+
+		status := cluster.Status.Patroni.SlotsRetainingWAL
+		assert.Equal(t, len(status), 2)
+		assert.Equal(t, status[0].Name, "active")
+		assert.Assert(t, status[0].Active)
+		assert.DeepEqual(t, status[0].RetainedBytes, initialize.Int64(4096))
+		assert.Equal(t, status[1].Name, "idle")
+		assert.Equal(t, status[1].Type, "physical")
+		assert.Assert(t, status[1].ObservedTime != nil)
+
+		assert.Equal(t, len(recorder.Events), 2)
+		assert.Equal(t, recorder.Events[0].Reason, "SlotRetainingWAL")
+		assert.Equal(t, recorder.Events[0].Note,
+			`Replication slot "active" holds 4Ki of WAL, more than 1Ki`)
+
+		t.Run("Recent", func(t *testing.T) {
+			scripts = nil
+			requeue, err := r.reconcilePatroniSlotRetention(ctx, cluster, instances)
+			assert.NilError(t, err)
+			assert.Assert(t, requeue > 0 && requeue <= replicationObservationInterval)
+			assert.Equal(t, len(scripts), 0, "expected no query")
+		})
+
+		t.Run("Again", func(t *testing.T) {
+			recorder.Events = nil
+			cluster.Status.Patroni.SlotsObservedTime =
+				initialize.Pointer(metav1.NewTime(time.Now().Add(-time.Hour)))
+
+			_, err := r.reconcilePatroniSlotRetention(ctx, cluster, instances)
+			assert.NilError(t, err)
+			assert.Equal(t, len(cluster.Status.Patroni.SlotsRetainingWAL), 2)
+			assert.Equal(t, len(recorder.Events), 0, "expected no repeated events")
+		})
+	})
+
+	t.Run("Healthy", func(t *testing.T) {
+		calls := 0
+		r := &Reconciler{
+			Recorder: events.NewRecorder(t, runtime.Scheme),
+			PodExec: func(
+				context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+			) error {
+				calls++
+				return nil
+			},
+		}
+
+		cluster := newCluster("Healthy")
+		requeue, err := r.reconcilePatroniSlotRetention(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, replicationObservationInterval)
+		assert.Assert(t, cluster.Status.Patroni.SlotsRetainingWAL == nil)
+		assert.Assert(t, cluster.Status.Patroni.SlotsObservedTime != nil)
+
+		// Another call soon after does nothing, though no slot was reported.
+		requeue, err = r.reconcilePatroniSlotRetention(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, requeue > 0 && requeue <= replicationObservationInterval)
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		var scripts []string
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{
+			Recorder: recorder,
+			PodExec: func(
+				_ context.Context, _, _, _ string, stdin io.Reader, stdout, _ io.Writer, command ...string,
+			) error {
+				b, err := io.ReadAll(stdin)
+				scripts = append(scripts, string(b))
+
+				if strings.Contains(string(b), `pg_drop_replication_slot`) {
+					assert.Assert(t, cmp.Contains(command, `--set=slots=["idle"]`))
+					_, _ = io.WriteString(stdout, "idle|\n")
+				} else {
+					_, _ = io.WriteString(stdout, slots)
+					_, _ = io.WriteString(stdout, `{"name":"hippo_00_wxyz_0","type":"physical","active":false,"retained_bytes":8192}`+"\n")
+				}
+				return err
+			},
+		}
+
+		replica := &corev1.Pod{}
+		replica.Namespace, replica.Name = "ns1", "hippo-00-wxyz-0"
+		instances := &observedInstances{forCluster: []*Instance{
+			instances.forCluster[0],
+			{Name: "hippo-00-wxyz", Pods: []*corev1.Pod{replica}},
+		}}
+
+		cluster := newCluster("Drop")
+		_, err := r.reconcilePatroniSlotRetention(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, len(scripts), 2, "expected a query and a drop")
+
+		// The active slot and the slot of a Patroni member remain.
+		status := cluster.Status.Patroni.SlotsRetainingWAL
+		assert.Equal(t, len(status), 2)
+		assert.Equal(t, status[0].Name, "active")
+		assert.Equal(t, status[1].Name, "hippo_00_wxyz_0")
+
+		assert.Equal(t, len(recorder.Events), 3)
+		assert.Equal(t, recorder.Events[0].Reason, "SlotRetainingWAL")
+		assert.Equal(t, recorder.Events[1].Type, "Warning")
+		assert.Equal(t, recorder.Events[1].Reason, "DroppedSlot")
+		assert.Equal(t, recorder.Events[1].Note,
+			`Dropped replication slot "idle" that held 2Ki of WAL, more than 1Ki`)
+		assert.Equal(t, recorder.Events[2].Reason, "SlotRetainingWAL")
+		assert.Equal(t, recorder.Events[2].Note,
+			`Replication slot "hippo_00_wxyz_0" holds 8Ki of WAL, more than 1Ki`)
+	})
+}
@@ -8,17 +8,23 @@ import (
 	"bytes"
 	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/url"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -112,6 +118,13 @@ func (r *Reconciler) generatePostgresHBAs(
 	// Append any rules specified in the Patroni section.
 	result.AppendUnstructured(patroni.PostgresHBAs(cluster.Spec.Patroni)...)
 
+	// Use SCRAM in place of every password method, structured or not, once
+	// MD5 passwords are rejected.
+	// - https://www.postgresql.org/docs/current/auth-password.html
+	if postgresRejectsMD5(cluster) {
+		result.ReplaceMethod("scram-sha-256", "md5", "password")
+	}
+
 	// When there are no specified rules, include the recommended defaults.
 	if result.Length() == mandatory {
 		result.Append(builtin.Default...)
@@ -120,6 +133,14 @@ func (r *Reconciler) generatePostgresHBAs(
 	return result
 }
 
+// postgresRejectsMD5 returns true when cluster should refuse MD5 password
+// authentication. That happens only after no roles have MD5 passwords.
+func postgresRejectsMD5(cluster *v1beta1.PostgresCluster) bool {
+	authn := cluster.Spec.Authentication
+	return authn != nil && authn.RejectMD5 != nil && *authn.RejectMD5 &&
+		meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.MD5Passwords)
+}
+
 // generatePostgresParameters produces the parameter set for cluster that
 // incorporates, from highest to lowest precedence:
 //  1. mandatory values determined by controllers
@@ -131,10 +152,18 @@ func (*Reconciler) generatePostgresParameters(
 ) *postgres.ParameterSet {
 	builtin := postgres.NewParameters()
 	pgaudit.PostgreSQLParameters(&builtin)
+	postgres.ExtensionParameters(cluster, &builtin)
+	patroni.SlotParameters(cluster, &builtin)
 	pgbackrest.PostgreSQL(cluster, &builtin, backupsSpecFound)
 	pgmonitor.PostgreSQLParameters(ctx, cluster, &builtin)
 	postgres.SetHugePages(cluster, &builtin)
 
+	// Hash new passwords using SCRAM regardless of other settings.
+	// - https://www.postgresql.org/docs/current/runtime-config-connection.html#GUC-PASSWORD-ENCRYPTION
+	if postgresRejectsMD5(cluster) {
+		builtin.Mandatory.Add("password_encryption", "scram-sha-256")
+	}
+
 	// Last write wins, so start with the recommended defaults.
 	result := cmp.Or(builtin.Default.DeepCopy(), postgres.NewParameterSet())
 
@@ -205,12 +234,20 @@ func (r *Reconciler) generatePostgresUserSecret(
 
 	// Use the existing password and verifier.
 	if existing != nil {
-		intent.Data["password"] = existing.Data["password"]
-		intent.Data["verifier"] = existing.Data["verifier"]
+		if password := existing.Data["password"]; len(password) > 0 {
+			intent.Data["password"] = password
+		}
+		if verifier := existing.Data["verifier"]; len(verifier) > 0 {
+			intent.Data["verifier"] = verifier
+		}
 	}
 
+	// When the password is in another Secret, existing holds its verifier and
+	// the plaintext is never stored here; see [Reconciler.providedPostgresUserPassword].
+	provided := spec.Password != nil && spec.Password.SecretKeyRef != nil
+
 	// When password is unset, generate a new one according to the specified policy.
-	if len(intent.Data["password"]) == 0 {
+	if len(intent.Data["password"]) == 0 && !provided {
 		// NOTE: The tests around ASCII passwords are lacking. When changing
 		// this, make sure that ASCII is the default.
 		generate := util.GenerateASCIIPassword
@@ -226,14 +263,13 @@ func (r *Reconciler) generatePostgresUserSecret(
 			return nil, errors.WithStack(err)
 		}
 		intent.Data["password"] = []byte(password)
-		intent.Data["verifier"] = nil
+		delete(intent.Data, "verifier")
 	}
 
-	// When a password has been generated or the verifier is empty,
-	// generate a verifier based on the current password.
-	// NOTE(cbandy): We don't have a function to compare a plaintext
-	// password to a SCRAM verifier.
-	if len(intent.Data["verifier"]) == 0 {
+	// When a password has been generated or the verifier is empty or not
+	// SCRAM, generate a verifier based on the current password. Secrets
+	// written long ago may contain MD5 verifiers.
+	if !pgpassword.IsSCRAMVerifier(string(intent.Data["verifier"])) && !provided {
 		verifier, err := pgpassword.NewSCRAMPassword(string(intent.Data["password"])).Build()
 		if err != nil {
 			return nil, errors.WithStack(err)
@@ -242,11 +278,15 @@ func (r *Reconciler) generatePostgresUserSecret(
 	}
 
 	// When a database has been specified, include it and a connection URI.
+	// Connection URIs contain the password, so they are omitted when the
+	// password is in another Secret.
 	// - https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING
 	if len(spec.Databases) > 0 {
+		intent.Data["dbname"] = []byte(spec.Databases[0])
+	}
+	if len(spec.Databases) > 0 && !provided {
 		database := spec.Databases[0]
 
-		intent.Data["dbname"] = []byte(database)
 		intent.Data["uri"] = []byte((&url.URL{
 			Scheme: "postgresql",
 			User:   url.UserPassword(username, string(intent.Data["password"])),
@@ -276,7 +316,7 @@ func (r *Reconciler) generatePostgresUserSecret(
 		intent.Data["pgbouncer-host"] = []byte(hostname)
 		intent.Data["pgbouncer-port"] = []byte(port)
 
-		if len(spec.Databases) > 0 {
+		if len(spec.Databases) > 0 && !provided {
 			database := spec.Databases[0]
 
 			intent.Data["pgbouncer-uri"] = []byte((&url.URL{
@@ -364,7 +404,48 @@ func (r *Reconciler) reconcilePostgresDatabases(
 		}
 	}
 
-	var pgAuditOK, postgisInstallOK bool
+	// Gather the extensions that should exist in PostgreSQL.
+
+	writeExtensions := len(cluster.Spec.Extensions) > 0
+	for _, database := range cluster.Spec.Databases {
+		writeExtensions = writeExtensions || len(database.Extensions) > 0
+	}
+
+	// Gather the databases that should not accept connections. These stay
+	// stopped while the cluster is redirected to another cluster. The limits
+	// of the others are restored before the specification is applied.
+
+	stop := sets.New[string]()
+	if value := cluster.GetAnnotations()[naming.PostgresStopConnections]; value != "" {
+		var names []string
+		if err := json.Unmarshal([]byte(value), &names); err != nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidAnnotation",
+				"Unable to parse annotation %q: %v", naming.PostgresStopConnections, err)
+		}
+		stop.Insert(names...)
+	}
+	if cluster.GetAnnotations()[naming.PostgresPrimaryRedirect] != "" {
+		stop.Insert(slices.Collect(maps.Keys(cluster.Status.StoppedConnectionLimits))...)
+	}
+
+	restore := make(map[string]int32)
+	for database, limit := range cluster.Status.StoppedConnectionLimits {
+		if !stop.Has(database) {
+			restore[database] = limit
+		}
+	}
+
+	specs := make([]v1beta1.PostgresDatabaseSpec, len(cluster.Spec.Databases))
+	for i := range cluster.Spec.Databases {
+		cluster.Spec.Databases[i].DeepCopyInto(&specs[i])
+		if stop.Has(specs[i].Name) {
+			specs[i].ConnectionLimit = nil
+		}
+	}
+
+	var extensions []v1beta1.PostgresExtensionStatus
+	var pgAuditOK, postgisInstallOK, restored bool
+	var stopped map[string]int32
 	create := func(ctx context.Context, exec postgres.Executor) error {
 		if pgAuditOK = pgaudit.EnableInPostgreSQL(ctx, exec) == nil; !pgAuditOK {
 			// pgAudit can only be enabled after its shared library is loaded,
@@ -387,11 +468,42 @@ func (r *Reconciler) reconcilePostgresDatabases(
 				"Unable to install PostGIS")
 		}
 
-		return postgres.CreateDatabasesInPostgreSQL(ctx, exec, sets.List(databases))
+		var err error
+		if restored = len(restore) > 0; restored {
+			err = postgres.RestoreConnectionsInPostgreSQL(ctx, exec, restore)
+			restored = err == nil
+		}
+		if err == nil && stop.Len() > 0 {
+			stopped, err = postgres.StopConnectionsInPostgreSQL(ctx, exec, sets.List(stop))
+		}
+
+		// Create any specified databases with their options before those
+		// gathered from users so that the options are applied.
+		if err == nil && len(specs) > 0 {
+			err = postgres.WriteDatabasesInPostgreSQL(ctx, exec, specs)
+		}
+		if err == nil {
+			err = postgres.CreateDatabasesInPostgreSQL(ctx, exec, sets.List(databases))
+		}
+		if err == nil && writeExtensions {
+			extensions, err = postgres.WriteExtensionsInPostgreSQL(ctx, exec,
+				cluster.Spec.Extensions, cluster.Spec.Databases)
+		}
+		return err
 	}
 
 	// Calculate a hash of the SQL that should be executed in PostgreSQL.
 	revision, err := safeHash32(func(hasher io.Writer) error {
+		// Extensions are updated when the image changes, so include the image
+		// of the instance that executes the SQL.
+		if writeExtensions {
+			for _, c := range pod.Spec.Containers {
+				if c.Name == container {
+					_, _ = fmt.Fprint(hasher, c.Image)
+				}
+			}
+		}
+
 		// Discard log messages about executing SQL.
 		return create(logging.NewContext(ctx, logging.Discard()), func(
 			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
@@ -415,27 +527,53 @@ func (r *Reconciler) reconcilePostgresDatabases(
 	// Apply the necessary SQL and record its hash in cluster.Status. Include
 	// the hash in any log messages.
 
+	restored, stopped = false, nil
 	if err == nil {
 		log := logging.FromContext(ctx).WithValues("revision", revision)
 		err = errors.WithStack(create(logging.NewContext(ctx, log), podExecutor))
 	}
+
+	// Remember the previous connection limits even when something else failed.
+	if restored {
+		for database := range restore {
+			delete(cluster.Status.StoppedConnectionLimits, database)
+		}
+	}
+	for database, limit := range stopped {
+		if _, ok := cluster.Status.StoppedConnectionLimits[database]; !ok {
+			if cluster.Status.StoppedConnectionLimits == nil {
+				cluster.Status.StoppedConnectionLimits = make(map[string]int32)
+			}
+			cluster.Status.StoppedConnectionLimits[database] = limit
+		}
+	}
+
 	if err == nil && pgAuditOK && postgisInstallOK {
 		cluster.Status.DatabaseRevision = revision
+		cluster.Status.Extensions = extensions
 	}
 
 	return err
 }
 
 // reconcilePostgresUsers writes the objects necessary to manage users and their
-// passwords in PostgreSQL.
+// passwords in PostgreSQL. It returns how long until a password is next due to
+// be rotated, if any.
 func (r *Reconciler) reconcilePostgresUsers(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
-) error {
+) (time.Duration, error) {
 	r.validatePostgresUsers(cluster)
 
+	var retry time.Duration
 	users, secrets, err := r.reconcilePostgresUserSecrets(ctx, cluster)
 	if err == nil {
-		err = r.reconcilePostgresUsersInPostgreSQL(ctx, cluster, instances, users, secrets)
+		retry, err = r.reconcilePostgresUsersInPostgreSQL(ctx, cluster, instances, users, secrets)
+	}
+	if err == nil {
+		err = r.reconcilePostgresMD5Passwords(ctx, cluster, instances, secrets)
+	}
+	if err == nil {
+		err = r.reconcilePostgresUserDeletion(ctx, cluster, instances, users)
 	}
 	if err == nil {
 		// Copy PostgreSQL users and passwords into pgAdmin. This is here because
@@ -444,7 +582,7 @@ func (r *Reconciler) reconcilePostgresUsers(
 		// are available here, too.
 		err = r.reconcilePGAdminUsers(ctx, cluster, users, secrets)
 	}
-	return err
+	return shorter(retry, nextPostgresPasswordRotation(cluster, users, time.Now())), err
 }
 
 // validatePostgresUsers emits warnings when cluster.Spec.Users contains values
@@ -576,7 +714,10 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 
 	// Index secrets by PostgreSQL user name and delete any that are not in the
 	// cluster spec. Keep track of the deprecated default secret to migrate its
-	// contents when the current secret doesn't exist.
+	// contents when the current secret doesn't exist. When users are dropped,
+	// their secrets are deleted later by [Reconciler.reconcilePostgresUserDeletion].
+	dropRemovedUsers := cluster.Spec.UserDeletion != nil &&
+		cluster.Spec.UserDeletion.Policy == v1beta1.PostgresUserDeletionDrop
 	var (
 		defaultSecret     *corev1.Secret
 		defaultSecretName = naming.DeprecatedPostgresUserSecret(cluster).Name
@@ -595,13 +736,21 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 				} else {
 					userSecrets[secretUserName] = secret
 				}
-			} else if err == nil {
+			} else if err == nil && !dropRemovedUsers {
 				err = errors.WithStack(r.deleteControlled(ctx, cluster, secret))
 			}
 		}
 	}
 
+	// Passwords are rotated on demand when the annotation has a value that
+	// has not been handled yet.
+	now := metav1.Now()
+	trigger, triggered := cluster.Annotations[naming.PostgresPasswordRotation]
+	triggered = triggered &&
+		(cluster.Status.PasswordRotation == nil || *cluster.Status.PasswordRotation != trigger)
+
 	// Reconcile each PostgreSQL user in the cluster spec.
+	var userStatuses []v1beta1.PostgresUserStatus
 	for userName, user := range userSpecs {
 		secret := userSecrets[userName]
 
@@ -611,23 +760,292 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 			secret = defaultSecret
 		}
 
+		var rotated *metav1.Time
+		if i := slices.IndexFunc(cluster.Status.Users, func(status v1beta1.PostgresUserStatus) bool {
+			return status.Name == userName
+		}); i >= 0 {
+			rotated = cluster.Status.Users[i].PasswordRotationTime
+		}
+
+		hadPassword := secret != nil && len(secret.Data["password"]) > 0
+		secret, rotated = rotatePostgresUserPassword(user, secret, rotated, triggered, now)
+
+		if err == nil && user.Password != nil && user.Password.SecretKeyRef != nil {
+			secret, err = r.providedPostgresUserPassword(ctx, cluster, user, secret)
+		}
+		if rotated != nil {
+			userStatuses = append(userStatuses, v1beta1.PostgresUserStatus{
+				Name: userName, PasswordRotationTime: rotated,
+			})
+		}
+
 		if err == nil {
 			userSecrets[userName], err = r.generatePostgresUserSecret(cluster, user, secret)
 		}
 		if err == nil {
 			err = errors.WithStack(r.apply(ctx, userSecrets[userName]))
 		}
+		if err == nil && hadPassword && len(secret.Data["password"]) == 0 {
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "RotatedPassword",
+				"Generated a new password for PostgreSQL user %q", userName)
+		}
+	}
+
+	if err == nil {
+		slices.SortFunc(userStatuses, func(a, b v1beta1.PostgresUserStatus) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+		cluster.Status.Users = userStatuses
+
+		if triggered {
+			cluster.Status.PasswordRotation = initialize.String(trigger)
+		}
 	}
 
 	return specUsers, userSecrets, err
 }
 
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+
+// providedPostgresUserPassword returns the Secret from which the user Secret of
+// spec should be generated when its password is in another Secret. The result
+// holds only a SCRAM verifier of that password. When the referenced value is
+// missing or invalid, it emits a warning and the verifier in existing is kept.
+func (r *Reconciler) providedPostgresUserPassword(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	spec *v1beta1.PostgresUserSpec, existing *corev1.Secret,
+) (*corev1.Secret, error) {
+	ref := spec.Password.SecretKeyRef
+	result := &corev1.Secret{Data: map[string][]byte{}}
+	if existing != nil {
+		result.Data["verifier"] = existing.Data["verifier"]
+	}
+
+	source := &corev1.Secret{}
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: ref.Name}, source)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.WithStack(err)
+	}
+
+	value := string(source.Data[ref.Key])
+	switch {
+	case len(value) == 0:
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidUserPassword",
+			"Secret %q has no key %q for PostgreSQL user %q", ref.Name, ref.Key, spec.Name)
+
+	case pgpassword.IsSCRAMVerifier(value):
+		if pgpassword.ValidateSCRAMVerifier(value) != nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidUserPassword",
+				"Secret %q key %q is not a valid SCRAM-SHA-256 verifier for PostgreSQL user %q",
+				ref.Name, ref.Key, spec.Name)
+		} else {
+			result.Data["verifier"] = []byte(value)
+		}
+
+	default:
+		// Keep the existing verifier when it matches the plaintext password.
+		// Every new verifier has a different salt and would change the role.
+		scram := pgpassword.NewSCRAMPassword(value)
+		if !scram.Verify(string(result.Data["verifier"])) {
+			verifier, err := scram.Build()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			result.Data["verifier"] = []byte(verifier)
+		}
+	}
+
+	return result, nil
+}
+
+// rotatePostgresUserPassword returns the Secret from which the password of
+// spec should be generated and the last time that password was rotated. When
+// the rotation policy of spec calls for a new password, the returned Secret is
+// a copy of existing that lacks a password. The returned time is nil when spec
+// has no rotation policy.
+func rotatePostgresUserPassword(
+	spec *v1beta1.PostgresUserSpec, existing *corev1.Secret,
+	rotated *metav1.Time, triggered bool, now metav1.Time,
+) (*corev1.Secret, *metav1.Time) {
+	var policy *v1beta1.PostgresPasswordRotationSpec
+	if spec.Password != nil && spec.Password.SecretKeyRef == nil {
+		policy = spec.Password.Rotation
+	}
+
+	if existing == nil || len(existing.Data["password"]) == 0 {
+		// A new password will be generated regardless.
+		if policy != nil {
+			rotated = &now
+		} else {
+			rotated = nil
+		}
+		return existing, rotated
+	}
+
+	if policy == nil {
+		return existing, nil
+	}
+
+	// Without a recorded rotation, the password is as old as its Secret.
+	if rotated == nil && !existing.CreationTimestamp.IsZero() {
+		rotated = existing.CreationTimestamp.DeepCopy()
+	}
+	if rotated == nil {
+		rotated = &now
+	}
+
+	expired := func(period *v1beta1.Duration) bool {
+		return period != nil && !now.Before(&metav1.Time{
+			Time: rotated.Add(period.AsDuration().Duration),
+		})
+	}
+
+	if triggered || expired(policy.Interval) {
+		result := existing.DeepCopy()
+		delete(result.Data, "password")
+		delete(result.Data, "verifier")
+		return result, &now
+	}
+
+	return existing, rotated
+}
+
+// nextPostgresPasswordRotation returns how long until the password of a user
+// in specUsers is due to be rotated. It returns zero when nothing is due.
+func nextPostgresPasswordRotation(
+	cluster *v1beta1.PostgresCluster, specUsers []v1beta1.PostgresUserSpec, now time.Time,
+) time.Duration {
+	var next time.Duration
+	consider := func(rotated *metav1.Time, period *v1beta1.Duration) {
+		if period != nil {
+			if d := rotated.Add(period.AsDuration().Duration).Sub(now); d > 0 && (next == 0 || d < next) {
+				next = d
+			}
+		}
+	}
+
+	for _, status := range cluster.Status.Users {
+		i := slices.IndexFunc(specUsers, func(user v1beta1.PostgresUserSpec) bool {
+			return user.Name == status.Name
+		})
+		if i < 0 || status.PasswordRotationTime == nil ||
+			specUsers[i].Password == nil || specUsers[i].Password.Rotation == nil {
+			continue
+		}
+
+		consider(status.PasswordRotationTime, specUsers[i].Password.Rotation.Interval)
+	}
+
+	return next
+}
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={list,delete}
+
+// reconcilePostgresUserDeletion drops PostgreSQL users that have been removed
+// from the cluster spec when the deletion policy is "Drop". The Secret of each
+// user is deleted once that user is dropped, so users that are retained are
+// attempted again later.
+func (r *Reconciler) reconcilePostgresUserDeletion(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	specUsers []v1beta1.PostgresUserSpec,
+) error {
+	const container = naming.ContainerDatabase
+	deletion := cluster.Spec.UserDeletion
+
+	if deletion == nil || deletion.Policy != v1beta1.PostgresUserDeletionDrop {
+		return nil
+	}
+
+	// Find the PostgreSQL instance that can execute SQL that writes system
+	// catalogs. When there is none, return early.
+	pod, _ := instances.writablePod(container)
+	if pod == nil {
+		return nil
+	}
+
+	secrets := &corev1.SecretList{}
+	selector, err := naming.AsSelector(naming.ClusterPostgresUsers(cluster.Name))
+	if err == nil {
+		err = errors.WithStack(
+			r.Client.List(ctx, secrets,
+				client.InNamespace(cluster.Namespace),
+				client.MatchingLabelsSelector{Selector: selector},
+			))
+	}
+	if err != nil {
+		return err
+	}
+
+	// Gather the secrets of users that are not in the cluster spec. The
+	// "postgres" superuser and the reassignment target are never dropped.
+	removed := map[string][]*corev1.Secret{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		userName := secret.Labels[naming.LabelPostgresUser]
+
+		if !slices.ContainsFunc(specUsers, func(user v1beta1.PostgresUserSpec) bool {
+			return user.Name == userName
+		}) {
+			removed[userName] = append(removed[userName], secret)
+		}
+	}
+
+	var drop []string
+	for userName := range removed {
+		if userName == "postgres" {
+			continue
+		}
+		if userName == deletion.ReassignOwnedTo {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "UserNotDropped",
+				"PostgreSQL user %q receives objects of dropped users", userName)
+			delete(removed, userName)
+			continue
+		}
+		drop = append(drop, userName)
+	}
+	slices.Sort(drop)
+
+	var retained []string
+	if len(drop) > 0 {
+		ctx := logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+		retained, err = postgres.DropUsersInPostgreSQL(ctx, func(
+			ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			return r.PodExec(ctx, pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+		}, drop, deletion.ReassignOwnedTo)
+		err = errors.WithStack(err)
+	}
+
+	for _, userName := range retained {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "UserNotDropped",
+			"PostgreSQL user %q owns objects; set spec.userDeletion.reassignOwnedTo to drop it", userName)
+		delete(removed, userName)
+	}
+
+	// Delete the secrets of users that were dropped or never dropped.
+	for userName, secrets := range removed {
+		for _, secret := range secrets {
+			if err == nil {
+				err = errors.WithStack(r.deleteControlled(ctx, cluster, secret))
+			}
+		}
+		if err == nil && slices.Contains(drop, userName) {
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "DroppedUser",
+				"Dropped PostgreSQL user %q", userName)
+		}
+	}
+
+	return err
+}
+
 // reconcilePostgresUsersInPostgreSQL creates users inside of PostgreSQL and
-// sets their options and database access as specified.
+// sets their options and database access as specified. It returns how long
+// until it should try again when some granted schemas do not exist yet; those
+// are reported in the "MissingSchemas" condition.
 func (r *Reconciler) reconcilePostgresUsersInPostgreSQL(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
 	specUsers []v1beta1.PostgresUserSpec, userSecrets map[string]*corev1.Secret,
-) error {
+) (time.Duration, error) {
 	const container = naming.ContainerDatabase
 	var podExecutor postgres.Executor
 
@@ -655,7 +1073,7 @@ func (r *Reconciler) reconcilePostgresUsersInPostgreSQL(
 		}
 	}
 	if podExecutor == nil {
-		return nil
+		return 0, nil
 	}
 
 	// Calculate a hash of the SQL that should be executed in PostgreSQL.
@@ -665,29 +1083,63 @@ func (r *Reconciler) reconcilePostgresUsersInPostgreSQL(
 		verifiers[userName] = string(userSecrets[userName].Data["verifier"])
 	}
 
+	var missing []string
 	write := func(ctx context.Context, exec postgres.Executor) error {
-		return postgres.WriteUsersInPostgreSQL(ctx, cluster, exec, specUsers, verifiers)
+		err := postgres.WriteUsersInPostgreSQL(ctx, cluster, exec, specUsers, verifiers)
+
+		// Databases are created before users, but their owners are often
+		// among those users. Change database owners after writing users.
+		if err == nil && len(cluster.Spec.Databases) > 0 {
+			err = postgres.WriteDatabaseOwnersInPostgreSQL(ctx, exec, cluster.Spec.Databases)
+		}
+
+		// Grant privileges to users and revoke those that are not specified.
+		if err == nil && slices.ContainsFunc(specUsers, func(user v1beta1.PostgresUserSpec) bool {
+			return user.Grants != nil
+		}) {
+			missing, err = postgres.WriteUserGrantsInPostgreSQL(ctx, exec, specUsers)
+		}
+		return err
 	}
 
-	revision, err := safeHash32(func(hasher io.Writer) error {
-		// Discard log messages about executing SQL.
-		return write(logging.NewContext(ctx, logging.Discard()), func(
-			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
-		) error {
-			_, err := fmt.Fprint(hasher, command)
-			if err == nil && stdin != nil {
-				_, err = io.Copy(hasher, stdin)
+	// Grants in schemas that do not exist are skipped. While that is so, the
+	// revision changes every hour so the SQL is applied again in case those
+	// schemas have been created.
+	var retry time.Duration
+	var retryHour time.Time
+	if meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.MissingSchemas) {
+		now := time.Now()
+		retryHour = now.Truncate(time.Hour)
+		retry = retryHour.Add(time.Hour).Sub(now)
+	}
+
+	hash := func(hour time.Time) (string, error) {
+		return safeHash32(func(hasher io.Writer) error {
+			// Discard log messages about executing SQL.
+			err := write(logging.NewContext(ctx, logging.Discard()), func(
+				_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+			) error {
+				_, err := fmt.Fprint(hasher, command)
+				if err == nil && stdin != nil {
+					_, err = io.Copy(hasher, stdin)
+				}
+				return err
+			})
+			if err == nil && !hour.IsZero() {
+				_, err = fmt.Fprint(hasher, hour.Unix())
 			}
 			return err
 		})
-	})
+	}
+
+	revision, err := hash(retryHour)
 
 	if err == nil && revision == cluster.Status.UsersRevision {
 		// The necessary SQL has already been applied; there's nothing more to do.
 
 		// TODO(cbandy): Give the user a way to trigger execution regardless.
 		// The value of an annotation could influence the hash, for example.
-		return nil
+		return retry, nil
 	}
 
 	// Apply the necessary SQL and record its hash in cluster.Status. Include
@@ -697,11 +1149,108 @@ func (r *Reconciler) reconcilePostgresUsersInPostgreSQL(
 		log := logging.FromContext(ctx).WithValues("revision", revision)
 		err = errors.WithStack(write(logging.NewContext(ctx, log), podExecutor))
 	}
+
+	// Privileges in schemas that do not exist were skipped. Report them in a
+	// condition and warn when they change.
+	if err == nil && len(missing) == 0 {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.MissingSchemas)
+
+		if !retryHour.IsZero() {
+			retry, retryHour = 0, time.Time{}
+			revision, err = hash(retryHour)
+		}
+	} else if err == nil {
+		message := "Skipped grants in schemas that do not exist: " + strings.Join(missing, ", ")
+		if condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.MissingSchemas); condition == nil || condition.Message != message {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "MissingSchemas", message)
+		}
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: cluster.Generation,
+			Type:               v1beta1.MissingSchemas,
+			Status:             metav1.ConditionTrue,
+			Reason:             "MissingSchemas",
+			Message:            message,
+		})
+
+		if retryHour.IsZero() {
+			now := time.Now()
+			retryHour = now.Truncate(time.Hour)
+			retry = retryHour.Add(time.Hour).Sub(now)
+			revision, err = hash(retryHour)
+		}
+	}
 	if err == nil {
 		cluster.Status.UsersRevision = revision
 	}
 
-	return err
+	return retry, err
+}
+
+// reconcilePostgresMD5Passwords replaces the MD5 passwords of PostgreSQL users
+// with the SCRAM verifiers in their Secrets and reports any roles that still
+// have MD5 passwords in the "MD5Passwords" condition. Roles are checked until
+// none have MD5 passwords and again after the cluster spec changes.
+func (r *Reconciler) reconcilePostgresMD5Passwords(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	userSecrets map[string]*corev1.Secret,
+) error {
+	const container = naming.ContainerDatabase
+
+	condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MD5Passwords)
+	if condition != nil && condition.Status == metav1.ConditionFalse &&
+		condition.ObservedGeneration == cluster.Generation {
+		return nil
+	}
+
+	// Find the PostgreSQL instance that can execute SQL that writes system
+	// catalogs. When there is none, return early.
+	pod, _ := instances.writablePod(container)
+	if pod == nil {
+		return nil
+	}
+
+	verifiers := make(map[string]string, len(userSecrets))
+	for userName := range userSecrets {
+		verifiers[userName] = string(userSecrets[userName].Data["verifier"])
+	}
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+	remaining, err := postgres.RehashMD5UsersInPostgreSQL(ctx, func(
+		ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(ctx, pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+	}, verifiers)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(remaining) == 0 {
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: cluster.Generation,
+			Type:               v1beta1.MD5Passwords,
+			Status:             metav1.ConditionFalse,
+			Reason:             "SCRAMPasswords",
+			Message:            "No PostgreSQL roles have MD5 passwords",
+		})
+		return nil
+	}
+
+	// Keep the message short when there are many roles.
+	names := remaining
+	if len(names) > 10 {
+		names = append(slices.Clip(names[:10]), fmt.Sprintf("and %d more", len(remaining)-10))
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		ObservedGeneration: cluster.Generation,
+		Type:               v1beta1.MD5Passwords,
+		Status:             metav1.ConditionTrue,
+		Reason:             "MD5Passwords",
+		Message: "PostgreSQL roles have MD5 passwords and need new passwords: " +
+			strings.Join(names, ", "),
+	})
+	return nil
 }
 
 // +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={create,patch}
@@ -1126,3 +1675,270 @@ func (r *Reconciler) reconcileDatabaseInitSQL(ctx context.Context,
 
 	return err
 }
+
+// reconcilePostgresLogicalReplication writes publications and subscriptions
+// inside of PostgreSQL and fills in the state of subscriptions in cluster.Status.
+// It returns how long to wait before doing so again.
+func (r *Reconciler) reconcilePostgresLogicalReplication(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (time.Duration, error) {
+	const container = naming.ContainerDatabase
+	var podExecutor postgres.Executor
+
+	if len(cluster.Spec.Publications) == 0 && len(cluster.Spec.Subscriptions) == 0 {
+		cluster.Status.LogicalReplicationRevision = ""
+		cluster.Status.Subscriptions = nil
+		cluster.Status.SubscriptionsObservedTime = nil
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.MissingReplicaIdentity)
+		return 0, nil
+	}
+
+	// Find the PostgreSQL instance that can execute SQL that writes system
+	// catalogs. When there is none, return early.
+	pod, _ := instances.writablePod(container)
+	if pod == nil {
+		return 0, nil
+	}
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+	podExecutor = func(
+		ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(ctx, pod.Namespace, pod.Name, container, stdin, stdout, stderr, command...)
+	}
+
+	// Connect each subscription to the primary Service of its source cluster
+	// using the password of the user in that cluster. Changes to those
+	// Secrets do not trigger this cluster, so check them again periodically.
+	var requeue time.Duration
+	connections := make(map[string]string, len(cluster.Spec.Subscriptions))
+	for _, spec := range cluster.Spec.Subscriptions {
+		source := &v1beta1.PostgresCluster{}
+		source.Namespace, source.Name = cluster.Namespace, spec.Source.ClusterName
+
+		secret := &corev1.Secret{ObjectMeta: naming.PostgresUserSecret(source, spec.Source.User)}
+		password, err := r.subscriptionSourcePassword(ctx, source, spec.Source.User, secret)
+
+		if err == nil && len(password) == 0 {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "SubscriptionSourceUnavailable",
+				"Unable to find the password of user %q in PostgresCluster %q for subscription %q",
+				spec.Source.User, spec.Source.ClusterName, spec.Name)
+			requeue = time.Minute
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		database := spec.Source.Database
+		if database == "" {
+			database = spec.Database
+		}
+
+		// The source cluster may use a different certificate authority, so
+		// require encryption without verifying its certificate.
+		// - https://www.postgresql.org/docs/current/libpq-ssl.html#LIBPQ-SSL-PROTECTION
+		connections[spec.Name] = postgres.ConnectionString(map[string]string{
+			"dbname":   database,
+			"host":     string(secret.Data["host"]),
+			"password": password,
+			"port":     string(secret.Data["port"]),
+			"sslmode":  "require",
+			"user":     string(secret.Data["user"]),
+		})
+	}
+
+	var unidentified []string
+	write := func(ctx context.Context, exec postgres.Executor) error {
+		var err error
+		if len(cluster.Spec.Publications) > 0 {
+			unidentified, err = postgres.WritePublicationsInPostgreSQL(
+				ctx, exec, cluster.Spec.Publications)
+		}
+		if err == nil && len(connections) > 0 {
+			err = postgres.WriteSubscriptionsInPostgreSQL(ctx, exec,
+				cluster.Spec.Subscriptions, connections)
+		}
+		return err
+	}
+
+	// Publications of all tables are not created while some tables have no
+	// replica identity. While that is so, the revision changes every hour so
+	// the SQL is applied again in case those tables have changed.
+	var retryHour time.Time
+	if meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.MissingReplicaIdentity) {
+		now := time.Now()
+		retryHour = now.Truncate(time.Hour)
+		requeue = shorter(requeue, retryHour.Add(time.Hour).Sub(now))
+	}
+
+	// Calculate a hash of the SQL that should be executed in PostgreSQL.
+	hash := func(hour time.Time) (string, error) {
+		return safeHash32(func(hasher io.Writer) error {
+			// Discard log messages about executing SQL.
+			err := write(logging.NewContext(ctx, logging.Discard()), func(
+				_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+			) error {
+				_, err := fmt.Fprint(hasher, command)
+				if err == nil && stdin != nil {
+					_, err = io.Copy(hasher, stdin)
+				}
+				return err
+			})
+			if err == nil && !hour.IsZero() {
+				_, err = fmt.Fprint(hasher, hour.Unix())
+			}
+			return err
+		})
+	}
+
+	revision, err := hash(retryHour)
+
+	// Apply the necessary SQL and record its hash in cluster.Status. Include
+	// the hash in any log messages.
+	written := false
+	if err == nil && revision != cluster.Status.LogicalReplicationRevision {
+		log := logging.FromContext(ctx).WithValues("revision", revision)
+		err = errors.WithStack(write(logging.NewContext(ctx, log), podExecutor))
+		written = err == nil
+	}
+
+	// Tables without a replica identity held back publications of all tables.
+	// Report them in a condition and warn when they change.
+	if written && len(unidentified) == 0 {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.MissingReplicaIdentity)
+
+		if !retryHour.IsZero() {
+			retryHour = time.Time{}
+			revision, err = hash(retryHour)
+		}
+	} else if written {
+		message := "Publications of all tables wait for these to have a replica identity: " +
+			strings.Join(unidentified, ", ")
+		if condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.MissingReplicaIdentity); condition == nil || condition.Message != message {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "MissingReplicaIdentity", message)
+		}
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: cluster.Generation,
+			Type:               v1beta1.MissingReplicaIdentity,
+			Status:             metav1.ConditionTrue,
+			Reason:             "MissingReplicaIdentity",
+			Message:            message,
+		})
+
+		if retryHour.IsZero() {
+			now := time.Now()
+			retryHour = now.Truncate(time.Hour)
+			requeue = shorter(requeue, retryHour.Add(time.Hour).Sub(now))
+			revision, err = hash(retryHour)
+		}
+	}
+	if err == nil {
+		cluster.Status.LogicalReplicationRevision = revision
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if len(cluster.Spec.Subscriptions) == 0 {
+		cluster.Status.Subscriptions = nil
+		cluster.Status.SubscriptionsObservedTime = nil
+		return requeue, nil
+	}
+
+	// Query the state of subscriptions after writing them or when the last
+	// query is old enough. Every change to status triggers another reconcile,
+	// and subscription lag changes constantly.
+	now := time.Now()
+	if wait := observationDelay(cluster.Status.SubscriptionsObservedTime, now); wait > 0 && !written {
+		return shorter(requeue, wait), nil
+	}
+	requeue = shorter(requeue, replicationObservationInterval)
+
+	// Record this attempt whether or not it succeeds so that the next one
+	// waits, even when no subscriptions are found.
+	observed := metav1.NewTime(now)
+	cluster.Status.SubscriptionsObservedTime = &observed
+
+	subscriptions, err := postgres.SubscriptionStatusInPostgreSQL(ctx, podExecutor)
+	if err != nil {
+		logging.FromContext(ctx).Error(err, "unable to query subscription status")
+		return requeue, nil
+	}
+
+	cluster.Status.Subscriptions = nil
+	for _, spec := range cluster.Spec.Subscriptions {
+		for _, subscription := range subscriptions {
+			if subscription.Name != spec.Name || subscription.Database != spec.Database {
+				continue
+			}
+
+			status := v1beta1.PostgresSubscriptionStatus{
+				Name:         spec.Name,
+				Database:     spec.Database,
+				ObservedTime: &observed,
+			}
+			switch {
+			case !subscription.Enabled:
+				status.State = v1beta1.PostgresSubscriptionDisabled
+			case !subscription.Running:
+				status.State = v1beta1.PostgresSubscriptionStopped
+			case subscription.Synchronizing > 0:
+				status.State = v1beta1.PostgresSubscriptionSynchronizing
+			default:
+				status.State = v1beta1.PostgresSubscriptionStreaming
+			}
+			if subscription.LagSeconds != nil {
+				status.LagSeconds = initialize.Int64(int64(*subscription.LagSeconds))
+			}
+			cluster.Status.Subscriptions = append(cluster.Status.Subscriptions, status)
+		}
+	}
+
+	return requeue, nil
+}
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get}
+
+// subscriptionSourcePassword reads secret, the user Secret of user in source,
+// and returns the password that user connects with. When the source cluster
+// keeps that password in another Secret, the password is read from there, as
+// the PGUpgrade controller does. It returns an empty string when the source
+// or its password is unavailable or when only a verifier of it is known.
+func (r *Reconciler) subscriptionSourcePassword(
+	ctx context.Context, source *v1beta1.PostgresCluster, user string, secret *corev1.Secret,
+) (string, error) {
+	err := r.Client.Get(ctx, client.ObjectKeyFromObject(source), source)
+	if err == nil {
+		err = r.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret)
+	}
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	password := string(secret.Data["password"])
+	for _, spec := range source.Spec.Users {
+		if spec.Name == user && spec.Password != nil && spec.Password.SecretKeyRef != nil {
+			ref := spec.Password.SecretKeyRef
+			provided := &corev1.Secret{}
+			err := r.Client.Get(ctx, client.ObjectKey{Namespace: source.Namespace, Name: ref.Name}, provided)
+			if apierrors.IsNotFound(err) {
+				return "", nil
+			}
+			if err != nil {
+				return "", errors.WithStack(err)
+			}
+			password = string(provided.Data[ref.Key])
+		}
+	}
+
+	if pgpassword.IsSCRAMVerifier(password) {
+		return "", nil
+	}
+	return password, nil
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr/funcr"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -20,9 +21,11 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/feature"
@@ -162,6 +165,45 @@ func TestGeneratePostgresHBAs(t *testing.T) {
 			`another`,                              // Patroni
 		})
 	})
+
+	t.Run("RejectMD5", func(t *testing.T) {
+		cluster := v1beta1.NewPostgresCluster()
+		require.UnmarshalInto(t, &cluster.Spec.Authentication, `{
+			rejectMD5: true,
+			rules: [
+				{ connection: host, method: md5 },
+				{ hba: "host all all all md5" },
+				{ hba: "hostssl all all 10.0.0.0 255.0.0.0 password" },
+			],
+		}`)
+		require.UnmarshalInto(t, &cluster.Spec.Patroni, `{
+			dynamicConfiguration: {
+				postgresql: { pg_hba: ["local all all md5"] },
+			},
+		}`)
+
+		// Nothing changes until there are no MD5 passwords.
+		result := reconciler.generatePostgresHBAs(ctx, cluster).AsStrings()
+		assert.DeepEqual(t, result[len(required):], []string{
+			`"host" all all all "md5"`,
+			`host all all all md5`,
+			`hostssl all all 10.0.0.0 255.0.0.0 password`,
+			`local all all md5`,
+		})
+
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: v1beta1.MD5Passwords, Status: metav1.ConditionFalse, Reason: "SCRAMPasswords",
+		})
+
+		// Every password method is replaced, including unstructured rules.
+		result = reconciler.generatePostgresHBAs(ctx, cluster).AsStrings()
+		assert.DeepEqual(t, result[len(required):], []string{
+			`"host" all all all scram-sha-256`,
+			`host all all all scram-sha-256`,
+			`hostssl all all 10.0.0.0 255.0.0.0 scram-sha-256`,
+			`local all all scram-sha-256`,
+		})
+	})
 }
 
 func TestGeneratePostgresParameters(t *testing.T) {
@@ -278,6 +320,37 @@ func TestGeneratePostgresParameters(t *testing.T) {
 			assert.Equal(t, result.Value("shared_preload_libraries"), "citus,pgaudit,given, citus,other",
 				"expected citus in front")
 		})
+
+		t.Run("Extensions", func(t *testing.T) {
+			cluster := v1beta1.NewPostgresCluster()
+			require.UnmarshalInto(t, &cluster.Spec, `{
+				config: { parameters: { shared_preload_libraries: given } },
+				extensions: [{ name: pg_cron, sharedPreloadLibraries: [pg_cron] }],
+			}`)
+
+			result := reconciler.generatePostgresParameters(ctx, cluster, false)
+			assert.Equal(t, result.Value("shared_preload_libraries"), "pgaudit,pg_cron,given",
+				"expected extension libraries with mandatory ones")
+		})
+	})
+
+	t.Run("password_encryption", func(t *testing.T) {
+		cluster := v1beta1.NewPostgresCluster()
+		require.UnmarshalInto(t, &cluster.Spec, `{
+			authentication: { rejectMD5: true },
+			config: { parameters: { password_encryption: md5 } },
+		}`)
+
+		result := reconciler.generatePostgresParameters(ctx, cluster, false)
+		assert.Equal(t, result.Value("password_encryption"), "md5",
+			"expected no change while there are MD5 passwords")
+
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: v1beta1.MD5Passwords, Status: metav1.ConditionFalse, Reason: "SCRAMPasswords",
+		})
+
+		result = reconciler.generatePostgresParameters(ctx, cluster, false)
+		assert.Equal(t, result.Value("password_encryption"), "scram-sha-256")
 	})
 }
 
@@ -384,14 +457,28 @@ func TestGeneratePostgresUserSecret(t *testing.T) {
 		secret, err = reconciler.generatePostgresUserSecret(cluster, spec, &corev1.Secret{
 			Data: map[string][]byte{
 				"password": []byte(`asdf`),
-				"verifier": []byte(`some$thing`),
+				"verifier": []byte(`SCRAM-SHA-256$some$thing`),
 			},
 		})
 		assert.NilError(t, err)
 
 		if assert.Check(t, secret != nil) {
 			assert.Equal(t, string(secret.Data["password"]), "asdf")
-			assert.Equal(t, string(secret.Data["verifier"]), "some$thing")
+			assert.Equal(t, string(secret.Data["verifier"]), "SCRAM-SHA-256$some$thing")
+		}
+
+		// Generated when existing verifier is MD5.
+		secret, err = reconciler.generatePostgresUserSecret(cluster, spec, &corev1.Secret{
+			Data: map[string][]byte{
+				"password": []byte(`asdf`),
+				"verifier": []byte(`md5a7b5b5b1b14e9c8d6d8ad5c5e1a1bfc3`),
+			},
+		})
+		assert.NilError(t, err)
+
+		if assert.Check(t, secret != nil) {
+			assert.Equal(t, string(secret.Data["password"]), "asdf")
+			assert.Assert(t, cmp.Regexp(`^SCRAM-SHA-256[$]`, string(secret.Data["verifier"])))
 		}
 	})
 
@@ -477,6 +564,210 @@ func TestGeneratePostgresUserSecret(t *testing.T) {
 	})
 }
 
+func TestProvidedPostgresUserPassword(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+
+	spec := &v1beta1.PostgresUserSpec{Name: "app"}
+	spec.Password = &v1beta1.PostgresPasswordSpec{
+		SecretKeyRef: &v1beta1.SecretKeyRef{Name: "external", Key: "value"},
+	}
+
+	verifier := `SCRAM-SHA-256$4096:aDFwcDBwNHJ0eTIwMjA=$xHkOo65LX9eBB8a6v+axqvs3+aMBTH0sCT7w/Nxzh5M=:PXuFoeJNuAGSeExskYSqkwUyiUJu8LPC9DgwDWQ9ARQ=`
+	existing := &corev1.Secret{Data: map[string][]byte{
+		"password": []byte("generated"),
+		"verifier": []byte(verifier),
+	}}
+
+	reconcile := func(value string) (*corev1.Secret, *events.Recorder) {
+		source := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "external"}}
+		if value != "" {
+			source.Data = map[string][]byte{"value": []byte(value)}
+		}
+
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		reconciler := &Reconciler{Recorder: recorder}
+		reconciler.Client = fake.NewClientBuilder().WithObjects(source).Build()
+
+		secret, err := reconciler.providedPostgresUserPassword(ctx, cluster, spec, existing)
+		assert.NilError(t, err)
+		return secret, recorder
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		secret, recorder := reconcile("")
+		assert.DeepEqual(t, secret.Data, map[string][]byte{"verifier": []byte(verifier)})
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "InvalidUserPassword")
+		assert.Equal(t, recorder.Events[0].Note,
+			`Secret "external" has no key "value" for PostgreSQL user "app"`)
+	})
+
+	t.Run("Verifier", func(t *testing.T) {
+		other := `SCRAM-SHA-256$4096:aDFwcDBwNHJ0eTIwMjA=$s9HbNQBsfJwflGr4lvr4vEt/vvspp5Uu8IjWYLjMUMg=:3sUGJgo/70EQvjsma2I/RJsheqLhxN2rarUt7oqK6q8=`
+
+		secret, recorder := reconcile(other)
+		assert.DeepEqual(t, secret.Data, map[string][]byte{"verifier": []byte(other)})
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("InvalidVerifier", func(t *testing.T) {
+		secret, recorder := reconcile(`SCRAM-SHA-256$4096:aDFwcDBwNHJ0eTIwMjA=`)
+		assert.DeepEqual(t, secret.Data, map[string][]byte{"verifier": []byte(verifier)})
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "InvalidUserPassword")
+	})
+
+	t.Run("Plaintext", func(t *testing.T) {
+		// The existing verifier matches; it is kept.
+		secret, _ := reconcile("datalake")
+		assert.DeepEqual(t, secret.Data, map[string][]byte{"verifier": []byte(verifier)})
+
+		// The existing verifier does not match; a new one is built.
+		secret, _ = reconcile("datalak3")
+		assert.Assert(t, string(secret.Data["verifier"]) != verifier)
+		assert.Assert(t, cmp.Regexp(`^SCRAM-SHA-256[$]`, string(secret.Data["verifier"])))
+
+		// The plaintext is not stored.
+		assert.Equal(t, len(secret.Data), 1)
+	})
+
+	t.Run("UserSecret", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Port = initialize.Int32(5432)
+		spec := spec.DeepCopy()
+		spec.Databases = []v1beta1.PostgresIdentifier{"db1"}
+
+		secret, _ := reconcile("datalake")
+		reconciler := &Reconciler{Client: fake.NewClientBuilder().WithScheme(runtime.Scheme).Build()}
+		secret, err := reconciler.generatePostgresUserSecret(cluster, spec, secret)
+		assert.NilError(t, err)
+
+		assert.Equal(t, string(secret.Data["verifier"]), verifier)
+		assert.Equal(t, string(secret.Data["dbname"]), "db1")
+		for _, key := range []string{"password", "uri", "jdbc-uri"} {
+			_, found := secret.Data[key]
+			assert.Assert(t, !found, "unexpected %q", key)
+		}
+	})
+
+	t.Run("NotRotated", func(t *testing.T) {
+		spec := spec.DeepCopy()
+		spec.Password.Rotation = &v1beta1.PostgresPasswordRotationSpec{}
+
+		secret, rotated := rotatePostgresUserPassword(spec, existing, nil, true, metav1.Now())
+		assert.Assert(t, rotated == nil)
+		assert.DeepEqual(t, secret.Data, existing.Data)
+	})
+}
+
+func TestRotatePostgresUserPassword(t *testing.T) {
+	now := metav1.Now()
+	hourAgo := metav1.NewTime(now.Add(-time.Hour))
+	dayAgo := metav1.NewTime(now.Add(-24 * time.Hour))
+
+	day, err := v1beta1.NewDuration("1d")
+	assert.NilError(t, err)
+
+	existing := &corev1.Secret{Data: map[string][]byte{
+		"password": []byte("current"),
+		"verifier": []byte("SCRAM-SHA-256$current"),
+	}}
+
+	t.Run("NoPolicy", func(t *testing.T) {
+		spec := &v1beta1.PostgresUserSpec{Name: "some-user"}
+
+		secret, rotated := rotatePostgresUserPassword(spec, nil, nil, true, now)
+		assert.Assert(t, secret == nil)
+		assert.Assert(t, rotated == nil)
+
+		secret, rotated = rotatePostgresUserPassword(spec, existing, &dayAgo, true, now)
+		assert.Assert(t, rotated == nil)
+		assert.DeepEqual(t, secret.Data, map[string][]byte{
+			"password": []byte("current"),
+			"verifier": []byte("SCRAM-SHA-256$current"),
+		})
+	})
+
+	spec := &v1beta1.PostgresUserSpec{Name: "some-user"}
+	spec.Password = &v1beta1.PostgresPasswordSpec{
+		Type:     v1beta1.PostgresPasswordTypeASCII,
+		Rotation: &v1beta1.PostgresPasswordRotationSpec{Interval: day},
+	}
+
+	t.Run("NoSecret", func(t *testing.T) {
+		secret, rotated := rotatePostgresUserPassword(spec, nil, &dayAgo, false, now)
+		assert.Assert(t, secret == nil)
+		assert.DeepEqual(t, rotated, &now)
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		secret, rotated := rotatePostgresUserPassword(spec, existing, &dayAgo, false, now)
+		assert.DeepEqual(t, rotated, &now)
+		assert.DeepEqual(t, secret.Data, map[string][]byte{})
+
+		// The existing Secret is not modified.
+		assert.Equal(t, string(existing.Data["password"]), "current")
+	})
+
+	t.Run("Triggered", func(t *testing.T) {
+		recent := metav1.NewTime(now.Add(-time.Minute))
+
+		secret, rotated := rotatePostgresUserPassword(spec, existing, &recent, true, now)
+		assert.DeepEqual(t, rotated, &now)
+		assert.Equal(t, len(secret.Data["password"]), 0)
+		assert.Equal(t, len(secret.Data["verifier"]), 0)
+	})
+
+	t.Run("NotDue", func(t *testing.T) {
+		secret, rotated := rotatePostgresUserPassword(spec, existing, &hourAgo, false, now)
+		assert.DeepEqual(t, rotated, &hourAgo)
+		assert.DeepEqual(t, secret.Data, existing.Data)
+	})
+
+	t.Run("CreationTimestamp", func(t *testing.T) {
+		created := existing.DeepCopy()
+		created.CreationTimestamp = dayAgo
+
+		secret, rotated := rotatePostgresUserPassword(spec, created, nil, false, now)
+		assert.DeepEqual(t, rotated, &now)
+		assert.Equal(t, len(secret.Data["password"]), 0)
+	})
+}
+
+func TestNextPostgresPasswordRotation(t *testing.T) {
+	now := time.Now()
+	hour, err := v1beta1.NewDuration("1h")
+	assert.NilError(t, err)
+	day, err := v1beta1.NewDuration("1d")
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{}
+	users := []v1beta1.PostgresUserSpec{{Name: "a"}, {Name: "b"}}
+
+	assert.Equal(t, nextPostgresPasswordRotation(cluster, users, now), time.Duration(0))
+
+	users[0].Password = &v1beta1.PostgresPasswordSpec{
+		Rotation: &v1beta1.PostgresPasswordRotationSpec{Interval: hour},
+	}
+	users[1].Password = &v1beta1.PostgresPasswordSpec{
+		Rotation: &v1beta1.PostgresPasswordRotationSpec{Interval: day},
+	}
+	cluster.Status.Users = []v1beta1.PostgresUserStatus{
+		{Name: "a", PasswordRotationTime: &metav1.Time{Time: now.Add(-2 * time.Hour)}},
+		{Name: "b", PasswordRotationTime: &metav1.Time{Time: now.Add(-20 * time.Hour)}},
+		{Name: "removed", PasswordRotationTime: &metav1.Time{Time: now.Add(-24 * time.Hour)}},
+	}
+
+	// The password of "b" is due in four hours. The password of "a" is overdue.
+	assert.Equal(t, nextPostgresPasswordRotation(cluster, users, now), 4*time.Hour)
+}
+
 func TestReconcilePostgresVolumes(t *testing.T) {
 	ctx := context.Background()
 	_, tClient := setupKubernetes(t)
@@ -1400,6 +1691,111 @@ func TestReconcileDatabaseInitSQLConfigMap(t *testing.T) {
 	})
 }
 
+func TestReconcilePostgresDatabasesStopConnections(t *testing.T) {
+	ctx := context.Background()
+
+	primary := &corev1.Pod{}
+	primary.Namespace, primary.Name = "ns1", "hippo-00-abcd-0"
+	primary.Annotations = map[string]string{"status": `{"role":"primary"}`}
+	primary.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{primary}},
+	}}
+
+	newCluster := func() *v1beta1.PostgresCluster {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Namespace, cluster.Name = "ns1", "hippo"
+		require.UnmarshalInto(t, &cluster.Spec, `{
+			databases: [{ name: app, connectionLimit: 5 }],
+			users: [{ name: hippo, databases: [app, other] }],
+		}`)
+		return cluster
+	}
+
+	// exec records the SQL sent to PostgreSQL and prints the previous limits
+	// of databases when their connections are stopped.
+	exec := func(scripts *[]string, previous string) func(
+		context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+	) error {
+		return func(
+			_ context.Context, _, _, _ string, stdin io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			*scripts = append(*scripts, string(b))
+
+			if strings.Contains(string(b), "json_object_agg") {
+				_, _ = stdout.Write([]byte(previous))
+			}
+			return nil
+		}
+	}
+
+	t.Run("Stop", func(t *testing.T) {
+		var scripts []string
+		r := &Reconciler{PodExec: exec(&scripts, `{"app":5,"other":-1}`)}
+
+		cluster := newCluster()
+		cluster.Annotations = map[string]string{
+			naming.PostgresStopConnections: `["app","other"]`,
+		}
+
+		assert.NilError(t, r.reconcilePostgresDatabases(ctx, cluster, instances))
+		assert.DeepEqual(t, cluster.Status.StoppedConnectionLimits,
+			map[string]int32{"app": 5, "other": -1})
+
+		all := strings.Join(scripts, "\n")
+		assert.Assert(t, cmp.Contains(all, `{"database":"app"}`+"\n"+`{"database":"other"}`))
+		assert.Assert(t, !strings.Contains(all, `"connection_limit":5`),
+			"expected the specified limit to wait")
+		assert.Assert(t, !strings.Contains(all, `WHERE datconnlimit = 0`),
+			"expected nothing restored")
+	})
+
+	t.Run("Redirected", func(t *testing.T) {
+		var scripts []string
+		r := &Reconciler{PodExec: exec(&scripts, ``)}
+
+		cluster := newCluster()
+		cluster.Annotations = map[string]string{
+			naming.PostgresPrimaryRedirect: "rhino",
+		}
+		cluster.Status.StoppedConnectionLimits = map[string]int32{"app": 5, "other": -1}
+
+		assert.NilError(t, r.reconcilePostgresDatabases(ctx, cluster, instances))
+		assert.DeepEqual(t, cluster.Status.StoppedConnectionLimits,
+			map[string]int32{"app": 5, "other": -1})
+
+		all := strings.Join(scripts, "\n")
+		assert.Assert(t, cmp.Contains(all, `{"database":"app"}`+"\n"+`{"database":"other"}`))
+		assert.Assert(t, !strings.Contains(all, `WHERE datconnlimit = 0`),
+			"expected nothing restored")
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		var scripts []string
+		r := &Reconciler{PodExec: exec(&scripts, ``)}
+
+		cluster := newCluster()
+		cluster.Annotations = map[string]string{
+			naming.PostgresStopConnections: `["other"]`,
+		}
+		cluster.Status.StoppedConnectionLimits = map[string]int32{"app": 5, "other": -1}
+
+		assert.NilError(t, r.reconcilePostgresDatabases(ctx, cluster, instances))
+		assert.DeepEqual(t, cluster.Status.StoppedConnectionLimits,
+			map[string]int32{"other": -1})
+
+		all := strings.Join(scripts, "\n")
+		assert.Assert(t, cmp.Contains(all, `{"connection_limit":5,"database":"app"}`))
+		assert.Assert(t, cmp.Contains(all, `{"database":"other"}`))
+	})
+}
+
 func TestValidatePostgresUsers(t *testing.T) {
 	t.Parallel()
 
@@ -1480,3 +1876,578 @@ func TestValidatePostgresUsers(t *testing.T) {
 		reconciler.validatePostgresUsers(cluster)
 	})
 }
+
+func TestReconcilePostgresUsersInPostgreSQLMissingSchemas(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+
+	instances := &observedInstances{forCluster: []*Instance{{
+		Name: "instance",
+		Pods: []*corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns1", Name: "pod",
+				Annotations: map[string]string{"status": `{"role":"primary"}`},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  naming.ContainerDatabase,
+					State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+				}},
+			},
+		}},
+		Runner: &appsv1.StatefulSet{},
+	}}}
+
+	var users []v1beta1.PostgresUserSpec
+	require.UnmarshalInto(t, &users, `[{
+		name: app,
+		grants: { schemas: [{ database: app, schema: reports, privileges: [USAGE] }] },
+	}]`)
+
+	recorder := events.NewRecorder(t, runtime.Scheme)
+	reconciler := &Reconciler{Recorder: recorder}
+	missing := ""
+	calls := 0
+	reconciler.PodExec = func(
+		_ context.Context, _, _, _ string, _ io.Reader, stdout, _ io.Writer, command ...string,
+	) error {
+		calls++
+		if strings.Contains(strings.Join(command, "\n"), "pg_catalog.pg_database") {
+			_, _ = io.WriteString(stdout, missing)
+		}
+		return nil
+	}
+
+	// The grant in a missing schema is reported once.
+	missing = "app.reports\n"
+	retry, err := reconciler.reconcilePostgresUsersInPostgreSQL(ctx, cluster, instances, users, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, retry > 0 && retry <= time.Hour)
+	assert.Assert(t, cluster.Status.UsersRevision != "")
+	assert.Equal(t, len(recorder.Events), 1)
+	assert.Equal(t, recorder.Events[0].Reason, "MissingSchemas")
+
+	condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MissingSchemas)
+	if assert.Check(t, condition != nil) {
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Message,
+			"Skipped grants in schemas that do not exist: app.reports")
+	}
+
+	// Nothing is applied again until the next hour.
+	before := calls
+	retry, err = reconciler.reconcilePostgresUsersInPostgreSQL(ctx, cluster, instances, users, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, retry > 0 && retry <= time.Hour)
+	assert.Equal(t, calls, before)
+	assert.Equal(t, len(recorder.Events), 1)
+
+	// Once the schema exists, the condition goes away.
+	missing = ""
+	cluster.Status.UsersRevision = ""
+	retry, err = reconciler.reconcilePostgresUsersInPostgreSQL(ctx, cluster, instances, users, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, retry, time.Duration(0))
+	assert.Assert(t, calls > before)
+	assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MissingSchemas) == nil)
+	assert.Equal(t, len(recorder.Events), 1)
+
+	// The revision is stable after that.
+	before = calls
+	_, err = reconciler.reconcilePostgresUsersInPostgreSQL(ctx, cluster, instances, users, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, calls, before)
+}
+
+func TestReconcilePostgresMD5Passwords(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+	cluster.Generation = 2
+
+	instances := &observedInstances{forCluster: []*Instance{{
+		Name: "instance",
+		Pods: []*corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns1", Name: "pod",
+				Annotations: map[string]string{"status": `{"role":"primary"}`},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  naming.ContainerDatabase,
+					State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+				}},
+			},
+		}},
+		Runner: &appsv1.StatefulSet{},
+	}}}
+
+	secrets := map[string]*corev1.Secret{
+		"app": {Data: map[string][]byte{"verifier": []byte("SCRAM-SHA-256$1:salt$stored:server")}},
+	}
+
+	reconciler := &Reconciler{}
+	output := ""
+	calls := 0
+	reconciler.PodExec = func(
+		_ context.Context, namespace, pod, container string,
+		stdin io.Reader, stdout, _ io.Writer, command ...string,
+	) error {
+		calls++
+		assert.Equal(t, pod, "pod")
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b),
+			`{"username":"app","verifier":"SCRAM-SHA-256$1:salt$stored:server"}`))
+
+		_, _ = io.WriteString(stdout, output)
+		return nil
+	}
+
+	// Some roles still have MD5 passwords.
+	output = "legacy\nother\n"
+	assert.NilError(t, reconciler.reconcilePostgresMD5Passwords(ctx, cluster, instances, secrets))
+	assert.Equal(t, calls, 1)
+
+	condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MD5Passwords)
+	if assert.Check(t, condition != nil) {
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "MD5Passwords")
+		assert.Equal(t, condition.Message,
+			"PostgreSQL roles have MD5 passwords and need new passwords: legacy, other")
+	}
+
+	// No roles have MD5 passwords.
+	output = ""
+	assert.NilError(t, reconciler.reconcilePostgresMD5Passwords(ctx, cluster, instances, secrets))
+	assert.Equal(t, calls, 2)
+
+	condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MD5Passwords)
+	if assert.Check(t, condition != nil) {
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.ObservedGeneration, int64(2))
+	}
+
+	// Nothing to check until the spec changes.
+	assert.NilError(t, reconciler.reconcilePostgresMD5Passwords(ctx, cluster, instances, secrets))
+	assert.Equal(t, calls, 2)
+
+	cluster.Generation = 3
+	assert.NilError(t, reconciler.reconcilePostgresMD5Passwords(ctx, cluster, instances, secrets))
+	assert.Equal(t, calls, 3)
+}
+
+func TestReconcilePostgresUserDeletion(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+	cluster.UID = "hippo-uid"
+
+	instances := &observedInstances{forCluster: []*Instance{{
+		Name: "instance",
+		Pods: []*corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns1", Name: "pod",
+				Annotations: map[string]string{"status": `{"role":"primary"}`},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  naming.ContainerDatabase,
+					State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+				}},
+			},
+		}},
+		Runner: &appsv1.StatefulSet{},
+	}}}
+
+	secret := func(user string) *corev1.Secret {
+		secret := &corev1.Secret{ObjectMeta: naming.PostgresUserSecret(cluster, user)}
+		secret.Labels = naming.Merge(
+			naming.ClusterPostgresUsers(cluster.Name).MatchLabels,
+			map[string]string{naming.LabelPostgresUser: user})
+		secret.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "postgres-operator.crunchydata.com/v1beta1",
+			Kind:       "PostgresCluster", Name: cluster.Name, UID: cluster.UID,
+			Controller: initialize.Bool(true),
+		}}
+		return secret
+	}
+
+	t.Run("Retain", func(t *testing.T) {
+		reconciler := &Reconciler{}
+		reconciler.PodExec = func(
+			context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			panic("should not be called")
+		}
+
+		assert.NilError(t, reconciler.reconcilePostgresUserDeletion(ctx, cluster, instances, nil))
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.UserDeletion = &v1beta1.PostgresUserDeletionSpec{Policy: "Retain"}
+		assert.NilError(t, reconciler.reconcilePostgresUserDeletion(ctx, cluster, instances, nil))
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.UserDeletion = &v1beta1.PostgresUserDeletionSpec{Policy: "Drop"}
+
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		reconciler := &Reconciler{Recorder: recorder}
+		reconciler.Client = fake.NewClientBuilder().WithObjects(
+			secret("app"), secret("gone"), secret("owner"), secret("postgres"),
+		).Build()
+
+		var scripts []string
+		reconciler.PodExec = func(
+			_ context.Context, namespace, pod, container string,
+			stdin io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			assert.Equal(t, namespace, "ns1")
+			assert.Equal(t, pod, "pod")
+			assert.Equal(t, container, naming.ContainerDatabase)
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			scripts = append(scripts, string(b))
+
+			// The first script prints users that own objects.
+			if len(scripts) == 1 {
+				assert.Assert(t, cmp.Contains(string(b), "\n"+`{"username":"gone"}`+"\n"+`{"username":"owner"}`+"\n"))
+				_, _ = io.WriteString(stdout, "owner\n")
+			}
+			return nil
+		}
+
+		assert.NilError(t, reconciler.reconcilePostgresUserDeletion(ctx, cluster, instances,
+			[]v1beta1.PostgresUserSpec{{Name: "app"}}))
+		assert.Equal(t, len(scripts), 3)
+
+		var secrets corev1.SecretList
+		assert.NilError(t, reconciler.Client.List(ctx, &secrets))
+
+		var remaining []string
+		for _, secret := range secrets.Items {
+			remaining = append(remaining, secret.Labels[naming.LabelPostgresUser])
+		}
+		assert.DeepEqual(t, remaining, []string{"app", "owner"})
+
+		var reasons []string
+		for _, event := range recorder.Events {
+			reasons = append(reasons, event.Reason+" "+event.Note)
+		}
+		assert.Assert(t, cmp.Contains(reasons, `DroppedUser Dropped PostgreSQL user "gone"`))
+		assert.Assert(t, cmp.Contains(reasons,
+			`UserNotDropped PostgreSQL user "owner" owns objects; set spec.userDeletion.reassignOwnedTo to drop it`))
+	})
+}
+
+func TestReconcilePostgresLogicalReplication(t *testing.T) {
+	ctx := context.Background()
+
+	primary := &corev1.Pod{}
+	primary.Namespace, primary.Name = "ns1", "hippo-00-abcd-0"
+	primary.Annotations = map[string]string{"status": `{"role":"primary"}`}
+	primary.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{primary}},
+	}}
+
+	newCluster := func() *v1beta1.PostgresCluster {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Namespace, cluster.Name = "ns1", "hippo"
+		require.UnmarshalInto(t, &cluster.Spec, `{
+			publications: [{ name: changes, database: app }],
+			subscriptions: [{
+				name: orders, database: app, publications: [sales],
+				source: { clusterName: rhino, user: repl },
+			}],
+		}`)
+		return cluster
+	}
+
+	sourceCluster := &v1beta1.PostgresCluster{}
+	sourceCluster.Namespace, sourceCluster.Name = "ns1", "rhino"
+
+	source := &corev1.Secret{}
+	source.Namespace, source.Name = "ns1", "rhino-pguser-repl"
+	source.Data = map[string][]byte{
+		"host":     []byte("rhino-primary.ns1.svc"),
+		"port":     []byte("5432"),
+		"user":     []byte("repl"),
+		"password": []byte("secret'"),
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		r := &Reconciler{PodExec: func(
+			context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			panic("should not be called")
+		}}
+
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Status.LogicalReplicationRevision = "abc"
+		cluster.Status.Subscriptions = []v1beta1.PostgresSubscriptionStatus{{Name: "old"}}
+		cluster.Status.SubscriptionsObservedTime = &metav1.Time{Time: time.Now()}
+
+		requeue, err := r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Duration(0))
+		assert.Equal(t, cluster.Status.LogicalReplicationRevision, "")
+		assert.Assert(t, cluster.Status.Subscriptions == nil)
+		assert.Assert(t, cluster.Status.SubscriptionsObservedTime == nil)
+	})
+
+	t.Run("NoPrimary", func(t *testing.T) {
+		r := &Reconciler{PodExec: func(
+			context.Context, string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			panic("should not be called")
+		}}
+
+		cluster := newCluster()
+		requeue, err := r.reconcilePostgresLogicalReplication(ctx, cluster, &observedInstances{})
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Duration(0))
+	})
+
+	t.Run("MissingReplicaIdentity", func(t *testing.T) {
+		var scripts []string
+		unidentified := "table public.events in database app\n"
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{
+			Recorder: recorder,
+			PodExec: func(
+				_ context.Context, _, _, _ string, stdin io.Reader, stdout, _ io.Writer, _ ...string,
+			) error {
+				b, err := io.ReadAll(stdin)
+				scripts = append(scripts, string(b))
+				if strings.Contains(string(b), `CREATE PUBLICATION`) {
+					_, _ = io.WriteString(stdout, unidentified)
+				}
+				return err
+			},
+		}
+
+		cluster := newCluster()
+		cluster.Spec.Subscriptions = nil
+
+		requeue, err := r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, requeue > 0 && requeue <= time.Hour)
+		assert.Equal(t, len(scripts), 1)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MissingReplicaIdentity)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Message,
+			"Publications of all tables wait for these to have a replica identity: table public.events in database app")
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "MissingReplicaIdentity")
+
+		// The revision is recorded, so nothing happens until the next hour.
+		revision := cluster.Status.LogicalReplicationRevision
+		assert.Assert(t, revision != "")
+
+		scripts = nil
+		requeue, err = r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, requeue > 0 && requeue <= time.Hour)
+		assert.Equal(t, len(scripts), 0, "expected no SQL")
+		assert.Equal(t, len(recorder.Events), 1, "expected no repeated events")
+
+		t.Run("Resolved", func(t *testing.T) {
+			unidentified = ""
+			cluster.Status.LogicalReplicationRevision = "retry"
+
+			_, err := r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+			assert.NilError(t, err)
+			assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+				v1beta1.MissingReplicaIdentity) == nil)
+
+			// The revision no longer depends on the hour.
+			assert.Assert(t, cluster.Status.LogicalReplicationRevision != revision)
+			scripts = nil
+			requeue, err := r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+			assert.NilError(t, err)
+			assert.Equal(t, requeue, time.Duration(0))
+			assert.Equal(t, len(scripts), 0, "expected no SQL")
+		})
+	})
+
+	t.Run("SourceMissing", func(t *testing.T) {
+		var scripts []string
+		recorder := events.NewRecorder(t, runtime.Scheme)
+		r := &Reconciler{
+			Client:   fake.NewClientBuilder().WithScheme(runtime.Scheme).Build(),
+			Recorder: recorder,
+			PodExec: func(
+				_ context.Context, _, _, _ string, stdin io.Reader, _, _ io.Writer, _ ...string,
+			) error {
+				b, err := io.ReadAll(stdin)
+				scripts = append(scripts, string(b))
+				return err
+			},
+		}
+
+		cluster := newCluster()
+		requeue, err := r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, time.Minute)
+
+		// Publications are written; the subscription is not.
+		assert.Equal(t, len(scripts), 2)
+		assert.Assert(t, cmp.Contains(scripts[0], `CREATE PUBLICATION`))
+		assert.Assert(t, cmp.Contains(scripts[1], `pg_stat_subscription`))
+		assert.Assert(t, cluster.Status.LogicalReplicationRevision != "")
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Type, "Warning")
+		assert.Equal(t, recorder.Events[0].Reason, "SubscriptionSourceUnavailable")
+		assert.Equal(t, recorder.Events[0].Note,
+			`Unable to find the password of user "repl" in PostgresCluster "rhino" for subscription "orders"`)
+
+		// No subscription is found, but another call soon after does not query.
+		assert.Assert(t, cluster.Status.Subscriptions == nil)
+		assert.Assert(t, cluster.Status.SubscriptionsObservedTime != nil)
+
+		scripts = nil
+		requeue, err = r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, requeue > 0 && requeue <= time.Minute)
+		assert.Equal(t, len(scripts), 0, "expected no SQL")
+	})
+
+	t.Run("Subscription", func(t *testing.T) {
+		var scripts []string
+		r := &Reconciler{
+			Client: fake.NewClientBuilder().WithScheme(runtime.Scheme).
+				WithObjects(sourceCluster.DeepCopy(), source.DeepCopy()).Build(),
+			Recorder: events.NewRecorder(t, runtime.Scheme),
+			PodExec: func(
+				_ context.Context, namespace, pod, container string,
+				stdin io.Reader, stdout, _ io.Writer, _ ...string,
+			) error {
+				assert.Equal(t, namespace, "ns1")
+				assert.Equal(t, pod, "hippo-00-abcd-0")
+				assert.Equal(t, container, "database")
+
+				b, err := io.ReadAll(stdin)
+				scripts = append(scripts, string(b))
+
+				if strings.Contains(string(b), `pg_stat_subscription`) {
+					_, _ = io.WriteString(stdout, `
+{"database":"app","name":"orders","enabled":true,"running":true,"synchronizing":1,"lag_seconds":3.7}
+{"database":"app","name":"unlisted","enabled":true,"running":true,"synchronizing":0,"lag_seconds":1}
+`)
+				}
+				return err
+			},
+		}
+
+		cluster := newCluster()
+		requeue, err := r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, requeue, replicationObservationInterval)
+
+		assert.Equal(t, len(scripts), 3)
+		assert.Assert(t, cmp.Contains(scripts[1], `CREATE SUBSCRIPTION`))
+		assert.Assert(t, cmp.Contains(scripts[1],
+			`"connection":"dbname='app' host='rhino-primary.ns1.svc' password='secret\\'' port='5432' sslmode='require' user='repl'"`))
+
+		assert.Equal(t, len(cluster.Status.Subscriptions), 1)
+		status := cluster.Status.Subscriptions[0]
+		assert.Equal(t, status.Name, "orders")
+		assert.Equal(t, status.Database, "app")
+		assert.Equal(t, status.State, v1beta1.PostgresSubscriptionSynchronizing)
+		assert.DeepEqual(t, status.LagSeconds, initialize.Int64(3))
+		assert.Assert(t, status.ObservedTime != nil)
+
+		t.Run("Unchanged", func(t *testing.T) {
+			scripts = nil
+			requeue, err := r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+			assert.NilError(t, err)
+			assert.Assert(t, requeue > 0 && requeue <= replicationObservationInterval)
+			assert.Equal(t, len(scripts), 0, "expected no SQL")
+		})
+
+		t.Run("Changed", func(t *testing.T) {
+			scripts = nil
+			cluster.Spec.Subscriptions[0].Enabled = initialize.Bool(false)
+
+			_, err := r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+			assert.NilError(t, err)
+			assert.Equal(t, len(scripts), 3, "expected writes and a query")
+			assert.Assert(t, cmp.Contains(scripts[1], `"enabled":false`))
+		})
+	})
+
+	t.Run("SecretKeyRef", func(t *testing.T) {
+		sourceCluster := sourceCluster.DeepCopy()
+		require.UnmarshalInto(t, &sourceCluster.Spec.Users, `[{
+			name: repl, password: { secretKeyRef: { name: vault, key: repl-password } },
+		}]`)
+
+		// The user Secret of the source holds only a verifier of the password.
+		source := source.DeepCopy()
+		delete(source.Data, "password")
+		source.Data["verifier"] = []byte("SCRAM-SHA-256$4096:salt$stored:server")
+
+		provided := &corev1.Secret{}
+		provided.Namespace, provided.Name = "ns1", "vault"
+		provided.Data = map[string][]byte{"repl-password": []byte("from-vault")}
+
+		var scripts []string
+		r := &Reconciler{
+			Client: fake.NewClientBuilder().WithScheme(runtime.Scheme).
+				WithObjects(sourceCluster, source, provided).Build(),
+			Recorder: events.NewRecorder(t, runtime.Scheme),
+			PodExec: func(
+				_ context.Context, _, _, _ string, stdin io.Reader, _, _ io.Writer, _ ...string,
+			) error {
+				b, err := io.ReadAll(stdin)
+				scripts = append(scripts, string(b))
+				return err
+			},
+		}
+
+		cluster := newCluster()
+		_, err := r.reconcilePostgresLogicalReplication(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, len(scripts), 3)
+		assert.Assert(t, cmp.Contains(scripts[1],
+			`"connection":"dbname='app' host='rhino-primary.ns1.svc' password='from-vault' port='5432' sslmode='require' user='repl'"`))
+
+		t.Run("Verifier", func(t *testing.T) {
+			recorder := events.NewRecorder(t, runtime.Scheme)
+			provided := provided.DeepCopy()
+			provided.Data["repl-password"] = []byte("SCRAM-SHA-256$4096:salt$stored:server")
+
+			r := &Reconciler{
+				Client: fake.NewClientBuilder().WithScheme(runtime.Scheme).
+					WithObjects(sourceCluster.DeepCopy(), source.DeepCopy(), provided).Build(),
+				Recorder: recorder,
+				PodExec:  r.PodExec,
+			}
+
+			requeue, err := r.reconcilePostgresLogicalReplication(ctx, newCluster(), instances)
+			assert.NilError(t, err)
+			assert.Equal(t, requeue, time.Minute)
+			assert.Equal(t, len(recorder.Events), 1)
+			assert.Equal(t, recorder.Events[0].Reason, "SubscriptionSourceUnavailable")
+		})
+	})
+}
@@ -7,14 +7,17 @@ package postgrescluster
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // watchPods returns a handler.EventHandler for Pods.
@@ -63,6 +66,16 @@ func (*Reconciler) watchPods() handler.Funcs {
 
 			oldAnnotations := e.ObjectOld.GetAnnotations()
 			newAnnotations := e.ObjectNew.GetAnnotations()
+
+			// Queue an event when the reinitialize annotation is added or changes.
+			if len(cluster) != 0 && oldAnnotations[naming.PatroniReinitialize] != newAnnotations[naming.PatroniReinitialize] {
+				q.Add(reconcile.Request{NamespacedName: client.ObjectKey{
+					Namespace: e.ObjectNew.GetNamespace(),
+					Name:      cluster,
+				}})
+				return
+			}
+
 			// If the suggested-pgdata-pvc-size annotation is added or changes, reconcile.
 			if len(cluster) != 0 && oldAnnotations["suggested-pgdata-pvc-size"] != newAnnotations["suggested-pgdata-pvc-size"] {
 				q.Add(reconcile.Request{NamespacedName: client.ObjectKey{
@@ -74,3 +87,76 @@ func (*Reconciler) watchPods() handler.Funcs {
 		},
 	}
 }
+
+// +kubebuilder:rbac:groups="",resources="nodes",verbs={list,watch}
+// +kubebuilder:rbac:groups="",resources="pods",verbs={list}
+
+// watchNodes returns a handler.EventHandler for Nodes. It queues clusters
+// with a primary on a Node that becomes unschedulable.
+func (r *Reconciler) watchNodes() handler.Funcs {
+	return handler.Funcs{
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			oldNode, _ := e.ObjectOld.(*corev1.Node)
+			newNode, _ := e.ObjectNew.(*corev1.Node)
+
+			if oldNode == nil || newNode == nil ||
+				oldNode.Spec.Unschedulable || !newNode.Spec.Unschedulable {
+				return
+			}
+
+			var pods corev1.PodList
+			if err := r.Client.List(ctx, &pods,
+				client.MatchingLabels{naming.LabelRole: naming.RolePatroniLeader},
+				client.HasLabels{naming.LabelCluster},
+			); err != nil {
+				return
+			}
+			for i := range pods.Items {
+				if pods.Items[i].Spec.NodeName == newNode.Name {
+					q.Add(reconcile.Request{NamespacedName: client.ObjectKey{
+						Namespace: pods.Items[i].Namespace,
+						Name:      pods.Items[i].Labels[naming.LabelCluster],
+					}})
+				}
+			}
+		},
+	}
+}
+
+// watchSecrets returns a handler.EventHandler for Secrets that hold the
+// passwords of PostgreSQL users.
+func (r *Reconciler) watchSecrets() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, secret client.Object) []reconcile.Request {
+		return runtime.Requests(r.findPostgresClustersForSecret(ctx, client.ObjectKeyFromObject(secret))...)
+	})
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={list}
+
+// findPostgresClustersForSecret returns PostgresClusters that have a user
+// whose password is in secret.
+func (r *Reconciler) findPostgresClustersForSecret(
+	ctx context.Context, secret client.ObjectKey,
+) []*v1beta1.PostgresCluster {
+	var matching []*v1beta1.PostgresCluster
+	var clusters v1beta1.PostgresClusterList
+
+	// NOTE: If this becomes slow due to a large number of PostgresClusters in
+	// a single namespace, we can configure the [manager.Manager] field indexer
+	// and pass a [fields.Selector] here.
+	// - https://book.kubebuilder.io/reference/watching-resources/externally-managed.html
+	if err := r.Client.List(ctx, &clusters, &client.ListOptions{
+		Namespace: secret.Namespace,
+	}); err == nil {
+		for i := range clusters.Items {
+			for _, user := range clusters.Items[i].Spec.Users {
+				if user.Password != nil && user.Password.SecretKeyRef != nil &&
+					user.Password.SecretKeyRef.Name == secret.Name {
+					matching = append(matching, &clusters.Items[i])
+					break
+				}
+			}
+		}
+	}
+	return matching
+}
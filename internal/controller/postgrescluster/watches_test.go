@@ -12,9 +12,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestWatchPodsUpdate(t *testing.T) {
@@ -181,4 +186,113 @@ func TestWatchPodsUpdate(t *testing.T) {
 		},
 	}, queue)
 	assert.Equal(t, queue.Len(), 1)
+
+	// Pod annotation to reinitialize; reconcile.
+	update(ctx, event.UpdateEvent{
+		ObjectOld: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"postgres-operator.crunchydata.com/cluster": "octopus",
+				},
+			},
+		},
+		ObjectNew: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"postgres-operator.crunchydata.com/trigger-reinitialize": "now",
+				},
+				Labels: map[string]string{
+					"postgres-operator.crunchydata.com/cluster": "octopus",
+				},
+			},
+		},
+	}, queue)
+	assert.Equal(t, queue.Len(), 2)
+}
+
+func TestWatchNodesUpdate(t *testing.T) {
+	ctx := context.Background()
+	queue := &controllertest.Queue{TypedInterface: workqueue.NewTyped[reconcile.Request]()}
+
+	pod := func(namespace, name, cluster, role, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace, Name: name,
+				Labels: map[string]string{
+					"postgres-operator.crunchydata.com/cluster": cluster,
+					"postgres-operator.crunchydata.com/role":    role,
+				},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+	}
+
+	reconciler := &Reconciler{Client: fake.NewClientBuilder().WithObjects(
+		pod("ns1", "hippo-0", "hippo", "master", "n1"),
+		pod("ns1", "hippo-1", "hippo", "replica", "n2"),
+		pod("ns2", "rhino-0", "rhino", "replica", "n1"),
+		pod("ns2", "rhino-1", "rhino", "master", "n2"),
+	).Build()}
+
+	update := reconciler.watchNodes().UpdateFunc
+	assert.Assert(t, update != nil)
+
+	node := func(unschedulable bool) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		}
+	}
+
+	// Still schedulable; no reconcile.
+	update(ctx, event.UpdateEvent{ObjectOld: node(false), ObjectNew: node(false)}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	// Still unschedulable; no reconcile.
+	update(ctx, event.UpdateEvent{ObjectOld: node(true), ObjectNew: node(true)}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	// Cordoned; reconcile the cluster with a primary on the Node.
+	update(ctx, event.UpdateEvent{ObjectOld: node(false), ObjectNew: node(true)}, queue)
+	assert.Equal(t, queue.Len(), 1)
+
+	item, _ := queue.Get()
+	assert.Equal(t, item, reconcile.Request{NamespacedName: client.ObjectKey{
+		Namespace: "ns1", Name: "hippo",
+	}})
+}
+
+func TestFindPostgresClustersForSecret(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := func(namespace, name, secret string) *v1beta1.PostgresCluster {
+		cluster := v1beta1.NewPostgresCluster()
+		cluster.Namespace, cluster.Name = namespace, name
+		cluster.Spec.Users = []v1beta1.PostgresUserSpec{{Name: "generated"}, {
+			Name: "provided",
+			Password: &v1beta1.PostgresPasswordSpec{
+				SecretKeyRef: &v1beta1.SecretKeyRef{Name: secret, Key: "password"},
+			},
+		}}
+		return cluster
+	}
+
+	reconciler := &Reconciler{}
+	reconciler.Client = fake.NewClientBuilder().WithScheme(runtime.Scheme).WithObjects(
+		cluster("ns1", "hippo", "external"),
+		cluster("ns1", "rhino", "other"),
+		cluster("ns2", "zebra", "external"),
+	).Build()
+
+	var names []string
+	for _, cluster := range reconciler.findPostgresClustersForSecret(ctx,
+		client.ObjectKey{Namespace: "ns1", Name: "external"},
+	) {
+		names = append(names, cluster.Name)
+	}
+	assert.DeepEqual(t, names, []string{"hippo"})
+
+	assert.Assert(t, len(reconciler.findPostgresClustersForSecret(ctx,
+		client.ObjectKey{Namespace: "ns1", Name: "unknown"},
+	)) == 0)
 }
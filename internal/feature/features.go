@@ -90,6 +90,10 @@ const (
 	// Support custom sidecars for pgBouncer Pods
 	PGBouncerSidecars = "PGBouncerSidecars"
 
+	// Watch Nodes to switch over when the Node of a primary is cordoned;
+	// requires permission to watch Nodes across the cluster
+	SwitchoverOnCordon = "SwitchoverOnCordon"
+
 	// Adjust PGUpgrade parallelism according to CPU resources
 	PGUpgradeCPUConcurrency = "PGUpgradeCPUConcurrency"
 
@@ -114,6 +118,7 @@ func NewGate() MutableGate {
 		OpenTelemetryMetrics:    {Default: false, PreRelease: featuregate.Alpha},
 		PGBouncerSidecars:       {Default: false, PreRelease: featuregate.Alpha},
 		PGUpgradeCPUConcurrency: {Default: true, PreRelease: featuregate.Beta},
+		SwitchoverOnCordon:      {Default: false, PreRelease: featuregate.Alpha},
 		TablespaceVolumes:       {Default: false, PreRelease: featuregate.Alpha},
 		VolumeSnapshots:         {Default: false, PreRelease: featuregate.Alpha},
 	}); err != nil {
@@ -14,6 +14,35 @@ const (
 	// Patroni Switchover (or Failover).
 	PatroniSwitchover = annotationPrefix + "trigger-switchover"
 
+	// PatroniInstanceHash is an annotation on instance Pods that holds a hash
+	// of the Patroni and PostgreSQL settings specific to their instance set.
+	// Pods restart when these settings change.
+	PatroniInstanceHash = annotationPrefix + "patroni-instance-hash"
+
+	// PatroniReinitialize is the annotation added to an instance Pod to discard
+	// and recreate its PostgreSQL data directory. Each time the value changes,
+	// the instance is reinitialized once. It is never applied to the primary.
+	PatroniReinitialize = annotationPrefix + "trigger-reinitialize"
+
+	// PostgresPasswordRotation is the annotation added to a PostgresCluster to
+	// generate new passwords for its PostgreSQL users that have a rotation
+	// policy. Passwords are rotated each time the value changes.
+	PostgresPasswordRotation = annotationPrefix + "rotate-passwords"
+
+	// PostgresPrimaryRedirect is the annotation added to a PostgresCluster to
+	// send connections through its primary Service and PgBouncer to the primary
+	// of another PostgresCluster in the same namespace. The value is the name
+	// of that other cluster. PGUpgrade sets this after a logical replication
+	// upgrade; remove it to send connections back to this cluster.
+	PostgresPrimaryRedirect = annotationPrefix + "redirect-primary"
+
+	// PostgresStopConnections is the annotation added to a PostgresCluster to
+	// stop connections from everyone except superusers to some of its
+	// databases. The value is a JSON array of database names. The connection
+	// limit of each is restored when it is no longer listed and the cluster
+	// is not redirected by [PostgresPrimaryRedirect].
+	PostgresStopConnections = annotationPrefix + "stop-connections"
+
 	// PGBackRestBackup is the annotation that is added to a PostgresCluster to initiate a manual
 	// backup.  The value of the annotation will be a unique identifier for a backup Job (e.g. a
 	// timestamp), which will be stored in the PostgresCluster status to properly track completion
@@ -16,7 +16,12 @@ func TestAnnotationsValid(t *testing.T) {
 	assert.Assert(t, nil == validation.IsQualifiedName(AutoCreateUserSchemaAnnotation))
 	assert.Assert(t, nil == validation.IsQualifiedName(CrunchyBridgeClusterAdoptionAnnotation))
 	assert.Assert(t, nil == validation.IsQualifiedName(Finalizer))
+	assert.Assert(t, nil == validation.IsQualifiedName(PatroniInstanceHash))
+	assert.Assert(t, nil == validation.IsQualifiedName(PatroniReinitialize))
 	assert.Assert(t, nil == validation.IsQualifiedName(PatroniSwitchover))
+	assert.Assert(t, nil == validation.IsQualifiedName(PostgresPasswordRotation))
+	assert.Assert(t, nil == validation.IsQualifiedName(PostgresPrimaryRedirect))
+	assert.Assert(t, nil == validation.IsQualifiedName(PostgresStopConnections))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestBackup))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestBackupJobCompletion))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestConfigHash))
@@ -28,6 +28,10 @@ const (
 	// LabelData is used to identify Pods and Volumes store Postgres data.
 	LabelData = labelPrefix + "data"
 
+	// LabelLoadBalance is used to identify instance Pods that receive connections
+	// through the replica Service when some instance sets are excluded from it.
+	LabelLoadBalance = labelPrefix + "load-balance"
+
 	// LabelMoveJob is used to identify a directory move Job.
 	LabelMoveJob = labelPrefix + "move-job"
 
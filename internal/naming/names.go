@@ -238,6 +238,15 @@ func ClusterPrimaryService(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// ClusterPrimaryPodDisruptionBudget returns the ObjectMeta necessary to lookup
+// the PodDisruptionBudget that protects the PostgreSQL primary instance.
+func ClusterPrimaryPodDisruptionBudget(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-primary",
+	}
+}
+
 // ClusterReplicaService returns the ObjectMeta necessary to lookup the Service
 // that exposes PostgreSQL replica instances.
 func ClusterReplicaService(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -432,6 +441,16 @@ func PatroniScope(cluster *v1beta1.PostgresCluster) string {
 	return cluster.Name + "-ha"
 }
 
+// PatroniSynchronousState returns the ObjectMeta necessary to lookup the
+// ConfigMap or Endpoints Patroni creates for cluster to record its synchronous
+// replicas. See Patroni DCS "sync_path".
+func PatroniSynchronousState(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      PatroniScope(cluster) + "-sync",
+	}
+}
+
 // PatroniTrigger returns the ObjectMeta necessary to lookup the ConfigMap or
 // Endpoints Patroni creates for cluster to initiate a controlled change of the
 // leader. See Patroni DCS "failover_path".
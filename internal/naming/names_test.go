@@ -86,6 +86,7 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 			{"ClusterPGBouncer", ClusterPGBouncer(cluster)},
 			{"PatroniDistributedConfiguration", PatroniDistributedConfiguration(cluster)},
 			{"PatroniLeaderConfigMap", PatroniLeaderConfigMap(cluster)},
+			{"PatroniSynchronousState", PatroniSynchronousState(cluster)},
 			{"PatroniTrigger", PatroniTrigger(cluster)},
 			{"PGBackRestConfig", PGBackRestConfig(cluster)},
 		})
@@ -117,6 +118,7 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 		testUniqueAndValid(t, []test{
 			{"InstanceSetPDB", InstanceSet(cluster, instanceSet)},
 			{"PGBouncerPDB", ClusterPGBouncer(cluster)},
+			{"PrimaryPDB", ClusterPrimaryPodDisruptionBudget(cluster)},
 		})
 	})
 
@@ -180,6 +182,7 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 			// Patroni can use Endpoints which relate directly to a Service.
 			{"PatroniDistributedConfiguration", PatroniDistributedConfiguration(cluster)},
 			{"PatroniLeaderEndpoints", PatroniLeaderEndpoints(cluster)},
+			{"PatroniSynchronousState", PatroniSynchronousState(cluster)},
 			{"PatroniTrigger", PatroniTrigger(cluster)},
 		})
 	})
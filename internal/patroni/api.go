@@ -168,6 +168,29 @@ func (exec Executor) RestartPendingMembers(ctx context.Context, role, scope stri
 	return err
 }
 
+// ReinitializeMember asks the Patroni member in scope to discard its data
+// directory and create it again using its replica methods. It returns true
+// when Patroni accepts the request; the work happens in the background.
+// Patroni refuses to reinitialize the leader.
+func (exec Executor) ReinitializeMember(ctx context.Context, scope, member string) (bool, error) {
+	var stdout, stderr bytes.Buffer
+
+	// The following exits zero when it is able to read the DCS and communicate
+	// with the Patroni HTTP API. It prints the result of calling "POST /reinitialize"
+	// on the member.
+	// - https://patroni.readthedocs.io/en/latest/patronictl.html#patronictl-reinit
+	err := exec(ctx, nil, &stdout, &stderr,
+		"patronictl", "reinit", "--force", scope, member)
+
+	log := logging.FromContext(ctx)
+	log.V(1).Info("reinitialized member",
+		"stdout", stdout.String(),
+		"stderr", stderr.String(),
+	)
+
+	return strings.Contains(stdout.String(), "Success: reinitialize"), err
+}
+
 // GetTimeline gets the patronictl status and returns the timeline,
 // currently the only information required by PGO.
 // Returns zero if it runs into errors or cannot find a running Leader pod
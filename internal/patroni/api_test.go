@@ -226,6 +226,52 @@ func TestExecutorRestartPendingMembers(t *testing.T) {
 	assert.Equal(t, expected, actual, "should call exec")
 }
 
+func TestExecutorReinitializeMember(t *testing.T) {
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("oop")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.DeepEqual(t, command, strings.Fields(
+				`patronictl reinit --force shoe-scope sock-member`,
+			))
+			assert.Assert(t, stdin == nil, "expected no stdin, got %T", stdin)
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			return expected
+		}
+
+		_, actual := Executor(exec).ReinitializeMember(
+			context.Background(), "shoe-scope", "sock-member")
+
+		assert.Equal(t, expected, actual, "should call exec")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		success, err := Executor(func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = stdout.Write([]byte("Success: reinitialize for member sock-member\n"))
+			return nil
+		}).ReinitializeMember(context.Background(), "shoe-scope", "sock-member")
+
+		assert.NilError(t, err)
+		assert.Assert(t, success)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		success, err := Executor(func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = stdout.Write([]byte("Failed: reinitialize for member sock-member, status code=503, (Cluster has no leader)\n"))
+			return nil
+		}).ReinitializeMember(context.Background(), "shoe-scope", "sock-member")
+
+		assert.NilError(t, err)
+		assert.Assert(t, !success)
+	})
+}
+
 func TestExecutorGetTimeline(t *testing.T) {
 	t.Run("Error", func(t *testing.T) {
 		expected := errors.New("bang")
@@ -5,6 +5,7 @@
 package patroni
 
 import (
+	"cmp"
 	"fmt"
 	"path"
 	"strings"
@@ -227,6 +228,12 @@ func DynamicConfiguration(
 		"use_slots": false,
 	}
 
+	// Patroni ignores permanent slots unless it manages replication slots.
+	// - https://patroni.readthedocs.io/en/latest/dynamic_configuration.html
+	if len(spec.Patroni.Slots) > 0 {
+		postgresql["use_slots"] = true
+	}
+
 	// When TDE is configured, override the pg_rewind binary name to point
 	// to the wrapper script.
 	if config.FetchKeyCommand(spec) != "" {
@@ -259,6 +266,46 @@ func DynamicConfiguration(
 	// PostgreSQL v10 and earlier require superuser access over the network.
 	postgresql["use_pg_rewind"] = spec.PostgresVersion > 10
 
+	// Synchronous replication is configured at the top level. Quorum commit
+	// requires Patroni v4 or later.
+	// - https://patroni.readthedocs.io/en/latest/replication_modes.html
+	if sync := spec.Patroni.Synchronous; sync != nil {
+		if sync.Mode == v1beta1.PatroniSynchronousModeQuorum {
+			root["synchronous_mode"] = "quorum"
+		} else {
+			root["synchronous_mode"] = true
+		}
+		if sync.Standbys != nil {
+			root["synchronous_node_count"] = *sync.Standbys
+		}
+		if sync.Strict != nil {
+			root["synchronous_mode_strict"] = *sync.Strict
+		}
+	}
+
+	// Permanent slots are configured at the top level. Patroni creates them on
+	// the primary and copies logical slots to replicas so that they remain
+	// after a switchover or failover.
+	// - https://patroni.readthedocs.io/en/latest/dynamic_configuration.html
+	if len(spec.Patroni.Slots) > 0 {
+		slots, _ := root["slots"].(map[string]any)
+		if slots == nil {
+			slots = make(map[string]any)
+		}
+		for _, slot := range spec.Patroni.Slots {
+			if slot.Type == v1beta1.PatroniSlotTypeLogical {
+				slots[slot.Name] = map[string]any{
+					"type":     "logical",
+					"database": slot.Database,
+					"plugin":   cmp.Or(slot.Plugin, "pgoutput"),
+				}
+			} else {
+				slots[slot.Name] = map[string]any{"type": "physical"}
+			}
+		}
+		root["slots"] = slots
+	}
+
 	if spec.Standby != nil && spec.Standby.Enabled {
 		standby, _ := root["standby_cluster"].(map[string]any)
 		if standby == nil {
@@ -450,10 +497,11 @@ func instanceConfigFiles(cluster, instance *corev1.ConfigMap) []corev1.VolumePro
 	}
 }
 
-// instanceYAML returns Patroni settings that apply to instance.
+// instanceYAML returns Patroni settings that apply to instance. The replicateFrom
+// argument is the Patroni member, if any, from which instance should stream changes.
 func instanceYAML(
 	cluster *v1beta1.PostgresCluster, instance *v1beta1.PostgresInstanceSetSpec,
-	pgbackrestReplicaCreateCommand []string,
+	replicateFrom string, pgbackrestReplicaCreateCommand []string,
 ) (string, error) {
 	root := map[string]any{
 		// Missing here is "name" which cannot be known until the instance Pod is
@@ -479,11 +527,26 @@ func instanceYAML(
 		},
 
 		"tags": map[string]any{
-			// TODO(cbandy): "nofailover"
 			// TODO(cbandy): "nosync"
 		},
 	}
 
+	// Patroni reads member tags from local configuration only.
+	// - https://patroni.readthedocs.io/en/latest/yaml_configuration.html#tags
+	if tags := instance.Tags; tags != nil {
+		rendered := root["tags"].(map[string]any)
+
+		if tags.ReplicateFrom != "" && replicateFrom != "" {
+			rendered["replicatefrom"] = replicateFrom
+		}
+		if tags.NoFailoverOrDelay() {
+			rendered["nofailover"] = true
+		}
+		if tags.NoLoadBalanceOrDelay() {
+			rendered["noloadbalance"] = true
+		}
+	}
+
 	postgresql := map[string]any{
 		// TODO(cbandy): "bin_dir"
 
@@ -505,6 +568,28 @@ func instanceYAML(
 	}
 	root["postgresql"] = postgresql
 
+	// Patroni merges these with PostgreSQL parameters in DCS and prefers the
+	// local values. It ignores parameters that must match across the cluster,
+	// but the API rejects those anyway.
+	// - https://patroni.readthedocs.io/en/latest/patroni_configuration.html
+	if config := instance.Config; config != nil && len(config.Parameters) > 0 {
+		parameters := postgres.NewParameterSet()
+		for k, v := range config.Parameters {
+			parameters.Add(k, v.String())
+		}
+		postgresql["parameters"] = parameters.AsMap()
+	}
+
+	// Patroni writes "recovery_conf" settings only on replicas. Send the value
+	// in milliseconds, the base unit of this PostgreSQL parameter.
+	// - https://patroni.readthedocs.io/en/latest/yaml_configuration.html#postgresql
+	if instance.Tags.Delayed() {
+		postgresql["recovery_conf"] = map[string]any{
+			"recovery_min_apply_delay": fmt.Sprintf("%dms",
+				instance.Tags.ReplicationDelay.AsDuration().Milliseconds()),
+		}
+	}
+
 	// The "basebackup" replica method is configured differently from others.
 	// Patroni prepends "--" before it calls `pg_basebackup`.
 	// - https://github.com/zalando/patroni/blob/v2.0.2/patroni/postgresql/bootstrap.py#L45
@@ -15,8 +15,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/yaml"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
@@ -291,6 +293,82 @@ func TestDynamicConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "synchronous: spec overrides input",
+			spec: `{
+				patroni: {
+					dynamicConfiguration: {
+						synchronous_mode: false,
+						synchronous_node_count: 5,
+					},
+					synchronous: {
+						mode: Priority,
+						standbys: 2,
+						strict: true,
+					},
+				},
+			}`,
+			expected: map[string]any{
+				"loop_wait":               int32(10),
+				"ttl":                     int32(30),
+				"synchronous_mode":        true,
+				"synchronous_mode_strict": true,
+				"synchronous_node_count":  int32(2),
+				"postgresql": map[string]any{
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
+		{
+			name: "synchronous: quorum",
+			spec: `{
+				patroni: {
+					synchronous: { mode: Quorum },
+				},
+			}`,
+			expected: map[string]any{
+				"loop_wait":        int32(10),
+				"ttl":              int32(30),
+				"synchronous_mode": "quorum",
+				"postgresql": map[string]any{
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
+		{
+			name: "slots: spec overrides input",
+			spec: `{
+				patroni: {
+					dynamicConfiguration: {
+						slots: {
+							debezium: { type: physical },
+							other: { type: physical },
+						},
+					},
+					slots: [
+						{ name: debezium, type: Logical, database: app },
+						{ name: standby, type: Physical },
+						{ name: wal2json, type: Logical, database: app, plugin: wal2json },
+					],
+				},
+			}`,
+			expected: map[string]any{
+				"loop_wait": int32(10),
+				"ttl":       int32(30),
+				"slots": map[string]any{
+					"debezium": map[string]any{"type": "logical", "database": "app", "plugin": "pgoutput"},
+					"other":    map[string]any{"type": "physical"},
+					"standby":  map[string]any{"type": "physical"},
+					"wal2json": map[string]any{"type": "logical", "database": "app", "plugin": "wal2json"},
+				},
+				"postgresql": map[string]any{
+					"use_pg_rewind": true,
+					"use_slots":     true,
+				},
+			},
+		},
 		{
 			name: "postgresql: wrong-type is ignored",
 			spec: `{
@@ -678,7 +756,7 @@ func TestInstanceYAML(t *testing.T) {
 	cluster := &v1beta1.PostgresCluster{Spec: v1beta1.PostgresClusterSpec{PostgresVersion: 12}}
 	instance := new(v1beta1.PostgresInstanceSetSpec)
 
-	data, err := instanceYAML(cluster, instance, nil)
+	data, err := instanceYAML(cluster, instance, "", nil)
 	assert.NilError(t, err)
 	assert.Equal(t, data, strings.Trim(`
 # Generated by postgres-operator. DO NOT EDIT.
@@ -701,7 +779,7 @@ restapi: {}
 tags: {}
 	`, "\t\n")+"\n")
 
-	dataWithReplicaCreate, err := instanceYAML(cluster, instance, []string{"some", "backrest", "cmd"})
+	dataWithReplicaCreate, err := instanceYAML(cluster, instance, "", []string{"some", "backrest", "cmd"})
 	assert.NilError(t, err)
 	assert.Equal(t, dataWithReplicaCreate, strings.Trim(`
 # Generated by postgres-operator. DO NOT EDIT.
@@ -741,7 +819,7 @@ tags: {}
 		},
 	}
 
-	datawithTDE, err := instanceYAML(cluster, instance, nil)
+	datawithTDE, err := instanceYAML(cluster, instance, "", nil)
 	assert.NilError(t, err)
 	assert.Equal(t, datawithTDE, strings.Trim(`
 # Generated by postgres-operator. DO NOT EDIT.
@@ -765,6 +843,70 @@ restapi: {}
 tags: {}
 	`, "\t\n")+"\n")
 
+	t.Run("Tags", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{Spec: v1beta1.PostgresClusterSpec{PostgresVersion: 12}}
+		cluster.Status.Patroni.SystemIdentifier = "6952526174828511264"
+		instance := new(v1beta1.PostgresInstanceSetSpec)
+		instance.Tags = &v1beta1.PatroniTagsSpec{
+			ReplicateFrom: "reports",
+			NoFailover:    initialize.Bool(true),
+		}
+
+		data, err := instanceYAML(cluster, instance, "hippo-reports-abcd-0", nil)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(data, `
+tags:
+  nofailover: true
+  replicatefrom: hippo-reports-abcd-0
+`))
+
+		// There is no tag until the other set has an instance.
+		data, err = instanceYAML(cluster, instance, "", nil)
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(data, "replicatefrom"))
+		assert.Assert(t, !strings.Contains(data, "recovery_conf"))
+	})
+
+	t.Run("Parameters", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{Spec: v1beta1.PostgresClusterSpec{PostgresVersion: 12}}
+		cluster.Status.Patroni.SystemIdentifier = "6952526174828511264"
+		instance := new(v1beta1.PostgresInstanceSetSpec)
+		instance.Config = &v1beta1.PostgresInstanceConfigSpec{
+			Parameters: map[string]intstr.IntOrString{
+				"Work_Mem":             intstr.FromString("64MB"),
+				"max_parallel_workers": intstr.FromInt32(16),
+			},
+		}
+
+		data, err := instanceYAML(cluster, instance, "", nil)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(data, `
+  parameters:
+    max_parallel_workers: "16"
+    work_mem: 64MB
+`))
+	})
+
+	t.Run("ReplicationDelay", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{Spec: v1beta1.PostgresClusterSpec{PostgresVersion: 12}}
+		cluster.Status.Patroni.SystemIdentifier = "6952526174828511264"
+		instance := new(v1beta1.PostgresInstanceSetSpec)
+		instance.Tags = new(v1beta1.PatroniTagsSpec)
+		instance.Tags.ReplicationDelay, err = v1beta1.NewDuration("2h")
+		assert.NilError(t, err)
+
+		data, err := instanceYAML(cluster, instance, "", nil)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(data, `
+  recovery_conf:
+    recovery_min_apply_delay: 7200000ms
+`))
+		assert.Assert(t, cmp.Contains(data, `
+tags:
+  nofailover: true
+  noloadbalance: true
+`))
+	})
 }
 
 func TestPGBackRestCreateReplicaCommand(t *testing.T) {
@@ -774,7 +916,7 @@ func TestPGBackRestCreateReplicaCommand(t *testing.T) {
 	cluster := new(v1beta1.PostgresCluster)
 	instance := new(v1beta1.PostgresInstanceSetSpec)
 
-	data, err := instanceYAML(cluster, instance, []string{"some", "backrest", "cmd"})
+	data, err := instanceYAML(cluster, instance, "", []string{"some", "backrest", "cmd"})
 	assert.NilError(t, err)
 
 	var parsed struct {
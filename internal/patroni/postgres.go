@@ -81,3 +81,28 @@ func PostgresParameters(spec *v1beta1.PatroniSpec) *postgres.ParameterSet {
 
 	return result
 }
+
+// SlotParameters sets the parameters required by the permanent slots in cluster.
+func SlotParameters(cluster *v1beta1.PostgresCluster, outParameters *postgres.Parameters) {
+	var logical bool
+	if cluster.Spec.Patroni != nil {
+		for _, slot := range cluster.Spec.Patroni.Slots {
+			logical = logical || slot.Type == v1beta1.PatroniSlotTypeLogical
+		}
+	}
+	if !logical {
+		return
+	}
+
+	// Replicas report the oldest transaction they need so the primary keeps
+	// the catalog rows that logical slots on replicas depend on. Patroni
+	// turns this on when there are permanent logical slots, too.
+	// - https://www.postgresql.org/docs/current/runtime-config-replication.html#GUC-HOT-STANDBY-FEEDBACK
+	outParameters.Default.Add("hot_standby_feedback", "on")
+
+	// PostgreSQL v17 can also synchronize logical slots to replicas, but only
+	// when primary_conninfo includes a dbname. Patroni does not set one, so
+	// leave "sync_replication_slots" off and rely on Patroni to copy permanent
+	// logical slots instead.
+	// - https://www.postgresql.org/docs/current/logicaldecoding-explanation.html#LOGICALDECODING-REPLICATION-SLOTS-SYNCHRONIZATION
+}
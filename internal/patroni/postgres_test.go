@@ -9,6 +9,7 @@ import (
 
 	"gotest.tools/v3/assert"
 
+	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -195,3 +196,33 @@ func TestPostgresParameters(t *testing.T) {
 		})
 	})
 }
+
+func TestSlotParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	parameters := postgres.Parameters{Default: postgres.NewParameterSet()}
+
+	// Nothing without logical slots.
+	SlotParameters(cluster, &parameters)
+	require.UnmarshalInto(t, &cluster.Spec, `{
+		postgresVersion: 16,
+		patroni: { slots: [{ name: standby, type: Physical }] },
+	}`)
+	SlotParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{})
+
+	cluster.Spec.Patroni.Slots = append(cluster.Spec.Patroni.Slots, v1beta1.PatroniSlotSpec{
+		Name: "debezium", Type: v1beta1.PatroniSlotTypeLogical, Database: "app",
+	})
+	SlotParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{
+		"hot_standby_feedback": "on",
+	})
+
+	// PostgreSQL 17 does not synchronize failover slots by default. That
+	// requires a dbname in primary_conninfo that Patroni does not set.
+	cluster.Spec.PostgresVersion = 17
+	SlotParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Default.AsMap(), map[string]string{
+		"hot_standby_feedback": "on",
+	})
+}
@@ -6,6 +6,9 @@ package patroni
 
 import (
 	"context"
+	"encoding/json"
+	"maps"
+	"slices"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -44,10 +47,13 @@ func ClusterConfigMap(ctx context.Context,
 	return err
 }
 
-// InstanceConfigMap populates the shared ConfigMap with fields needed to run Patroni.
+// InstanceConfigMap populates the shared ConfigMap with fields needed to run
+// Patroni. The inReplicateFrom argument is the Patroni member, if any, from
+// which the instance should stream changes.
 func InstanceConfigMap(ctx context.Context,
 	inCluster *v1beta1.PostgresCluster,
 	inInstanceSpec *v1beta1.PostgresInstanceSetSpec,
+	inReplicateFrom string,
 	outInstanceConfigMap *corev1.ConfigMap,
 ) error {
 	var err error
@@ -57,7 +63,7 @@ func InstanceConfigMap(ctx context.Context,
 	command := pgbackrest.ReplicaCreateCommand(inCluster, inInstanceSpec)
 
 	outInstanceConfigMap.Data[configMapFileKey], err = instanceYAML(
-		inCluster, inInstanceSpec, command)
+		inCluster, inInstanceSpec, inReplicateFrom, command)
 
 	return err
 }
@@ -219,3 +225,51 @@ func PodRequiresRestart(pod metav1.Object) bool {
 	status := pod.GetAnnotations()["status"]
 	return strings.Contains(status, `"pending_restart":true`)
 }
+
+// PodStatus is the state of a Patroni member as written to its Pod.
+type PodStatus struct {
+	Role           string `json:"role"`
+	State          string `json:"state"`
+	Timeline       int64  `json:"timeline"`
+	PendingRestart bool   `json:"pending_restart"`
+
+	// The WAL position of this member. Patroni reports the current write
+	// position of a primary and the received or replayed position of a
+	// replica, whichever is further.
+	XLogLocation *int64 `json:"xlog_location"`
+
+	// The names of parameters that require a restart, sorted. Patroni v3.3
+	// and later report these in a "pending_restart_reason" object.
+	PendingRestartParameters []string `json:"-"`
+}
+
+// GetPodStatus returns the state Patroni reports for pod and whether or not
+// it could be read. Older versions of Patroni report the "master" role; this
+// returns "primary" instead.
+func GetPodStatus(pod metav1.Object) (PodStatus, bool) {
+	var status PodStatus
+	if pod == nil {
+		return status, false
+	}
+
+	// TODO(cbandy): This works only when using Kubernetes for DCS.
+
+	// - https://github.com/zalando/patroni/blob/v3.1.1/patroni/ha.py#L296
+	// - https://patroni.readthedocs.io/en/latest/releases.html#version-3-3-0
+	var reasons struct {
+		Parameters map[string]json.RawMessage `json:"pending_restart_reason"`
+	}
+	value, ok := pod.GetAnnotations()["status"]
+	if ok && (json.Unmarshal([]byte(value), &status) != nil ||
+		json.Unmarshal([]byte(value), &reasons) != nil) {
+		ok = false
+	}
+	if len(reasons.Parameters) > 0 {
+		status.PendingRestartParameters = slices.Sorted(maps.Keys(reasons.Parameters))
+	}
+	if status.Role == "master" {
+		status.Role = "primary"
+	}
+
+	return status, ok
+}
@@ -12,6 +12,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
@@ -95,15 +96,15 @@ func TestInstanceConfigMap(t *testing.T) {
 	cluster := new(v1beta1.PostgresCluster)
 	instance := new(v1beta1.PostgresInstanceSetSpec)
 	config := new(corev1.ConfigMap)
-	data, _ := instanceYAML(cluster, instance, nil)
+	data, _ := instanceYAML(cluster, instance, "", nil)
 
-	assert.NilError(t, InstanceConfigMap(ctx, cluster, instance, config))
+	assert.NilError(t, InstanceConfigMap(ctx, cluster, instance, "", config))
 
 	assert.DeepEqual(t, config.Data["patroni.yaml"], data)
 
 	// No change when called again.
 	before := config.DeepCopy()
-	assert.NilError(t, InstanceConfigMap(ctx, cluster, instance, config))
+	assert.NilError(t, InstanceConfigMap(ctx, cluster, instance, "", config))
 	assert.DeepEqual(t, config, before)
 }
 
@@ -287,3 +288,44 @@ func TestPodRequiresRestart(t *testing.T) {
 	pod.Annotations["status"] = `{"pending_restart":true}`
 	assert.Assert(t, PodRequiresRestart(pod))
 }
+
+func TestGetPodStatus(t *testing.T) {
+	// No object
+	_, ok := GetPodStatus(nil)
+	assert.Assert(t, !ok)
+
+	// No annotations
+	pod := &corev1.Pod{}
+	_, ok = GetPodStatus(pod)
+	assert.Assert(t, !ok)
+
+	// Unexpected value
+	pod.Annotations = map[string]string{"status": `{"timeline":"mystery"}`}
+	_, ok = GetPodStatus(pod)
+	assert.Assert(t, !ok)
+
+	// Replica
+	pod.Annotations["status"] = `{"conn_url":"postgres://x","role":"replica","state":"streaming","timeline":3,"xlog_location":100}`
+	status, ok := GetPodStatus(pod)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, status, PodStatus{
+		Role: "replica", State: "streaming", Timeline: 3, XLogLocation: initialize.Int64(100),
+	})
+
+	// Older primary
+	pod.Annotations["status"] = `{"role":"master","state":"running","timeline":4,"pending_restart":true}`
+	status, ok = GetPodStatus(pod)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, status, PodStatus{Role: "primary", State: "running", Timeline: 4, PendingRestart: true})
+
+	// Pending restart reasons
+	pod.Annotations["status"] = `{"role":"replica","state":"streaming","timeline":4,"pending_restart":true,` +
+		`"pending_restart_reason":{"shared_buffers":{"old_value":"128MB","new_value":"1GB"},` +
+		`"max_connections":{"old_value":"100","new_value":"200"}}}`
+	status, ok = GetPodStatus(pod)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, status, PodStatus{
+		Role: "replica", State: "streaming", Timeline: 4, PendingRestart: true,
+		PendingRestartParameters: []string{"max_connections", "shared_buffers"},
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/crunchydata/postgres-operator/internal/collector"
 	"github.com/crunchydata/postgres-operator/internal/naming"
@@ -156,9 +157,18 @@ func clusterINI(ctx context.Context, cluster *v1beta1.PostgresCluster) string {
 	// When that database does not exist, the client will experience timeouts
 	// or errors that sound like PgBouncer misconfiguration.
 	// - https://github.com/pgbouncer/pgbouncer/issues/352
+	//
+	// When cluster is redirected to another cluster, connect to the primary
+	// Service of that cluster. PgBouncer closes connections to the old host
+	// as they are released.
+	primary := naming.ClusterPrimaryService(cluster)
+	if other := cluster.GetAnnotations()[naming.PostgresPrimaryRedirect]; other != "" {
+		primary = naming.ClusterPrimaryService(&v1beta1.PostgresCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: other},
+		})
+	}
 	databases := iniValueSet{
-		"*": fmt.Sprintf("host=%s port=%d",
-			naming.ClusterPrimaryService(cluster).Name, postgresPort),
+		"*": fmt.Sprintf("host=%s port=%d", primary.Name, postgresPort),
 	}
 
 	// Replace the above with any specified databases.
@@ -131,6 +131,17 @@ app = mode=rad
 		cluster.Spec.Proxy.PGBouncer.Config.Global["conffile"] = "too-far"
 		assert.Assert(t, !strings.Contains(clusterINI(ctx, cluster), "too-far"))
 	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Proxy.PGBouncer.Config.Databases = nil
+		cluster.Annotations = map[string]string{
+			"postgres-operator.crunchydata.com/redirect-primary": "other",
+		}
+
+		assert.Assert(t, cmp.Contains(clusterINI(ctx, cluster),
+			"\n[databases]\n* = host=other-primary port=9999\n"))
+	})
 }
 
 func TestPodConfigFiles(t *testing.T) {
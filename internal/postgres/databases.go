@@ -8,8 +8,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // CreateDatabasesInPostgreSQL calls exec to create databases that do not exist
@@ -70,3 +74,306 @@ SELECT pg_catalog.format('CREATE DATABASE %I',
 
 	return err
 }
+
+// WriteDatabasesInPostgreSQL calls exec to create databases that do not exist
+// in PostgreSQL and to set the connection limit of databases that do. The
+// owner of each database is set by [WriteDatabaseOwnersInPostgreSQL].
+func WriteDatabasesInPostgreSQL(
+	ctx context.Context, exec Executor, databases []v1beta1.PostgresDatabaseSpec,
+) error {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the database specifications.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range databases {
+		spec := databases[i]
+		value := map[string]any{"database": spec.Name}
+
+		if spec.ConnectionLimit != nil {
+			value["connection_limit"] = *spec.ConnectionLimit
+		}
+		if spec.Encoding != "" {
+			value["encoding"] = spec.Encoding
+		}
+		if spec.ICULocale != "" {
+			value["icu_locale"] = spec.ICULocale
+		}
+		if spec.Locale != "" {
+			value["locale"] = spec.Locale
+		}
+		if spec.Template != "" {
+			value["template"] = spec.Template
+		}
+
+		if err == nil {
+			err = encoder.Encode(value)
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Create databases that do not already exist. Missing keys are NULL, and
+	// "concat_ws" skips NULL arguments, so only specified options are included.
+	// - https://www.postgresql.org/docs/current/sql-createdatabase.html
+	// - https://www.postgresql.org/docs/current/functions-json.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.concat_ws(' ',
+       pg_catalog.format('CREATE DATABASE %I', spec.database),
+       'TEMPLATE ' || pg_catalog.quote_ident(spec.template),
+       'ENCODING ' || pg_catalog.quote_literal(spec.encoding),
+       'LOCALE ' || pg_catalog.quote_literal(spec.locale),
+       'LOCALE_PROVIDER icu ICU_LOCALE ' || pg_catalog.quote_literal(spec.icu_locale))
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, template text, encoding text, locale text, icu_locale text)
+ WHERE NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_database WHERE datname = spec.database)
+ ORDER BY input.id
+\gexec
+`)
+
+	// Set the connection limit of databases that differ from the specification.
+	// - https://www.postgresql.org/docs/current/sql-alterdatabase.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('ALTER DATABASE %I WITH CONNECTION LIMIT %s',
+       spec.database, spec.connection_limit)
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, connection_limit integer)
+  JOIN pg_catalog.pg_database ON datname = spec.database
+ WHERE datconnlimit <> spec.connection_limit
+ ORDER BY input.id
+\gexec
+`)
+
+	stdout, stderr, err := exec.Exec(ctx, &sql,
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("wrote PostgreSQL databases", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// WriteDatabaseOwnersInPostgreSQL calls exec to change the owner of databases
+// that differ from their specification. Owners must exist before this is called.
+func WriteDatabaseOwnersInPostgreSQL(
+	ctx context.Context, exec Executor, databases []v1beta1.PostgresDatabaseSpec,
+) error {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the database specifications.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range databases {
+		if databases[i].Owner != "" && err == nil {
+			err = encoder.Encode(map[string]any{
+				"database": databases[i].Name,
+				"owner":    databases[i].Owner,
+			})
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Change the owner of databases that exist. A role that does not exist is
+	// NULL and always differs, so the statement fails rather than skips.
+	// - https://www.postgresql.org/docs/current/sql-alterdatabase.html
+	// - https://www.postgresql.org/docs/current/functions-info.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('ALTER DATABASE %I OWNER TO %I', spec.database, spec.owner)
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data) AS spec (database text, owner text)
+  JOIN pg_catalog.pg_database ON datname = spec.database
+ WHERE datdba IS DISTINCT FROM pg_catalog.to_regrole(pg_catalog.quote_ident(spec.owner))
+ ORDER BY input.id
+\gexec
+`)
+
+	stdout, stderr, err := exec.Exec(ctx, &sql,
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("wrote PostgreSQL database owners", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// StopConnectionsInPostgreSQL calls exec to set the connection limit of
+// databases to zero so that only superusers can connect to them. It returns
+// the previous limit of each database that changed.
+func StopConnectionsInPostgreSQL(
+	ctx context.Context, exec Executor, databases []string,
+) (map[string]int32, error) {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the database names.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range databases {
+		if err == nil {
+			err = encoder.Encode(map[string]any{
+				"database": databases[i],
+			})
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Print the previous limits as one JSON object then change them in the
+	// same transaction. A limit of zero stops everyone except superusers from
+	// connecting, and sessions cannot override it.
+	// - https://www.postgresql.org/docs/current/sql-alterdatabase.html
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	_, _ = sql.WriteString(`
+BEGIN;
+\pset format unaligned
+\pset tuples_only on
+CREATE TEMPORARY VIEW stopping AS
+SELECT input.id, datname, datconnlimit
+  FROM input
+  JOIN pg_catalog.pg_database
+       ON datname = pg_catalog.json_extract_path_text(input.data, 'database')
+ WHERE datconnlimit <> 0;
+
+SELECT pg_catalog.json_object_agg(datname, datconnlimit) FROM stopping;
+
+SELECT pg_catalog.format('ALTER DATABASE %I WITH CONNECTION LIMIT 0', datname)
+  FROM stopping ORDER BY id
+\gexec
+COMMIT;
+`)
+
+	var stdout, stderr string
+	if err == nil {
+		stdout, stderr, err = exec.Exec(ctx, &sql,
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("stopped connections to PostgreSQL databases", "stdout", stdout, "stderr", stderr)
+	}
+
+	var previous map[string]int32
+	if line := strings.TrimSpace(stdout); err == nil && line != "" {
+		err = json.Unmarshal([]byte(line), &previous)
+	}
+
+	return previous, err
+}
+
+// RestoreConnectionsInPostgreSQL calls exec to set the connection limit of
+// databases that are still stopped back to their previous limits. See
+// [StopConnectionsInPostgreSQL].
+func RestoreConnectionsInPostgreSQL(
+	ctx context.Context, exec Executor, limits map[string]int32,
+) error {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the previous limits.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for _, database := range slices.Sorted(maps.Keys(limits)) {
+		if err == nil {
+			err = encoder.Encode(map[string]any{
+				"database":         database,
+				"connection_limit": limits[database],
+			})
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Leave any limit that changed since connections were stopped.
+	// - https://www.postgresql.org/docs/current/sql-alterdatabase.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('ALTER DATABASE %I WITH CONNECTION LIMIT %s',
+       spec.database, spec.connection_limit)
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, connection_limit integer)
+  JOIN pg_catalog.pg_database ON datname = spec.database
+ WHERE datconnlimit = 0
+ ORDER BY input.id
+\gexec
+`)
+
+	var stdout, stderr string
+	if err == nil {
+		stdout, stderr, err = exec.Exec(ctx, &sql,
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("restored connections to PostgreSQL databases", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
@@ -13,7 +13,9 @@ import (
 
 	"gotest.tools/v3/assert"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestCreateDatabasesInPostgreSQL(t *testing.T) {
@@ -90,3 +92,334 @@ SELECT pg_catalog.format('CREATE DATABASE %I',
 		assert.Equal(t, calls, 1)
 	})
 }
+
+func TestWriteDatabasesInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		assert.Equal(t, expected, WriteDatabasesInPostgreSQL(ctx, exec, nil))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), strings.TrimLeft(`
+SET search_path TO '';
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+\.
+
+SELECT pg_catalog.concat_ws(' ',
+       pg_catalog.format('CREATE DATABASE %I', spec.database),
+       'TEMPLATE ' || pg_catalog.quote_ident(spec.template),
+       'ENCODING ' || pg_catalog.quote_literal(spec.encoding),
+       'LOCALE ' || pg_catalog.quote_literal(spec.locale),
+       'LOCALE_PROVIDER icu ICU_LOCALE ' || pg_catalog.quote_literal(spec.icu_locale))
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, template text, encoding text, locale text, icu_locale text)
+ WHERE NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_database WHERE datname = spec.database)
+ ORDER BY input.id
+\gexec
+
+SELECT pg_catalog.format('ALTER DATABASE %I WITH CONNECTION LIMIT %s',
+       spec.database, spec.connection_limit)
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, connection_limit integer)
+  JOIN pg_catalog.pg_database ON datname = spec.database
+ WHERE datconnlimit <> spec.connection_limit
+ ORDER BY input.id
+\gexec
+`, "\n"))
+			return nil
+		}
+
+		assert.NilError(t, WriteDatabasesInPostgreSQL(ctx, exec, nil))
+		assert.Equal(t, calls, 1)
+
+		assert.NilError(t, WriteDatabasesInPostgreSQL(ctx, exec, []v1beta1.PostgresDatabaseSpec{}))
+		assert.Equal(t, calls, 2)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"database":"white space"}
+{"connection_limit":-1,"database":"all","encoding":"UTF8","icu_locale":"en-US","locale":"C.UTF-8","template":"template0"}
+{"connection_limit":0,"database":"none"}
+\.
+`))
+			return nil
+		}
+
+		assert.NilError(t, WriteDatabasesInPostgreSQL(ctx, exec,
+			[]v1beta1.PostgresDatabaseSpec{
+				{Name: "white space", Owner: "ignored"},
+				{
+					Name:            "all",
+					ConnectionLimit: initialize.Int32(-1),
+					Encoding:        "UTF8",
+					ICULocale:       "en-US",
+					Locale:          "C.UTF-8",
+					Template:        "template0",
+				},
+				{Name: "none", ConnectionLimit: initialize.Int32(0)},
+			},
+		))
+		assert.Equal(t, calls, 1)
+	})
+}
+
+func TestWriteDatabaseOwnersInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		assert.Equal(t, expected, WriteDatabaseOwnersInPostgreSQL(ctx, exec, nil))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), strings.TrimLeft(`
+SET search_path TO '';
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+\.
+
+SELECT pg_catalog.format('ALTER DATABASE %I OWNER TO %I', spec.database, spec.owner)
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data) AS spec (database text, owner text)
+  JOIN pg_catalog.pg_database ON datname = spec.database
+ WHERE datdba IS DISTINCT FROM pg_catalog.to_regrole(pg_catalog.quote_ident(spec.owner))
+ ORDER BY input.id
+\gexec
+`, "\n"))
+			return nil
+		}
+
+		assert.NilError(t, WriteDatabaseOwnersInPostgreSQL(ctx, exec, nil))
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"database":"app","owner":"white space"}
+\.
+`))
+			return nil
+		}
+
+		assert.NilError(t, WriteDatabaseOwnersInPostgreSQL(ctx, exec,
+			[]v1beta1.PostgresDatabaseSpec{
+				{Name: "app", Owner: "white space"},
+				{Name: "skipped"},
+			},
+		))
+		assert.Equal(t, calls, 1)
+	})
+}
+
+func TestStopConnectionsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		_, err := StopConnectionsInPostgreSQL(ctx, exec, nil)
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), strings.TrimLeft(`
+SET search_path TO '';
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+\.
+
+BEGIN;
+\pset format unaligned
+\pset tuples_only on
+CREATE TEMPORARY VIEW stopping AS
+SELECT input.id, datname, datconnlimit
+  FROM input
+  JOIN pg_catalog.pg_database
+       ON datname = pg_catalog.json_extract_path_text(input.data, 'database')
+ WHERE datconnlimit <> 0;
+
+SELECT pg_catalog.json_object_agg(datname, datconnlimit) FROM stopping;
+
+SELECT pg_catalog.format('ALTER DATABASE %I WITH CONNECTION LIMIT 0', datname)
+  FROM stopping ORDER BY id
+\gexec
+COMMIT;
+`, "\n"))
+			return nil
+		}
+
+		previous, err := StopConnectionsInPostgreSQL(ctx, exec, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, previous == nil)
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"database":"app"}
+{"database":"white space"}
+\.
+`))
+			_, _ = stdout.Write([]byte(`{"app":-1,"white space":10}` + "\n"))
+			return nil
+		}
+
+		previous, err := StopConnectionsInPostgreSQL(ctx, exec, []string{"app", "white space"})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, previous, map[string]int32{"app": -1, "white space": 10})
+		assert.Equal(t, calls, 1)
+	})
+}
+
+func TestRestoreConnectionsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		assert.Equal(t, expected, RestoreConnectionsInPostgreSQL(ctx, exec, nil))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), strings.TrimLeft(`
+SET search_path TO '';
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+\.
+
+SELECT pg_catalog.format('ALTER DATABASE %I WITH CONNECTION LIMIT %s',
+       spec.database, spec.connection_limit)
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, connection_limit integer)
+  JOIN pg_catalog.pg_database ON datname = spec.database
+ WHERE datconnlimit = 0
+ ORDER BY input.id
+\gexec
+`, "\n"))
+			return nil
+		}
+
+		assert.NilError(t, RestoreConnectionsInPostgreSQL(ctx, exec, nil))
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"connection_limit":-1,"database":"app"}
+{"connection_limit":10,"database":"other"}
+\.
+`), "expected sorted by name")
+			return nil
+		}
+
+		assert.NilError(t, RestoreConnectionsInPostgreSQL(ctx, exec,
+			map[string]int32{"other": 10, "app": -1}))
+		assert.Equal(t, calls, 1)
+	})
+}
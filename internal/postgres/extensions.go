@@ -0,0 +1,190 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// ExtensionParameters sets the parameters required by the extensions in cluster.
+func ExtensionParameters(cluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	libraries := strings.Split(outParameters.Mandatory.Value("shared_preload_libraries"), ",")
+	load := func(extensions []v1beta1.PostgresExtensionSpec) {
+		for _, extension := range extensions {
+			for _, library := range extension.SharedPreloadLibraries {
+				if !slices.Contains(libraries, library) {
+					libraries = append(libraries, library)
+
+					// Load the shared library when PostgreSQL starts.
+					// PostgreSQL must be restarted when changing this value.
+					// - https://www.postgresql.org/docs/current/runtime-config-client.html
+					outParameters.Mandatory.AppendToList("shared_preload_libraries", library)
+				}
+			}
+		}
+	}
+
+	load(cluster.Spec.Extensions)
+	for _, database := range cluster.Spec.Databases {
+		load(database.Extensions)
+	}
+}
+
+// WriteExtensionsInPostgreSQL calls exec to create and update extensions in
+// every database that allows connections. Extensions in cluster are installed
+// in every database while those in databases are installed in only the named
+// database. It returns the installed version of each of these extensions.
+func WriteExtensionsInPostgreSQL(
+	ctx context.Context, exec Executor,
+	cluster []v1beta1.PostgresExtensionSpec, databases []v1beta1.PostgresDatabaseSpec,
+) ([]v1beta1.PostgresExtensionStatus, error) {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Do not wait for changes to be replicated. [Since PostgreSQL v9.1]
+	// - https://www.postgresql.org/docs/current/runtime-config-wal.html
+	_, _ = sql.WriteString(`SET synchronous_commit = LOCAL;`)
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the extension specifications.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	encode := func(database string, spec v1beta1.PostgresExtensionSpec) {
+		value := map[string]any{"name": spec.Name}
+
+		if database != "" {
+			value["database"] = database
+		}
+		if spec.Schema != "" {
+			value["schema"] = spec.Schema
+		}
+		if spec.Version != "" {
+			value["version"] = spec.Version
+		}
+
+		if err == nil {
+			err = encoder.Encode(value)
+		}
+	}
+
+	for i := range cluster {
+		encode("", cluster[i])
+	}
+	for i := range databases {
+		for j := range databases[i].Extensions {
+			encode(databases[i].Name, databases[i].Extensions[j])
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Choose one specification of each extension for the current database.
+	// Those for a particular database sort ahead of those without one.
+	// - https://www.postgresql.org/docs/current/sql-select.html#SQL-DISTINCT
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY VIEW target AS
+SELECT DISTINCT ON (spec.name) input.id, spec.name, spec.schema, spec.version
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, name text, schema text, version text)
+ WHERE spec.database IS NULL OR spec.database = pg_catalog.current_database()
+ ORDER BY spec.name, spec.database NULLS LAST;
+`)
+
+	// Create extensions that do not already exist. Missing keys are NULL, and
+	// "concat_ws" skips NULL arguments, so only specified options are included.
+	// The "search_path" above is empty, so extensions that can go in any schema
+	// are put in "public" unless another is specified. Extensions that require
+	// a particular schema are put there.
+	// - https://www.postgresql.org/docs/current/sql-createextension.html
+	// - https://www.postgresql.org/docs/current/view-pg-available-extension-versions.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.concat_ws(' ',
+       pg_catalog.format('CREATE EXTENSION %I', target.name),
+       'SCHEMA ' || pg_catalog.quote_ident(COALESCE(target.schema, (
+              SELECT 'public' WHERE NOT EXISTS (
+                     SELECT 1 FROM pg_catalog.pg_available_extension_versions AS available
+                      WHERE available.name = target.name AND available.schema IS NOT NULL)))),
+       'VERSION ' || pg_catalog.quote_literal(target.version))
+  FROM target
+ WHERE NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_extension WHERE extname = target.name)
+ ORDER BY target.id
+\gexec
+`)
+
+	// Update extensions that differ from their specified version or, when
+	// there is none, from the default version of the installed image.
+	// - https://www.postgresql.org/docs/current/sql-alterextension.html
+	// - https://www.postgresql.org/docs/current/view-pg-available-extensions.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.concat_ws(' ',
+       pg_catalog.format('ALTER EXTENSION %I UPDATE', target.name),
+       'TO ' || pg_catalog.quote_literal(target.version))
+  FROM target
+  JOIN pg_catalog.pg_extension ON extname = target.name
+  LEFT JOIN pg_catalog.pg_available_extensions AS available ON available.name = target.name
+ WHERE extversion IS DISTINCT FROM COALESCE(target.version, available.default_version)
+ ORDER BY target.id
+\gexec
+`)
+
+	// Print the installed version of each extension as one line of JSON.
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	_, _ = sql.WriteString(`
+\pset format unaligned
+\pset tuples_only on
+SELECT pg_catalog.json_build_object(
+       'database', pg_catalog.current_database(),
+       'name', extname, 'version', extversion)
+  FROM target
+  JOIN pg_catalog.pg_extension ON extname = target.name
+ ORDER BY target.id;
+`)
+
+	var stdout, stderr string
+	if err == nil {
+		stdout, stderr, err = exec.ExecInAllDatabases(ctx, sql.String(),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("wrote PostgreSQL extensions", "stdout", stdout, "stderr", stderr)
+	}
+
+	var installed []v1beta1.PostgresExtensionStatus
+	for scanner := bufio.NewScanner(strings.NewReader(stdout)); err == nil && scanner.Scan(); {
+		if line := scanner.Bytes(); len(bytes.TrimSpace(line)) > 0 {
+			var extension v1beta1.PostgresExtensionStatus
+			if err = json.Unmarshal(line, &extension); err == nil {
+				installed = append(installed, extension)
+			}
+		}
+	}
+
+	return installed, err
+}
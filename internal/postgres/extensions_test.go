@@ -0,0 +1,190 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestExtensionParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	parameters := Parameters{Mandatory: NewParameterSet()}
+
+	// No libraries when there are no extensions.
+	ExtensionParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{})
+
+	require.UnmarshalInto(t, &cluster.Spec, `{
+		extensions: [
+			{ name: pg_stat_statements, sharedPreloadLibraries: [pg_stat_statements] },
+			{ name: pg_cron, sharedPreloadLibraries: [pg_cron] },
+		],
+		databases: [
+			{ name: app, extensions: [
+				{ name: pg_partman, sharedPreloadLibraries: [pg_partman_bgw, pg_cron] },
+			] },
+		],
+	}`)
+
+	// Appended without duplicates.
+	parameters.Mandatory.Add("shared_preload_libraries", "pgaudit,pg_cron")
+	ExtensionParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
+		"shared_preload_libraries": "pgaudit,pg_cron,pg_stat_statements,pg_partman_bgw",
+	})
+}
+
+func TestWriteExtensionsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+
+			assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+				`SELECT datname FROM pg_catalog.pg_database`,
+			), "expected all databases and templates")
+
+			return expected
+		}
+
+		_, err := WriteExtensionsInPostgreSQL(ctx, exec, nil, nil)
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), strings.TrimLeft(`
+SET synchronous_commit = LOCAL;SET search_path TO '';
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+\.
+
+CREATE TEMPORARY VIEW target AS
+SELECT DISTINCT ON (spec.name) input.id, spec.name, spec.schema, spec.version
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, name text, schema text, version text)
+ WHERE spec.database IS NULL OR spec.database = pg_catalog.current_database()
+ ORDER BY spec.name, spec.database NULLS LAST;
+
+SELECT pg_catalog.concat_ws(' ',
+       pg_catalog.format('CREATE EXTENSION %I', target.name),
+       'SCHEMA ' || pg_catalog.quote_ident(COALESCE(target.schema, (
+              SELECT 'public' WHERE NOT EXISTS (
+                     SELECT 1 FROM pg_catalog.pg_available_extension_versions AS available
+                      WHERE available.name = target.name AND available.schema IS NOT NULL)))),
+       'VERSION ' || pg_catalog.quote_literal(target.version))
+  FROM target
+ WHERE NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_extension WHERE extname = target.name)
+ ORDER BY target.id
+\gexec
+
+SELECT pg_catalog.concat_ws(' ',
+       pg_catalog.format('ALTER EXTENSION %I UPDATE', target.name),
+       'TO ' || pg_catalog.quote_literal(target.version))
+  FROM target
+  JOIN pg_catalog.pg_extension ON extname = target.name
+  LEFT JOIN pg_catalog.pg_available_extensions AS available ON available.name = target.name
+ WHERE extversion IS DISTINCT FROM COALESCE(target.version, available.default_version)
+ ORDER BY target.id
+\gexec
+
+\pset format unaligned
+\pset tuples_only on
+SELECT pg_catalog.json_build_object(
+       'database', pg_catalog.current_database(),
+       'name', extname, 'version', extversion)
+  FROM target
+  JOIN pg_catalog.pg_extension ON extname = target.name
+ ORDER BY target.id;
+`, "\n"))
+			return nil
+		}
+
+		installed, err := WriteExtensionsInPostgreSQL(ctx, exec, nil, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, installed == nil)
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"name":"pg_stat_statements"}
+{"name":"pg_cron","schema":"white space","version":"1.6"}
+{"database":"app","name":"pg_partman"}
+\.
+`))
+
+			_, _ = io.WriteString(stdout, ""+
+				`{"database" : "app", "name" : "pg_partman", "version" : "5.2.4"}`+"\n"+
+				"\n"+
+				`{"database" : "postgres", "name" : "pg_cron", "version" : "1.6"}`+"\n")
+			return nil
+		}
+
+		installed, err := WriteExtensionsInPostgreSQL(ctx, exec,
+			[]v1beta1.PostgresExtensionSpec{
+				{Name: "pg_stat_statements"},
+				{Name: "pg_cron", Schema: "white space", Version: "1.6"},
+			},
+			[]v1beta1.PostgresDatabaseSpec{
+				{Name: "app", Extensions: []v1beta1.PostgresExtensionSpec{
+					{Name: "pg_partman"},
+				}},
+				{Name: "none"},
+			},
+		)
+		assert.NilError(t, err)
+		assert.Equal(t, calls, 1)
+		assert.DeepEqual(t, installed, []v1beta1.PostgresExtensionStatus{
+			{Database: "app", Name: "pg_partman", Version: "5.2.4"},
+			{Database: "postgres", Name: "pg_cron", Version: "1.6"},
+		})
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			_, _ = io.WriteString(stdout, "Output format is unaligned.\n")
+			return nil
+		}
+
+		_, err := WriteExtensionsInPostgreSQL(ctx, exec, nil, nil)
+		assert.ErrorContains(t, err, "invalid")
+	})
+}
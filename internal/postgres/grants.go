@@ -0,0 +1,308 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// grantsInput writes SQL to sql that fills a temporary table with the JSON
+// of users that have grants and defines a view, "target", of those users.
+func grantsInput(sql *bytes.Buffer, users []v1beta1.PostgresUserSpec) error {
+	var err error
+
+	// Fill a temporary table with the JSON of the user specifications.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+
+	encoder := json.NewEncoder(sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range users {
+		// The "postgres" user is a superuser; its privileges are not managed.
+		if users[i].Grants != nil && users[i].Name != "postgres" && err == nil {
+			err = encoder.Encode(map[string]any{
+				"databases": users[i].Databases,
+				"grants":    users[i].Grants,
+				"username":  users[i].Name,
+			})
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Look up the role of each user. Roles that do not exist are NULL.
+	// - https://www.postgresql.org/docs/current/functions-info.html
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY VIEW target AS
+SELECT input.id, spec.username, spec.databases, spec.grants,
+       pg_catalog.to_regrole(pg_catalog.quote_ident(spec.username)) AS role
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (username text, databases json, grants json);
+`)
+
+	return err
+}
+
+// WriteUserGrantsInPostgreSQL calls exec to grant the privileges specified for
+// users and to revoke privileges of the same kinds that are not specified.
+// Users without grants are ignored. Users must exist before this is called.
+// Privileges in schemas that do not exist are skipped; it returns a
+// description of each of those schemas.
+func WriteUserGrantsInPostgreSQL(
+	ctx context.Context, exec Executor, users []v1beta1.PostgresUserSpec,
+) ([]string, error) {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Quiet NOTICE messages about privileges that are already granted.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html
+	_, _ = sql.WriteString(`SET client_min_messages = WARNING;`)
+
+	// Do not wait for changes to be replicated. [Since PostgreSQL v9.1]
+	// - https://www.postgresql.org/docs/current/runtime-config-wal.html
+	_, _ = sql.WriteString(`SET synchronous_commit = LOCAL;`)
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	err = grantsInput(&sql, users)
+
+	// Role memberships and database privileges are stored in shared catalogs.
+	// Change them in a transaction so that other sessions see them together.
+	// - https://www.postgresql.org/docs/current/role-membership.html
+	// - https://www.postgresql.org/docs/current/sql-grant.html
+	// - https://www.postgresql.org/docs/current/sql-revoke.html
+	_, _ = sql.WriteString(`BEGIN;`)
+
+	// Grant specified role memberships.
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('GRANT %I TO %I', granted.rolname, target.username)
+  FROM target
+ CROSS JOIN pg_catalog.json_array_elements_text(
+       pg_catalog.json_extract_path(target.grants, 'roles')) AS granted (rolname)
+ ORDER BY target.id
+\gexec
+`)
+
+	// Revoke role memberships that are not specified. Memberships WITH ADMIN
+	// OPTION are kept; PostgreSQL 16 grants those to roles with CREATEROLE.
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('REVOKE %I FROM %I', granted.rolname, target.username)
+  FROM target
+  JOIN pg_catalog.pg_auth_members AS membership ON membership.member = target.role
+  JOIN pg_catalog.pg_roles AS granted ON granted.oid = membership.roleid
+ WHERE NOT membership.admin_option
+   AND granted.rolname NOT IN (
+       SELECT pg_catalog.json_array_elements_text(
+              pg_catalog.json_extract_path(target.grants, 'roles')))
+ ORDER BY target.id, granted.rolname
+\gexec
+`)
+
+	// Grant specified database privileges.
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('GRANT %s ON DATABASE %I TO %I',
+       privilege, spec.name, target.username)
+  FROM target
+ CROSS JOIN pg_catalog.json_to_recordset(
+       pg_catalog.json_extract_path(target.grants, 'databases'))
+       AS spec (name text, privileges json)
+ CROSS JOIN pg_catalog.json_array_elements_text(spec.privileges) AS privilege
+ ORDER BY target.id
+\gexec
+`)
+
+	// Revoke database privileges that are not specified. Privileges of the
+	// owner and of databases in the "databases" field are kept.
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('REVOKE %s ON DATABASE %I FROM %I',
+       acl.privilege_type, db.datname, target.username)
+  FROM target
+ CROSS JOIN pg_catalog.pg_database AS db
+ CROSS JOIN LATERAL pg_catalog.aclexplode(db.datacl) AS acl
+ WHERE acl.grantee = target.role AND db.datdba <> target.role
+   AND db.datname NOT IN (
+       SELECT pg_catalog.json_array_elements_text(target.databases))
+   AND NOT EXISTS (
+       SELECT 1 FROM pg_catalog.json_to_recordset(
+              pg_catalog.json_extract_path(target.grants, 'databases'))
+              AS spec (name text, privileges json)
+        WHERE spec.name = db.datname
+          AND acl.privilege_type IN (
+              SELECT pg_catalog.json_array_elements_text(spec.privileges)))
+ ORDER BY target.id, db.datname
+\gexec
+`)
+
+	// Commit (finish) the transaction.
+	_, _ = sql.WriteString(`COMMIT;`)
+
+	var stdout, stderr string
+	if err == nil {
+		stdout, stderr, err = exec.Exec(ctx, &sql,
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("wrote PostgreSQL role and database grants", "stdout", stdout, "stderr", stderr)
+	}
+
+	// Schema privileges and default privileges are stored in each database.
+	sql.Reset()
+	_, _ = sql.WriteString(`SET client_min_messages = WARNING;`)
+	_, _ = sql.WriteString(`SET synchronous_commit = LOCAL;`)
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	if err == nil {
+		err = grantsInput(&sql, users)
+	}
+
+	// Grant specified schema privileges in the current database. Schemas are
+	// often created by applications, so skip those that do not exist yet.
+	// - https://www.postgresql.org/docs/current/functions-info.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('GRANT %s ON SCHEMA %I TO %I',
+       privilege, spec.schema, target.username)
+  FROM target
+ CROSS JOIN pg_catalog.json_to_recordset(
+       pg_catalog.json_extract_path(target.grants, 'schemas'))
+       AS spec (database text, schema text, privileges json)
+ CROSS JOIN pg_catalog.json_array_elements_text(spec.privileges) AS privilege
+ WHERE spec.database = pg_catalog.current_database()
+   AND pg_catalog.to_regnamespace(pg_catalog.quote_ident(spec.schema)) IS NOT NULL
+ ORDER BY target.id
+\gexec
+`)
+
+	// Revoke schema privileges that are not specified. Privileges of the owner
+	// are kept.
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('REVOKE %s ON SCHEMA %I FROM %I',
+       acl.privilege_type, ns.nspname, target.username)
+  FROM target
+ CROSS JOIN pg_catalog.pg_namespace AS ns
+ CROSS JOIN LATERAL pg_catalog.aclexplode(ns.nspacl) AS acl
+ WHERE acl.grantee = target.role AND ns.nspowner <> target.role
+   AND NOT EXISTS (
+       SELECT 1 FROM pg_catalog.json_to_recordset(
+              pg_catalog.json_extract_path(target.grants, 'schemas'))
+              AS spec (database text, schema text, privileges json)
+        WHERE spec.database = pg_catalog.current_database()
+          AND spec.schema = ns.nspname
+          AND acl.privilege_type IN (
+              SELECT pg_catalog.json_array_elements_text(spec.privileges)))
+ ORDER BY target.id, ns.nspname
+\gexec
+`)
+
+	// Grant specified default privileges in the current database. Missing keys
+	// are NULL, and "concat_ws" skips NULL arguments.
+	// - https://www.postgresql.org/docs/current/sql-alterdefaultprivileges.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.concat_ws(' ',
+       pg_catalog.format('ALTER DEFAULT PRIVILEGES FOR ROLE %I', spec.role),
+       'IN SCHEMA ' || pg_catalog.quote_ident(spec.schema),
+       pg_catalog.format('GRANT %s ON %s TO %I',
+       privilege, spec."objectType", target.username))
+  FROM target
+ CROSS JOIN pg_catalog.json_to_recordset(
+       pg_catalog.json_extract_path(target.grants, 'defaultPrivileges'))
+       AS spec (database text, schema text, role text, "objectType" text, privileges json)
+ CROSS JOIN pg_catalog.json_array_elements_text(spec.privileges) AS privilege
+ WHERE spec.database = pg_catalog.current_database()
+   AND (spec.schema IS NULL OR
+        pg_catalog.to_regnamespace(pg_catalog.quote_ident(spec.schema)) IS NOT NULL)
+ ORDER BY target.id
+\gexec
+`)
+
+	// Revoke default privileges that are not specified. Entries of the role
+	// itself include its privileges as owner, so those are kept.
+	// - https://www.postgresql.org/docs/current/catalog-pg-default-acl.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.concat_ws(' ',
+       pg_catalog.format('ALTER DEFAULT PRIVILEGES FOR ROLE %I', creator.rolname),
+       'IN SCHEMA ' || pg_catalog.quote_ident(ns.nspname),
+       pg_catalog.format('REVOKE %s ON %s FROM %I',
+       acl.privilege_type, objects.type, target.username))
+  FROM target
+ CROSS JOIN pg_catalog.pg_default_acl AS defaults
+ CROSS JOIN LATERAL pg_catalog.aclexplode(defaults.defaclacl) AS acl
+ CROSS JOIN LATERAL (VALUES (CASE defaults.defaclobjtype
+       WHEN 'r' THEN 'TABLES' WHEN 'S' THEN 'SEQUENCES' WHEN 'f' THEN 'FUNCTIONS'
+       WHEN 'T' THEN 'TYPES' WHEN 'n' THEN 'SCHEMAS' END)) AS objects (type)
+  JOIN pg_catalog.pg_roles AS creator ON creator.oid = defaults.defaclrole
+  LEFT JOIN pg_catalog.pg_namespace AS ns ON ns.oid = defaults.defaclnamespace
+ WHERE acl.grantee = target.role AND defaults.defaclrole <> target.role
+   AND NOT EXISTS (
+       SELECT 1 FROM pg_catalog.json_to_recordset(
+              pg_catalog.json_extract_path(target.grants, 'defaultPrivileges'))
+              AS spec (database text, schema text, role text, "objectType" text, privileges json)
+        WHERE spec.database = pg_catalog.current_database()
+          AND spec.role = creator.rolname
+          AND spec.schema IS NOT DISTINCT FROM ns.nspname
+          AND spec."objectType" = objects.type
+          AND acl.privilege_type IN (
+              SELECT pg_catalog.json_array_elements_text(spec.privileges)))
+ ORDER BY target.id
+\gexec
+`)
+
+	// Print each schema above that does not exist in the current database.
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	_, _ = sql.WriteString(`
+\pset format unaligned
+\pset tuples_only on
+SELECT DISTINCT pg_catalog.format('schema %I in database %I',
+       spec.schema, pg_catalog.current_database())
+  FROM target
+ CROSS JOIN LATERAL (
+       SELECT database, schema FROM pg_catalog.json_to_recordset(
+              pg_catalog.json_extract_path(target.grants, 'schemas'))
+              AS spec (database text, schema text)
+        UNION ALL
+       SELECT database, schema FROM pg_catalog.json_to_recordset(
+              pg_catalog.json_extract_path(target.grants, 'defaultPrivileges'))
+              AS spec (database text, schema text)) AS spec
+ WHERE spec.database = pg_catalog.current_database()
+   AND spec.schema IS NOT NULL
+   AND pg_catalog.to_regnamespace(pg_catalog.quote_ident(spec.schema)) IS NULL;
+`)
+
+	if err == nil {
+		stdout, stderr, err = exec.ExecInAllDatabases(ctx, sql.String(),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("wrote PostgreSQL schema and default grants", "stdout", stdout, "stderr", stderr)
+	}
+
+	var missing []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			missing = append(missing, line)
+		}
+	}
+
+	return missing, err
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestWriteUserGrantsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		_, err := WriteUserGrantsInPostgreSQL(ctx, exec, nil)
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+\.
+`))
+			return nil
+		}
+
+		missing, err := WriteUserGrantsInPostgreSQL(ctx, exec, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, len(missing) == 0)
+		assert.Equal(t, calls, 2)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		var users []v1beta1.PostgresUserSpec
+		require.UnmarshalInto(t, &users, `[
+			{ name: postgres, grants: { roles: [ignored] } },
+			{ name: no-grants, databases: [ignored] },
+			{ name: app, databases: [app], grants: {
+				roles: [readers],
+				databases: [{ name: other, privileges: [CONNECT] }],
+				schemas: [{ database: app, schema: data, privileges: [USAGE] }],
+				defaultPrivileges: [{
+					database: app, schema: data, role: app,
+					objectType: TABLES, privileges: [SELECT],
+				}],
+			} },
+		]`)
+
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"databases":["app"],"grants":{"databases":[{"name":"other","privileges":["CONNECT"]}],"defaultPrivileges":[{"database":"app","objectType":"TABLES","privileges":["SELECT"],"role":"app","schema":"data"}],"roles":["readers"],"schemas":[{"database":"app","privileges":["USAGE"],"schema":"data"}]},"username":"app"}
+\.
+`))
+
+			// Shared catalogs are written once; the others in every database.
+			if calls == 1 {
+				assert.Assert(t, cmp.Contains(string(b), `GRANT %I TO %I`))
+				assert.Assert(t, cmp.Contains(string(b), `REVOKE %s ON DATABASE %I FROM %I`))
+				assert.Equal(t, command[0], "psql")
+			} else {
+				assert.Assert(t, cmp.Contains(string(b), `GRANT %s ON SCHEMA %I TO %I`))
+				assert.Assert(t, cmp.Contains(string(b), `REVOKE %s ON %s FROM %I`))
+				assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+					`SELECT datname FROM pg_catalog.pg_database`,
+				), "expected all databases and templates")
+
+				// Schemas that do not exist are skipped and printed.
+				assert.Assert(t, cmp.Contains(string(b),
+					`pg_catalog.to_regnamespace(pg_catalog.quote_ident(spec.schema)) IS NOT NULL`))
+				assert.Assert(t, cmp.Contains(string(b), `schema %I in database %I`))
+
+				_, _ = stdout.Write([]byte("schema data in database app\n\n"))
+			}
+			return nil
+		}
+
+		missing, err := WriteUserGrantsInPostgreSQL(ctx, exec, users)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, missing, []string{"schema data in database app"})
+		assert.Equal(t, calls, 2)
+	})
+}
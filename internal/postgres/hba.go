@@ -7,6 +7,7 @@ package postgres
 import (
 	"fmt"
 	"maps"
+	"net"
 	"regexp"
 	"slices"
 	"strings"
@@ -237,6 +238,61 @@ func (o *OrderedHBAs) AppendUnstructured(hbas ...string) {
 	}
 }
 
+// ReplaceMethod changes the authentication method of every record in o that
+// uses one of the old methods. Records that cannot be parsed are unchanged.
+func (o *OrderedHBAs) ReplaceMethod(method string, old ...string) {
+	for i, record := range o.records {
+		if start, end, ok := hbaMethodIndex(record); ok &&
+			slices.Contains(old, strings.Trim(record[start:end], `"`)) {
+			o.records[i] = record[:start] + method + record[end:]
+		}
+	}
+}
+
+// hbaMethodIndex returns the byte offsets of the authentication method in a
+// pg_hba.conf record. Fields are separated by whitespace and may be quoted;
+// a "#" outside of quotes begins a comment.
+// - https://www.postgresql.org/docs/current/auth-pg-hba-conf.html
+func hbaMethodIndex(record string) (int, int, bool) {
+	type field struct{ start, end int }
+	var fields []field
+
+	for i := 0; i < len(record); {
+		switch c := record[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '#':
+			i = len(record)
+		default:
+			start, quoted := i, false
+			for ; i < len(record); i++ {
+				if c := record[i]; c == '"' {
+					quoted = !quoted
+				} else if !quoted && (c == ' ' || c == '\t' || c == '#') {
+					break
+				}
+			}
+			fields = append(fields, field{start, i})
+		}
+	}
+
+	// The method follows the user of "local" records and the address of
+	// the others. An IP address may be followed by a separate mask.
+	index := 3
+	if len(fields) > 0 && record[fields[0].start:fields[0].end] != "local" {
+		index = 4
+		if len(fields) > 5 &&
+			net.ParseIP(record[fields[3].start:fields[3].end]) != nil &&
+			net.ParseIP(record[fields[4].start:fields[4].end]) != nil {
+			index = 5
+		}
+	}
+	if len(fields) <= index {
+		return 0, 0, false
+	}
+	return fields[index].start, fields[index].end, true
+}
+
 // AsStrings returns a copy of o as a slice.
 func (o *OrderedHBAs) AsStrings() []string {
 	return slices.Clone(o.records)
@@ -160,3 +160,31 @@ func TestOrderedHBAs(t *testing.T) {
 		})
 	})
 }
+
+func TestOrderedHBAsReplaceMethod(t *testing.T) {
+	rules := new(OrderedHBAs)
+	rules.Append(
+		NewHBA().TLS().Method("md5"),
+		NewHBA().Local().Users("app").Method("password"),
+		NewHBA().TLS().Method("cert"),
+	)
+	rules.AppendUnstructured(
+		`host all all 0.0.0.0/0 md5`,
+		`hostssl "my db" all 10.0.0.0 255.0.0.0 password clientcert=verify-ca`,
+		`local all md5 trust # md5`,
+		`host all "md5 user" samenet md5`,
+		`not enough fields`,
+	)
+
+	rules.ReplaceMethod("scram-sha-256", "md5", "password")
+	assert.DeepEqual(t, rules.AsStrings(), []string{
+		`hostssl all all all scram-sha-256`,
+		`local all "app" scram-sha-256`,
+		`hostssl all all all "cert"`,
+		`host all all 0.0.0.0/0 scram-sha-256`,
+		`hostssl "my db" all 10.0.0.0 255.0.0.0 scram-sha-256 clientcert=verify-ca`,
+		`local all md5 trust # md5`,
+		`host all "md5 user" samenet scram-sha-256`,
+		`not enough fields`,
+	})
+}
@@ -0,0 +1,406 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// escapeConnectionValue is called by ConnectionString to add backslashes
+// before the special characters of a quoted keyword value.
+var escapeConnectionValue = strings.NewReplacer(`'`, `\'`, `\`, `\\`).Replace
+
+// ConnectionString returns a libpq connection string of the keywords and
+// values in parameters. Keywords are sorted so the result is deterministic.
+// - https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING-KEYWORD-VALUE
+func ConnectionString(parameters map[string]string) string {
+	keywords := make([]string, 0, len(parameters))
+	for keyword := range parameters {
+		keywords = append(keywords, keyword)
+	}
+	slices.Sort(keywords)
+
+	for i, keyword := range keywords {
+		keywords[i] = keyword + `='` + escapeConnectionValue(parameters[keyword]) + `'`
+	}
+	return strings.Join(keywords, " ")
+}
+
+// WritePublicationsInPostgreSQL calls exec to create publications that do not
+// exist in PostgreSQL and to change the tables of publications that do.
+// PostgreSQL refuses to update or delete rows of a published table that has no
+// replica identity, so a publication of all tables is not created in a database
+// that has such tables; it returns a description of each of those tables.
+// - https://www.postgresql.org/docs/current/logical-replication-publication.html
+func WritePublicationsInPostgreSQL(
+	ctx context.Context, exec Executor, publications []v1beta1.PostgresPublicationSpec,
+) ([]string, error) {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Do not wait for changes to be replicated. [Since PostgreSQL v9.1]
+	// - https://www.postgresql.org/docs/current/runtime-config-wal.html
+	_, _ = sql.WriteString(`SET synchronous_commit = LOCAL;`)
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the publication specifications.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range publications {
+		spec := publications[i]
+		value := map[string]any{"database": spec.Database, "name": spec.Name}
+
+		if len(spec.Tables) > 0 {
+			tables := make([]map[string]string, len(spec.Tables))
+			for j, table := range spec.Tables {
+				tables[j] = map[string]string{"schema": table.Schema, "name": table.Name}
+				if table.Schema == "" {
+					tables[j]["schema"] = "public"
+				}
+			}
+			value["tables"] = tables
+		}
+
+		if err == nil {
+			err = encoder.Encode(value)
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Choose the publications for the current database. Quote and sort the
+	// names of their tables so they can be compared to those in the catalog.
+	// - https://www.postgresql.org/docs/current/functions-json.html
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY VIEW target AS
+SELECT input.id, spec.name, spec.tables IS NULL AS all_tables,
+       ARRAY(SELECT pg_catalog.format('%I.%I', t.schema, t.name)
+               FROM pg_catalog.json_to_recordset(spec.tables) AS t (schema text, name text)
+              ORDER BY 1) AS tables
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, name text, tables json)
+ WHERE spec.database = pg_catalog.current_database();
+`)
+
+	// List the tables that a publication of all tables would include but that
+	// have no replica identity: no primary key by default, no index when one
+	// was chosen, or nothing at all. Catalog tables are never published.
+	// - https://www.postgresql.org/docs/current/sql-altertable.html#SQL-ALTERTABLE-REPLICA-IDENTITY
+	// - https://www.postgresql.org/docs/current/catalog-pg-class.html
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY VIEW unidentified AS
+SELECT ns.nspname, c.relname
+  FROM pg_catalog.pg_class AS c
+  JOIN pg_catalog.pg_namespace AS ns ON ns.oid = c.relnamespace
+ WHERE c.relkind = 'r' AND c.relpersistence = 'p' AND c.oid >= 16384
+   AND ns.nspname NOT IN ('pg_catalog', 'information_schema')
+   AND CASE c.relreplident
+       WHEN 'f' THEN false
+       WHEN 'd' THEN NOT EXISTS (
+            SELECT 1 FROM pg_catalog.pg_index WHERE indrelid = c.oid AND indisprimary)
+       WHEN 'i' THEN NOT EXISTS (
+            SELECT 1 FROM pg_catalog.pg_index WHERE indrelid = c.oid AND indisreplident)
+       ELSE true END;
+`)
+
+	// Change the following objects in a transaction so that subscribers never
+	// see a publication go missing.
+	_, _ = sql.WriteString(`BEGIN;`)
+
+	// A publication of all tables cannot be changed to one of some tables,
+	// nor the reverse. Drop those that differ so they are created again below.
+	// Keep those that cannot become a publication of all tables yet.
+	// - https://www.postgresql.org/docs/current/sql-droppublication.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('DROP PUBLICATION %I', target.name)
+  FROM target
+  JOIN pg_catalog.pg_publication ON pubname = target.name
+ WHERE puballtables <> target.all_tables
+   AND NOT (target.all_tables AND EXISTS (SELECT 1 FROM unidentified))
+ ORDER BY target.id
+\gexec
+`)
+
+	// Create publications that do not already exist.
+	// - https://www.postgresql.org/docs/current/sql-createpublication.html
+	_, _ = sql.WriteString(`
+SELECT CASE WHEN target.all_tables
+       THEN pg_catalog.format('CREATE PUBLICATION %I FOR ALL TABLES', target.name)
+       ELSE pg_catalog.format('CREATE PUBLICATION %I FOR TABLE %s', target.name,
+            pg_catalog.array_to_string(target.tables, ', '))
+       END
+  FROM target
+ WHERE NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_publication WHERE pubname = target.name)
+   AND NOT (target.all_tables AND EXISTS (SELECT 1 FROM unidentified))
+ ORDER BY target.id
+\gexec
+`)
+
+	// Set the tables of publications that differ from their specification.
+	// - https://www.postgresql.org/docs/current/sql-alterpublication.html
+	// - https://www.postgresql.org/docs/current/view-pg-publication-tables.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('ALTER PUBLICATION %I SET TABLE %s', target.name,
+       pg_catalog.array_to_string(target.tables, ', '))
+  FROM target
+ WHERE NOT target.all_tables
+   AND target.tables IS DISTINCT FROM ARRAY(
+       SELECT pg_catalog.format('%I.%I', schemaname, tablename)
+         FROM pg_catalog.pg_publication_tables
+        WHERE pubname = target.name ORDER BY 1)
+ ORDER BY target.id
+\gexec
+`)
+
+	// Commit (finish) the transaction.
+	_, _ = sql.WriteString(`COMMIT;`)
+
+	// Print each table above when the current database has a publication of
+	// all tables.
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	_, _ = sql.WriteString(`
+\pset format unaligned
+\pset tuples_only on
+SELECT pg_catalog.format('table %I.%I in database %I',
+       unidentified.nspname, unidentified.relname, pg_catalog.current_database())
+  FROM unidentified
+ WHERE EXISTS (SELECT 1 FROM target WHERE target.all_tables)
+ ORDER BY 1;
+`)
+
+	var stdout, stderr string
+	if err == nil {
+		stdout, stderr, err = exec.ExecInAllDatabases(ctx, sql.String(),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("wrote PostgreSQL publications", "stdout", stdout, "stderr", stderr)
+	}
+
+	var unidentified []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			unidentified = append(unidentified, line)
+		}
+	}
+
+	return unidentified, err
+}
+
+// WriteSubscriptionsInPostgreSQL calls exec to create subscriptions that do
+// not exist in PostgreSQL and to update those that do. The connection string
+// of each subscription is taken from connections by name; subscriptions
+// without one are skipped.
+func WriteSubscriptionsInPostgreSQL(
+	ctx context.Context, exec Executor,
+	subscriptions []v1beta1.PostgresSubscriptionSpec, connections map[string]string,
+) error {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the subscription specifications.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range subscriptions {
+		spec := subscriptions[i]
+		connection, ok := connections[spec.Name]
+
+		if ok && err == nil {
+			err = encoder.Encode(map[string]any{
+				"connection":   connection,
+				"database":     spec.Database,
+				"enabled":      spec.Enabled == nil || *spec.Enabled,
+				"name":         spec.Name,
+				"publications": spec.Publications,
+			})
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Choose the subscriptions for the current database. Subscription names
+	// are unique in each database. Sort the names of their publications so
+	// they can be compared to those in the catalog.
+	// - https://www.postgresql.org/docs/current/catalog-pg-subscription.html
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY VIEW target AS
+SELECT input.id, spec.name, spec.connection, spec.enabled,
+       ARRAY(SELECT pg_catalog.json_array_elements_text(spec.publications) ORDER BY 1) AS publications,
+       pg_subscription.oid AS subid, pg_subscription.subconninfo,
+       pg_subscription.subenabled, pg_subscription.subpublications
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data)
+       AS spec (database text, name text, connection text, enabled boolean, publications json)
+  LEFT JOIN pg_catalog.pg_subscription ON subname = spec.name AND subdbid = (
+       SELECT oid FROM pg_catalog.pg_database WHERE datname = pg_catalog.current_database())
+ WHERE spec.database = pg_catalog.current_database();
+`)
+
+	// Create subscriptions that do not already exist. This connects to the
+	// source to create a replication slot, so it cannot be in a transaction.
+	// - https://www.postgresql.org/docs/current/sql-createsubscription.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('CREATE SUBSCRIPTION %I CONNECTION %L PUBLICATION %s WITH (enabled = %s)',
+       target.name, target.connection,
+       (SELECT pg_catalog.string_agg(pg_catalog.quote_ident(p), ', ')
+          FROM pg_catalog.unnest(target.publications) AS p),
+       target.enabled)
+  FROM target
+ WHERE target.subid IS NULL
+ ORDER BY target.id
+\gexec
+`)
+
+	// Update the connection, publications, and state of subscriptions that
+	// differ from their specification. Setting publications fetches any
+	// missing tables from the source.
+	// - https://www.postgresql.org/docs/current/sql-altersubscription.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('ALTER SUBSCRIPTION %I CONNECTION %L', target.name, target.connection)
+  FROM target
+ WHERE target.subid IS NOT NULL AND target.subconninfo IS DISTINCT FROM target.connection
+ ORDER BY target.id
+\gexec
+
+SELECT pg_catalog.format('ALTER SUBSCRIPTION %I SET PUBLICATION %s', target.name,
+       (SELECT pg_catalog.string_agg(pg_catalog.quote_ident(p), ', ')
+          FROM pg_catalog.unnest(target.publications) AS p))
+  FROM target
+ WHERE target.subid IS NOT NULL AND target.publications IS DISTINCT FROM ARRAY(
+       SELECT pg_catalog.unnest(target.subpublications) ORDER BY 1)
+ ORDER BY target.id
+\gexec
+
+SELECT pg_catalog.format('ALTER SUBSCRIPTION %I %s', target.name,
+       CASE WHEN target.enabled THEN 'ENABLE' ELSE 'DISABLE' END)
+  FROM target
+ WHERE target.subid IS NOT NULL AND target.subenabled IS DISTINCT FROM target.enabled
+ ORDER BY target.id
+\gexec
+`)
+
+	if err == nil {
+		var stdout, stderr string
+		stdout, stderr, err = exec.ExecInAllDatabases(ctx, sql.String(),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("wrote PostgreSQL subscriptions", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
+
+// SubscriptionStatus describes one subscription in PostgreSQL.
+// - https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-SUBSCRIPTION
+type SubscriptionStatus struct {
+	Database string `json:"database"`
+	Name     string `json:"name"`
+
+	// Whether or not the subscription is enabled and its apply worker is running.
+	Enabled bool `json:"enabled"`
+	Running bool `json:"running"`
+
+	// The number of tables that are still being copied from the source.
+	Synchronizing int64 `json:"synchronizing"`
+
+	// Seconds since the source last reported a position in its WAL to the
+	// apply worker. This is null when the worker is not running.
+	LagSeconds *float64 `json:"lag_seconds"`
+}
+
+// SubscriptionStatusInPostgreSQL calls exec to list the subscriptions in every
+// database that allows connections.
+func SubscriptionStatusInPostgreSQL(
+	ctx context.Context, exec Executor,
+) ([]SubscriptionStatus, error) {
+	log := logging.FromContext(ctx)
+
+	// Print one JSON object per subscription. The apply worker of each
+	// subscription is the one without a table. Since PostgreSQL v16, parallel
+	// apply workers have no table either, so choose the most recent of them.
+	// - https://www.postgresql.org/docs/current/catalog-pg-subscription-rel.html
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx, `
+SET search_path TO '';
+\pset format unaligned
+\pset tuples_only on
+SELECT DISTINCT ON (s.subname) pg_catalog.json_build_object(
+         'database', pg_catalog.current_database(),
+         'name', s.subname,
+         'enabled', s.subenabled,
+         'running', worker.pid IS NOT NULL,
+         'synchronizing', (
+           SELECT pg_catalog.count(*) FROM pg_catalog.pg_subscription_rel
+            WHERE srsubid = s.oid AND srsubstate <> 'r'),
+         'lag_seconds', EXTRACT(epoch FROM
+           pg_catalog.statement_timestamp() - worker.latest_end_time))
+  FROM pg_catalog.pg_subscription AS s
+  LEFT JOIN pg_catalog.pg_stat_subscription AS worker
+       ON worker.subid = s.oid AND worker.relid IS NULL AND worker.pid IS NOT NULL
+ WHERE s.subdbid = (
+       SELECT oid FROM pg_catalog.pg_database WHERE datname = pg_catalog.current_database())
+ ORDER BY s.subname, worker.latest_end_time DESC NULLS LAST;
+`,
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("queried PostgreSQL subscriptions", "stdout", stdout, "stderr", stderr)
+
+	var result []SubscriptionStatus
+	for scanner := bufio.NewScanner(strings.NewReader(stdout)); err == nil && scanner.Scan(); {
+		if line := scanner.Bytes(); len(bytes.TrimSpace(line)) > 0 {
+			var status SubscriptionStatus
+			if err = json.Unmarshal(line, &status); err == nil {
+				result = append(result, status)
+			}
+		}
+	}
+
+	return result, err
+}
@@ -0,0 +1,232 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestConnectionString(t *testing.T) {
+	assert.Equal(t, ConnectionString(nil), "")
+	assert.Equal(t, ConnectionString(map[string]string{
+		"user":     "app",
+		"host":     "hippo-primary.ns1.svc",
+		"password": `it's \ here`,
+	}), `host='hippo-primary.ns1.svc' password='it\'s \\ here' user='app'`)
+}
+
+func TestWritePublicationsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+
+			assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+				`SELECT datname FROM pg_catalog.pg_database`,
+			), "expected all databases and templates")
+
+			return expected
+		}
+
+		_, err := WritePublicationsInPostgreSQL(ctx, exec, nil)
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		var publications []v1beta1.PostgresPublicationSpec
+		require.UnmarshalInto(t, &publications, `[
+			{ name: everything, database: app },
+			{ name: some, database: app, tables: [
+				{ name: orders }, { name: items, schema: sales },
+			] },
+		]`)
+
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, _ ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), strings.Join([]string{
+				`{"database":"app","name":"everything"}`,
+				`{"database":"app","name":"some","tables":[{"name":"orders","schema":"public"},{"name":"items","schema":"sales"}]}`,
+				`\.`,
+			}, "\n")))
+
+			assert.Assert(t, cmp.Contains(string(b), `BEGIN;`))
+			assert.Assert(t, cmp.Contains(string(b), `'DROP PUBLICATION %I'`))
+			assert.Assert(t, cmp.Contains(string(b), `'CREATE PUBLICATION %I FOR ALL TABLES'`))
+			assert.Assert(t, cmp.Contains(string(b), `'ALTER PUBLICATION %I SET TABLE %s'`))
+			assert.Assert(t, cmp.Contains(string(b), `FROM pg_catalog.pg_publication_tables`))
+			assert.Assert(t, cmp.Contains(string(b), `COMMIT;`))
+
+			// Publications of all tables wait for every table to have a replica identity.
+			assert.Assert(t, cmp.Contains(string(b), `CREATE TEMPORARY VIEW unidentified AS`))
+			assert.Assert(t, cmp.Contains(string(b),
+				`AND NOT (target.all_tables AND EXISTS (SELECT 1 FROM unidentified))`))
+			assert.Assert(t, strings.Index(string(b), `COMMIT;`) <
+				strings.Index(string(b), `'table %I.%I in database %I'`))
+			return nil
+		}
+
+		unidentified, err := WritePublicationsInPostgreSQL(ctx, exec, publications)
+		assert.NilError(t, err)
+		assert.Assert(t, unidentified == nil)
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("Unidentified", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = io.WriteString(stdout,
+				"table public.events in database app\n\ntable public.log in database app\n")
+			return nil
+		}
+
+		unidentified, err := WritePublicationsInPostgreSQL(ctx, exec, nil)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, unidentified, []string{
+			"table public.events in database app",
+			"table public.log in database app",
+		})
+	})
+}
+
+func TestWriteSubscriptionsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+
+			assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+				`SELECT datname FROM pg_catalog.pg_database`,
+			), "expected all databases and templates")
+
+			return expected
+		}
+
+		assert.Equal(t, expected, WriteSubscriptionsInPostgreSQL(ctx, exec, nil, nil))
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		var subscriptions []v1beta1.PostgresSubscriptionSpec
+		require.UnmarshalInto(t, &subscriptions, `[
+			{ name: first, database: app, publications: [one, two],
+			  source: { clusterName: other, user: repl } },
+			{ name: second, database: app, publications: [three], enabled: false,
+			  source: { clusterName: other, user: repl } },
+			{ name: missing, database: app, publications: [four],
+			  source: { clusterName: gone, user: repl } },
+		]`)
+
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, _ ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), strings.Join([]string{
+				`{"connection":"host='x'","database":"app","enabled":true,"name":"first","publications":["one","two"]}`,
+				`{"connection":"host='y'","database":"app","enabled":false,"name":"second","publications":["three"]}`,
+				`\.`,
+			}, "\n")))
+
+			// Subscriptions cannot be created in a transaction.
+			assert.Assert(t, !strings.Contains(string(b), `BEGIN`))
+			assert.Assert(t, cmp.Contains(string(b), `'CREATE SUBSCRIPTION %I CONNECTION %L PUBLICATION %s WITH (enabled = %s)'`))
+			assert.Assert(t, cmp.Contains(string(b), `'ALTER SUBSCRIPTION %I CONNECTION %L'`))
+			assert.Assert(t, cmp.Contains(string(b), `'ALTER SUBSCRIPTION %I SET PUBLICATION %s'`))
+			assert.Assert(t, cmp.Contains(string(b), `THEN 'ENABLE' ELSE 'DISABLE'`))
+			return nil
+		}
+
+		assert.NilError(t, WriteSubscriptionsInPostgreSQL(ctx, exec, subscriptions,
+			map[string]string{"first": "host='x'", "second": "host='y'"}))
+		assert.Equal(t, calls, 1)
+	})
+}
+
+func TestSubscriptionStatusInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `FROM pg_catalog.pg_subscription AS s`))
+			assert.Assert(t, cmp.Contains(string(b), `pg_catalog.pg_stat_subscription`))
+			return expected
+		}
+
+		_, err := SubscriptionStatusInPostgreSQL(ctx, exec)
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = io.WriteString(stdout, `
+{"database" : "app", "name" : "first", "enabled" : true, "running" : true, "synchronizing" : 2, "lag_seconds" : 1.5}
+{"database" : "app", "name" : "second", "enabled" : false, "running" : false, "synchronizing" : 0, "lag_seconds" : null}
+`)
+			return nil
+		}
+
+		result, err := SubscriptionStatusInPostgreSQL(ctx, exec)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, result, []SubscriptionStatus{
+			{
+				Database: "app", Name: "first", Enabled: true, Running: true,
+				Synchronizing: 2, LagSeconds: initialize.Pointer(1.5),
+			},
+			{Database: "app", Name: "second"},
+		})
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = io.WriteString(stdout, "not json\n")
+			return nil
+		}
+
+		_, err := SubscriptionStatusInPostgreSQL(ctx, exec)
+		assert.ErrorContains(t, err, "invalid")
+	})
+}
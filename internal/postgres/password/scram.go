@@ -12,6 +12,8 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
@@ -58,6 +60,9 @@ var (
 	ErrSCRAMPasswordInvalid = errors.New(`invalid password attributes. must provide "password"`)
 	// ErrSCRAMSaltLengthInvalid is returned when the salt length is less than 1
 	ErrSCRAMSaltLengthInvalid = errors.New(`salt length must be at least 1`)
+	// ErrSCRAMVerifierInvalid is returned when a value is not a SCRAM verifier
+	ErrSCRAMVerifierInvalid = errors.New(
+		`invalid SCRAM verifier. must be "SCRAM-SHA-256$<ITERATIONS>:<SALT>$<STORED_KEY>:<SERVER_KEY>"`)
 )
 
 // SCRAMPassword contains the building blocks to build a PostgreSQL SCRAM
@@ -89,24 +94,46 @@ func (s *SCRAMPassword) Build() (string, error) {
 		return "", err
 	}
 
+	storedKey, serverKey := s.keys(salt, s.Iterations)
+
+	// finally, we can build the scram verified!
+	verifier := fmt.Sprintf(scramVerifierFormat,
+		s.Iterations, s.encode(salt), s.encode(storedKey), s.encode(serverKey))
+
+	return verifier, nil
+}
+
+// Verify returns true when verifier is a SCRAM verifier of the password.
+// It returns false when verifier is not a valid SCRAM verifier.
+func (s *SCRAMPassword) Verify(verifier string) bool {
+	iterations, salt, storedKey, serverKey, err := parseSCRAMVerifier(verifier)
+	if err != nil {
+		return false
+	}
+
+	expectedStoredKey, expectedServerKey := s.keys(salt, iterations)
+
+	return hmac.Equal(storedKey, expectedStoredKey) &&
+		hmac.Equal(serverKey, expectedServerKey)
+}
+
+// keys returns the stored key and server key of the password using salt and
+// the number of PBKDF2 iterations
+func (s *SCRAMPassword) keys(salt []byte, iterations int) (storedKey, serverKey []byte) {
 	// before generating the salted password, we have to normalize the password
 	// using SASLprep
 	password := s.saslPrep()
 
-	saltedPassword := pbkdf2.Key([]byte(password), salt, s.Iterations, scramDefaultHash().Size(), scramDefaultHash)
+	saltedPassword := pbkdf2.Key([]byte(password), salt, iterations, scramDefaultHash().Size(), scramDefaultHash)
 
 	// time to create the HMAC generated values (client key, server key)
 	clientKey := s.hmac(scramDefaultHash, saltedPassword, scramClientKeyMessage)
-	serverKey := s.hmac(scramDefaultHash, saltedPassword, scramServerKeyMessage)
+	serverKey = s.hmac(scramDefaultHash, saltedPassword, scramServerKeyMessage)
 
 	// get the stored key, which is the hash of the client key
-	storedKey := s.hash(scramDefaultHash, clientKey)
-
-	// finally, we can build the scram verified!
-	verifier := fmt.Sprintf(scramVerifierFormat,
-		s.Iterations, s.encode(salt), s.encode(storedKey), s.encode(serverKey))
+	storedKey = s.hash(scramDefaultHash, clientKey)
 
-	return verifier, nil
+	return storedKey, serverKey
 }
 
 // encode creates a base64 encoding of a value that's returned as a string
@@ -187,6 +214,56 @@ func NewSCRAMPassword(password string) *SCRAMPassword {
 	}
 }
 
+// IsSCRAMVerifier returns true when value has the prefix of a SCRAM verifier.
+// Use [ValidateSCRAMVerifier] to check the rest of value.
+func IsSCRAMVerifier(value string) bool {
+	return strings.HasPrefix(value, "SCRAM-SHA-256$")
+}
+
+// ValidateSCRAMVerifier returns ErrSCRAMVerifierInvalid when verifier does not
+// follow the format that is stored by PostgreSQL.
+func ValidateSCRAMVerifier(verifier string) error {
+	_, _, _, _, err := parseSCRAMVerifier(verifier)
+	return err
+}
+
+// parseSCRAMVerifier returns the parts of a SCRAM verifier. The format is
+// described by scramVerifierFormat and parsed like PostgreSQL does, i.e.
+//
+// https://git.postgresql.org/gitweb/?p=postgresql.git;a=blob;f=src/backend/libpq/auth-scram.c
+func parseSCRAMVerifier(verifier string) (
+	iterations int, salt, storedKey, serverKey []byte, err error,
+) {
+	digest, rest, _ := strings.Cut(verifier, "$")
+	params, keys, _ := strings.Cut(rest, "$")
+	iterationsText, saltText, ok1 := strings.Cut(params, ":")
+	storedKeyText, serverKeyText, ok2 := strings.Cut(keys, ":")
+
+	if digest != "SCRAM-SHA-256" || !ok1 || !ok2 {
+		return 0, nil, nil, nil, ErrSCRAMVerifierInvalid
+	}
+
+	iterations, err = strconv.Atoi(iterationsText)
+	if err != nil || iterations < 1 {
+		return 0, nil, nil, nil, ErrSCRAMVerifierInvalid
+	}
+
+	// the salt can be any length, but the keys are the size of the hash
+	size := scramDefaultHash().Size()
+	salt, err = base64.StdEncoding.DecodeString(saltText)
+	if err == nil {
+		storedKey, err = base64.StdEncoding.DecodeString(storedKeyText)
+	}
+	if err == nil {
+		serverKey, err = base64.StdEncoding.DecodeString(serverKeyText)
+	}
+	if err != nil || len(salt) == 0 || len(storedKey) != size || len(serverKey) != size {
+		return 0, nil, nil, nil, ErrSCRAMVerifierInvalid
+	}
+
+	return iterations, salt, storedKey, serverKey, nil
+}
+
 // scramGenerateSalt generates a series of cryptographic bytes of a specified
 // length for purposes of SCRAM. must be at least 1
 func scramGenerateSalt(length int) ([]byte, error) {
@@ -119,6 +119,72 @@ func TestSCRAMBuild(t *testing.T) {
 	})
 }
 
+func TestSCRAMVerify(t *testing.T) {
+	verifier := `SCRAM-SHA-256$4096:aDFwcDBwNHJ0eTIwMjA=$xHkOo65LX9eBB8a6v+axqvs3+aMBTH0sCT7w/Nxzh5M=:PXuFoeJNuAGSeExskYSqkwUyiUJu8LPC9DgwDWQ9ARQ=`
+
+	t.Run("match", func(t *testing.T) {
+		if !NewSCRAMPassword("datalake").Verify(verifier) {
+			t.Errorf("expected %q to verify", verifier)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		if NewSCRAMPassword("datalak3").Verify(verifier) {
+			t.Errorf("expected %q to not verify", verifier)
+		}
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		scram := NewSCRAMPassword("øásis")
+		generated, err := scram.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !scram.Verify(generated) {
+			t.Errorf("expected %q to verify", generated)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if NewSCRAMPassword("datalake").Verify("datalake") {
+			t.Errorf("expected invalid verifier to not verify")
+		}
+	})
+}
+
+func TestValidateSCRAMVerifier(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		verifier := `SCRAM-SHA-256$4096:aDFwcDBwNHJ0eTIwMjA=$xHkOo65LX9eBB8a6v+axqvs3+aMBTH0sCT7w/Nxzh5M=:PXuFoeJNuAGSeExskYSqkwUyiUJu8LPC9DgwDWQ9ARQ=`
+
+		if !IsSCRAMVerifier(verifier) {
+			t.Errorf("expected %q to be a SCRAM verifier", verifier)
+		}
+		if err := ValidateSCRAMVerifier(verifier); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, verifier := range []string{
+			``,
+			`datalake`,
+			`md53a0689aa9e31a50b5621971fc89f0c64`,
+			`SCRAM-SHA-256$`,
+			`SCRAM-SHA-256$4096:aDFwcDBwNHJ0eTIwMjA=`,
+			`SCRAM-SHA-256$0:aDFwcDBwNHJ0eTIwMjA=$xHkOo65LX9eBB8a6v+axqvs3+aMBTH0sCT7w/Nxzh5M=:PXuFoeJNuAGSeExskYSqkwUyiUJu8LPC9DgwDWQ9ARQ=`,
+			`SCRAM-SHA-256$4096:$xHkOo65LX9eBB8a6v+axqvs3+aMBTH0sCT7w/Nxzh5M=:PXuFoeJNuAGSeExskYSqkwUyiUJu8LPC9DgwDWQ9ARQ=`,
+			`SCRAM-SHA-256$4096:aDFwcDBwNHJ0eTIwMjA=$aGlwcG8=:PXuFoeJNuAGSeExskYSqkwUyiUJu8LPC9DgwDWQ9ARQ=`,
+			`SCRAM-SHA-256$4096:aDFwcDBwNHJ0eTIwMjA=$xHkOo65LX9eBB8a6v+axqvs3+aMBTH0sCT7w/Nxzh5M=:!!!`,
+		} {
+			t.Run(verifier, func(t *testing.T) {
+				if err := ValidateSCRAMVerifier(verifier); err != ErrSCRAMVerifierInvalid {
+					t.Errorf("expected ErrSCRAMVerifierInvalid, got %v", err)
+				}
+			})
+		}
+	})
+}
+
 func TestSCRAMEncode(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		scram := SCRAMPassword{}
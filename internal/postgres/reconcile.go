@@ -162,7 +162,7 @@ func InstancePod(ctx context.Context,
 		// Patroni will set the command and probes.
 
 		Env:             Environment(inCluster),
-		Image:           config.PostgresContainerImage(inCluster),
+		Image:           config.PostgresInstanceImage(inCluster, inInstanceSpec),
 		ImagePullPolicy: inCluster.Spec.ImagePullPolicy,
 		Resources:       inInstanceSpec.Resources,
 
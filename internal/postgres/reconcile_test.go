@@ -702,6 +702,21 @@ volumes:
 			[]string{"startup", "11", "/pgwal/pg11_wal"})
 	})
 
+	t.Run("InstanceSetImage", func(t *testing.T) {
+		instance := new(v1beta1.PostgresInstanceSetSpec)
+		instance.Image = "canary-image"
+
+		pod := new(corev1.PodTemplateSpec)
+		InstancePod(ctx, cluster, instance,
+			serverSecretProjection, clientSecretProjection, dataVolume, nil, nil, pod)
+
+		// The database container and its init containers use the image.
+		assert.Equal(t, pod.Spec.Containers[0].Image, "canary-image")
+		for _, container := range pod.Spec.InitContainers {
+			assert.Equal(t, container.Image, "canary-image", "in %q", container.Name)
+		}
+	})
+
 	t.Run("TempVolume", func(t *testing.T) {
 		instance := new(v1beta1.PostgresInstanceSetSpec)
 		require.UnmarshalInto(t, &instance, `{
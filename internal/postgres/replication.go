@@ -0,0 +1,183 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// ReplicationStatus describes one standby connected to a primary.
+// - https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-REPLICATION-VIEW
+type ReplicationStatus struct {
+	// The "application_name" of the standby. Patroni sets this to the name
+	// of its member, which is the name of the instance Pod.
+	Name string `json:"name"`
+
+	// How the primary waits for this standby: "async", "potential", "sync",
+	// or "quorum".
+	SyncState string `json:"sync_state"`
+
+	// Bytes of WAL the primary has written that this standby has not yet
+	// written or replayed.
+	WriteLagBytes  *int64 `json:"write_lag_bytes"`
+	ReplayLagBytes *int64 `json:"replay_lag_bytes"`
+
+	// Seconds between WAL being flushed on the primary and this standby
+	// reporting that it has written or replayed it. These are null when the
+	// standby is caught up and idle.
+	WriteLagSeconds  *float64 `json:"write_lag_seconds"`
+	ReplayLagSeconds *float64 `json:"replay_lag_seconds"`
+}
+
+// ReplicationStatusInPostgreSQL calls exec to list the standbys connected to
+// PostgreSQL. It returns nothing when PostgreSQL is not a primary.
+func ReplicationStatusInPostgreSQL(
+	ctx context.Context, exec Executor,
+) ([]ReplicationStatus, error) {
+	log := logging.FromContext(ctx)
+
+	// Print one JSON object per standby. The "*_lag" columns and
+	// "pg_wal_lsn_diff" function are available since PostgreSQL v10.
+	// - https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-BACKUP
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	stdout, stderr, err := exec.Exec(ctx, strings.NewReader(`
+SET search_path TO '';
+\pset format unaligned
+\pset tuples_only on
+SELECT pg_catalog.json_build_object(
+         'name', application_name,
+         'sync_state', sync_state,
+         'write_lag_bytes', pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), write_lsn)::bigint,
+         'replay_lag_bytes', pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), replay_lsn)::bigint,
+         'write_lag_seconds', EXTRACT(epoch FROM write_lag),
+         'replay_lag_seconds', EXTRACT(epoch FROM replay_lag))
+  FROM pg_catalog.pg_stat_replication
+ WHERE NOT pg_catalog.pg_is_in_recovery()
+ ORDER BY application_name;
+`),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("queried PostgreSQL replication", "stdout", stdout, "stderr", stderr)
+
+	var result []ReplicationStatus
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" && err == nil {
+			var status ReplicationStatus
+			err = errors.WithStack(json.Unmarshal([]byte(line), &status))
+			result = append(result, status)
+		}
+	}
+
+	return result, err
+}
+
+// ReplicationSlot describes one replication slot on a primary.
+// - https://www.postgresql.org/docs/current/view-pg-replication-slots.html
+type ReplicationSlot struct {
+	Name string `json:"name"`
+
+	// Either "logical" or "physical".
+	Type string `json:"type"`
+
+	// Whether or not a client is connected to the slot.
+	Active bool `json:"active"`
+
+	// Bytes of WAL the primary keeps for the slot. This is null when the
+	// slot has not reserved any WAL.
+	RetainedBytes *int64 `json:"retained_bytes"`
+}
+
+// ReplicationSlotsInPostgreSQL calls exec to list the replication slots in
+// PostgreSQL. It returns nothing when PostgreSQL is not a primary.
+func ReplicationSlotsInPostgreSQL(
+	ctx context.Context, exec Executor,
+) ([]ReplicationSlot, error) {
+	log := logging.FromContext(ctx)
+
+	// Print one JSON object per slot.
+	// - https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-BACKUP
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	stdout, stderr, err := exec.Exec(ctx, strings.NewReader(`
+SET search_path TO '';
+\pset format unaligned
+\pset tuples_only on
+SELECT pg_catalog.json_build_object(
+         'name', slot_name,
+         'type', slot_type,
+         'active', active,
+         'retained_bytes', pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), restart_lsn)::bigint)
+  FROM pg_catalog.pg_replication_slots
+ WHERE NOT pg_catalog.pg_is_in_recovery()
+ ORDER BY slot_name;
+`),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("queried PostgreSQL replication slots", "stdout", stdout, "stderr", stderr)
+
+	var result []ReplicationSlot
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" && err == nil {
+			var slot ReplicationSlot
+			err = errors.WithStack(json.Unmarshal([]byte(line), &slot))
+			result = append(result, slot)
+		}
+	}
+
+	return result, err
+}
+
+// DropReplicationSlotsInPostgreSQL calls exec to drop the named replication
+// slots that have no client connected. It returns the names of slots dropped.
+func DropReplicationSlotsInPostgreSQL(
+	ctx context.Context, exec Executor, names []string,
+) ([]string, error) {
+	log := logging.FromContext(ctx)
+
+	slots, err := json.Marshal(names)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Print the name of each slot as it is dropped.
+	// - https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-REPLICATION
+	stdout, stderr, err := exec.Exec(ctx, strings.NewReader(`
+SET search_path TO '';
+\pset format unaligned
+\pset tuples_only on
+SELECT slot_name, pg_catalog.pg_drop_replication_slot(slot_name)
+  FROM pg_catalog.pg_replication_slots
+ WHERE NOT active
+   AND slot_name IN (SELECT pg_catalog.json_array_elements_text(:'slots'))
+ ORDER BY slot_name;
+`),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+			"slots":         string(slots),
+		})
+
+	log.V(1).Info("dropped PostgreSQL replication slots", "stdout", stdout, "stderr", stderr)
+
+	var dropped []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if name, _, _ := strings.Cut(strings.TrimSpace(line), "|"); name != "" {
+			dropped = append(dropped, name)
+		}
+	}
+
+	return dropped, err
+}
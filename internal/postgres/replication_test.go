@@ -0,0 +1,157 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+)
+
+func TestReplicationStatusInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `FROM pg_catalog.pg_stat_replication`))
+			assert.Assert(t, cmp.Contains(string(b), `NOT pg_catalog.pg_is_in_recovery()`))
+			return expected
+		}
+
+		_, err := ReplicationStatusInPostgreSQL(ctx, exec)
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = io.WriteString(stdout, `
+{"name" : "hippo-00-abcd-0", "sync_state" : "sync", "write_lag_bytes" : 0, "replay_lag_bytes" : 1024, "write_lag_seconds" : 0.0012, "replay_lag_seconds" : 2.5}
+{"name" : "hippo-01-efgh-0", "sync_state" : "async", "write_lag_bytes" : 5, "replay_lag_bytes" : 5, "write_lag_seconds" : null, "replay_lag_seconds" : null}
+`)
+			return nil
+		}
+
+		result, err := ReplicationStatusInPostgreSQL(ctx, exec)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, result, []ReplicationStatus{
+			{
+				Name: "hippo-00-abcd-0", SyncState: "sync",
+				WriteLagBytes: initialize.Int64(0), ReplayLagBytes: initialize.Int64(1024),
+				WriteLagSeconds: initialize.Pointer(0.0012), ReplayLagSeconds: initialize.Pointer(2.5),
+			},
+			{
+				Name: "hippo-01-efgh-0", SyncState: "async",
+				WriteLagBytes: initialize.Int64(5), ReplayLagBytes: initialize.Int64(5),
+			},
+		})
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = io.WriteString(stdout, "not json\n")
+			return nil
+		}
+
+		_, err := ReplicationStatusInPostgreSQL(ctx, exec)
+		assert.ErrorContains(t, err, "invalid")
+	})
+}
+
+func TestReplicationSlotsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `FROM pg_catalog.pg_replication_slots`))
+			assert.Assert(t, cmp.Contains(string(b), `NOT pg_catalog.pg_is_in_recovery()`))
+			return expected
+		}
+
+		_, err := ReplicationSlotsInPostgreSQL(ctx, exec)
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = io.WriteString(stdout, `
+{"name" : "debezium", "type" : "logical", "active" : true, "retained_bytes" : 2048}
+{"name" : "unused", "type" : "physical", "active" : false, "retained_bytes" : null}
+`)
+			return nil
+		}
+
+		result, err := ReplicationSlotsInPostgreSQL(ctx, exec)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, result, []ReplicationSlot{
+			{Name: "debezium", Type: "logical", Active: true, RetainedBytes: initialize.Int64(2048)},
+			{Name: "unused", Type: "physical"},
+		})
+	})
+}
+
+func TestDropReplicationSlotsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			assert.Assert(t, cmp.Contains(command, `--set=slots=["one","two"]`))
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `pg_catalog.pg_drop_replication_slot(slot_name)`))
+			assert.Assert(t, cmp.Contains(string(b), `WHERE NOT active`))
+			return expected
+		}
+
+		_, err := DropReplicationSlotsInPostgreSQL(ctx, exec, []string{"one", "two"})
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Parse", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, _ = io.WriteString(stdout, "one|\n\n")
+			return nil
+		}
+
+		dropped, err := DropReplicationSlotsInPostgreSQL(ctx, exec, []string{"one", "two"})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, dropped, []string{"one"})
+	})
+}
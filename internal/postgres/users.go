@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"slices"
 	"strings"
 
 	pg_query "github.com/pganalyze/pg_query_go/v6"
@@ -18,6 +19,7 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/feature"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	pgpassword "github.com/crunchydata/postgres-operator/internal/postgres/password"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -250,3 +252,233 @@ func WriteUsersSchemasInPostgreSQL(ctx context.Context, exec Executor,
 	}
 	return err
 }
+
+// DropUsersInPostgreSQL calls exec to drop users and the objects they own in
+// every database. When reassignTo is not empty, objects owned by users are
+// first given to that role. Otherwise, users that own objects are not dropped
+// and their names are returned. Users that do not exist are ignored.
+func DropUsersInPostgreSQL(
+	ctx context.Context, exec Executor, users []string, reassignTo string,
+) ([]string, error) {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+	var stdout, stderr string
+
+	input := func(sql *bytes.Buffer) {
+		// Fill a temporary table with the JSON of the users to drop.
+		// "\copy" reads from subsequent lines until the special line "\.".
+		// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+		_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+		encoder := json.NewEncoder(sql)
+		encoder.SetEscapeHTML(false)
+
+		for i := range users {
+			value := map[string]any{"username": users[i]}
+			if reassignTo != "" {
+				value["reassign"] = reassignTo
+			}
+			if err == nil {
+				err = encoder.Encode(value)
+			}
+		}
+		_, _ = sql.WriteString(`\.` + "\n")
+
+		// Look up the role of each user and whether it owns any objects in any
+		// database. Roles that do not exist are skipped.
+		// - https://www.postgresql.org/docs/current/catalog-pg-shdepend.html
+		_, _ = sql.WriteString(`
+CREATE TEMPORARY VIEW target AS
+SELECT input.id, role.rolname, spec.reassign,
+       EXISTS (
+       SELECT 1 FROM pg_catalog.pg_shdepend
+        WHERE refclassid = 'pg_catalog.pg_authid'::pg_catalog.regclass
+          AND refobjid = role.oid AND deptype = 'o') AS owner
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data) AS spec (username text, reassign text)
+  JOIN pg_catalog.pg_roles AS role ON role.rolname = spec.username;
+`)
+	}
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+	input(&sql)
+
+	// Print the name of each user that owns objects and has nowhere to put them.
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	_, _ = sql.WriteString(`
+\pset format unaligned
+\pset tuples_only on
+SELECT target.rolname FROM target
+ WHERE target.owner AND target.reassign IS NULL
+ ORDER BY target.id;
+`)
+
+	if err == nil {
+		stdout, stderr, err = exec.Exec(ctx, &sql,
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("checked PostgreSQL users", "stdout", stdout, "stderr", stderr)
+	}
+
+	var retained []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			retained = append(retained, line)
+		}
+	}
+
+	// Reassign and drop objects in every database. Check ownership again in
+	// case objects were created since the check above.
+	// - https://www.postgresql.org/docs/current/sql-reassign-owned.html
+	// - https://www.postgresql.org/docs/current/sql-drop-owned.html
+	// - https://www.postgresql.org/docs/current/role-removal.html
+	sql.Reset()
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+	input(&sql)
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('REASSIGN OWNED BY %I TO %I', target.rolname, target.reassign)
+  FROM target WHERE target.reassign IS NOT NULL
+ ORDER BY target.id
+\gexec
+
+SELECT pg_catalog.format('DROP OWNED BY %I', target.rolname)
+  FROM target WHERE target.reassign IS NOT NULL OR NOT target.owner
+ ORDER BY target.id
+\gexec
+`)
+
+	if err == nil && len(retained) < len(users) {
+		stdout, stderr, err = exec.ExecInAllDatabases(ctx, sql.String(),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("dropped PostgreSQL objects", "stdout", stdout, "stderr", stderr)
+	}
+
+	// Drop the roles that no longer own anything.
+	// - https://www.postgresql.org/docs/current/sql-droprole.html
+	sql.Reset()
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+	input(&sql)
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('DROP ROLE %I', target.rolname)
+  FROM target WHERE target.reassign IS NOT NULL OR NOT target.owner
+ ORDER BY target.id
+\gexec
+`)
+
+	if err == nil && len(retained) < len(users) {
+		stdout, stderr, err = exec.Exec(ctx, &sql,
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("dropped PostgreSQL users", "stdout", stdout, "stderr", stderr)
+	}
+
+	return retained, err
+}
+
+// RehashMD5UsersInPostgreSQL calls exec to replace the MD5 passwords of users
+// with their SCRAM-SHA-256 verifiers. Verifiers of other kinds are ignored. It
+// returns the names of all roles that still have MD5 passwords afterward.
+func RehashMD5UsersInPostgreSQL(
+	ctx context.Context, exec Executor, verifiers map[string]string,
+) ([]string, error) {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Do not wait for changes to be replicated. [Since PostgreSQL v9.1]
+	// - https://www.postgresql.org/docs/current/runtime-config-wal.html
+	_, _ = sql.WriteString(`SET synchronous_commit = LOCAL;`)
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the verifiers.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	usernames := make([]string, 0, len(verifiers))
+	for username := range verifiers {
+		usernames = append(usernames, username)
+	}
+	slices.Sort(usernames)
+
+	for _, username := range usernames {
+		if verifier := verifiers[username]; pgpassword.IsSCRAMVerifier(verifier) && err == nil {
+			err = encoder.Encode(map[string]any{
+				"username": username,
+				"verifier": verifier,
+			})
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Replace MD5 passwords of the users above. PostgreSQL stores MD5 passwords
+	// as "md5" followed by 32 hexadecimal digits.
+	// - https://www.postgresql.org/docs/current/catalog-pg-authid.html
+	// - https://www.postgresql.org/docs/current/sql-alterrole.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('ALTER ROLE %I PASSWORD %L', role.rolname, spec.verifier)
+  FROM input
+ CROSS JOIN pg_catalog.json_to_record(input.data) AS spec (username text, verifier text)
+  JOIN pg_catalog.pg_authid AS role ON role.rolname = spec.username
+ WHERE role.rolpassword LIKE 'md5%'
+ ORDER BY input.id
+\gexec
+`)
+
+	// Print the name of each role that still has an MD5 password.
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-PSET
+	_, _ = sql.WriteString(`
+\pset format unaligned
+\pset tuples_only on
+SELECT rolname FROM pg_catalog.pg_authid
+ WHERE rolpassword LIKE 'md5%'
+ ORDER BY rolname;
+`)
+
+	var stdout, stderr string
+	if err == nil {
+		stdout, stderr, err = exec.Exec(ctx, &sql,
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("rehashed PostgreSQL MD5 passwords", "stdout", stdout, "stderr", stderr)
+	}
+
+	var remaining []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			remaining = append(remaining, line)
+		}
+	}
+
+	return remaining, err
+}
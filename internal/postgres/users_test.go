@@ -235,3 +235,126 @@ func TestWriteUsersSchemasInPostgreSQL(t *testing.T) {
 	})
 
 }
+
+func TestDropUsersInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		_, err := DropUsersInPostgreSQL(ctx, exec, []string{"any"}, "")
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Retained", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"username":"one"}
+{"username":"two"}
+\.
+`))
+			assert.Assert(t, cmp.Contains(string(b),
+				`WHERE target.owner AND target.reassign IS NULL`))
+
+			_, _ = io.WriteString(stdout, "one\ntwo\n")
+			return nil
+		}
+
+		retained, err := DropUsersInPostgreSQL(ctx, exec, []string{"one", "two"}, "")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, retained, []string{"one", "two"})
+		assert.Equal(t, calls, 1, "expected nothing to drop")
+	})
+
+	t.Run("Reassign", func(t *testing.T) {
+		var scripts []string
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			scripts = append(scripts, string(b))
+
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"reassign":"owner","username":"one"}
+\.
+`))
+			return nil
+		}
+
+		retained, err := DropUsersInPostgreSQL(ctx, exec, []string{"one"}, "owner")
+		assert.NilError(t, err)
+		assert.Assert(t, retained == nil)
+		assert.Equal(t, len(scripts), 3)
+
+		assert.Assert(t, cmp.Contains(scripts[1], `REASSIGN OWNED BY %I TO %I`))
+		assert.Assert(t, cmp.Contains(scripts[1], `DROP OWNED BY %I`))
+		assert.Assert(t, cmp.Contains(scripts[2], `DROP ROLE %I`))
+	})
+}
+
+func TestRehashMD5UsersInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		_, err := RehashMD5UsersInPostgreSQL(ctx, exec, nil)
+		assert.Equal(t, expected, err)
+	})
+
+	t.Run("Verifiers", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"username":"one","verifier":"SCRAM-SHA-256$1:salt$stored:server"}
+{"username":"two","verifier":"SCRAM-SHA-256$2:salt$stored:server"}
+\.
+`), "expected only SCRAM verifiers, in order")
+			assert.Assert(t, cmp.Contains(string(b), `WHERE role.rolpassword LIKE 'md5%'`))
+
+			_, _ = io.WriteString(stdout, "legacy\n")
+			return nil
+		}
+
+		remaining, err := RehashMD5UsersInPostgreSQL(ctx, exec, map[string]string{
+			"two":   "SCRAM-SHA-256$2:salt$stored:server",
+			"md5":   "md55f4dcc3b5aa765d61d8327deb882cf99",
+			"empty": "",
+			"one":   "SCRAM-SHA-256$1:salt$stored:server",
+		})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, remaining, []string{"legacy"})
+		assert.Equal(t, calls, 1)
+	})
+}
@@ -419,6 +419,85 @@ func TestPostgresConfigParameters(t *testing.T) {
 	})
 }
 
+func TestPostgresInstanceConfigParameters(t *testing.T) {
+	ctx := context.Background()
+	cc := require.Kubernetes(t)
+	t.Parallel()
+
+	namespace := require.Namespace(t, cc)
+	base := v1beta1.NewPostgresCluster()
+
+	// Start with a bunch of required fields.
+	require.UnmarshalInto(t, &base.Spec, `{
+		postgresVersion: 16,
+		backups: {
+			pgbackrest: {
+				repos: [{ name: repo1 }],
+			},
+		},
+		instances: [{
+			dataVolumeClaimSpec: {
+				accessModes: [ReadWriteOnce],
+				resources: { requests: { storage: 1Mi } },
+			},
+		}],
+	}`)
+
+	base.Namespace = namespace.Name
+	base.Name = "postgres-instance-config-parameters"
+
+	assert.NilError(t, cc.Create(ctx, base.DeepCopy(), client.DryRunAll),
+		"expected this base cluster to be valid")
+
+	t.Run("Allowed", func(t *testing.T) {
+		for _, tt := range []struct {
+			key   string
+			value intstr.IntOrString
+		}{
+			{"hot_standby_feedback", intstr.FromString("on")},
+			{"max_parallel_workers", intstr.FromInt32(16)},
+			{"work_mem", intstr.FromString("64MB")},
+		} {
+			t.Run(tt.key, func(t *testing.T) {
+				cluster := base.DeepCopy()
+				cluster.Spec.InstanceSets[0].Config = &v1beta1.PostgresInstanceConfigSpec{
+					Parameters: map[string]intstr.IntOrString{tt.key: tt.value},
+				}
+
+				assert.NilError(t, cc.Create(ctx, cluster, client.DryRunAll))
+			})
+		}
+	})
+
+	t.Run("Disallowed", func(t *testing.T) {
+		for _, key := range []string{
+			"archive_command",
+			"cluster_name",
+			"data_directory",
+			"hot_standby",
+			"listen_addresses",
+			"max_connections",
+			"max_wal_senders",
+			"max_worker_processes",
+			"primary_conninfo",
+			"recovery_min_apply_delay",
+			"shared_preload_libraries",
+			"ssl_ciphers",
+			"wal_level",
+		} {
+			t.Run(key, func(t *testing.T) {
+				cluster := base.DeepCopy()
+				cluster.Spec.InstanceSets[0].Config = &v1beta1.PostgresInstanceConfigSpec{
+					Parameters: map[string]intstr.IntOrString{key: intstr.FromString("x")},
+				}
+
+				err := cc.Create(ctx, cluster, client.DryRunAll)
+				assert.Assert(t, apierrors.IsInvalid(err))
+			})
+		}
+	})
+}
+
 func TestPostgresUserOptions(t *testing.T) {
 	ctx := context.Background()
 	cc := require.Kubernetes(t)
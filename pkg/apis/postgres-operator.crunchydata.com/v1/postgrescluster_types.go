@@ -61,6 +61,15 @@ type PostgresClusterSpec struct {
 	// namespace as the cluster.
 	// +optional
 	DatabaseInitSQL *DatabaseInitSQL `json:"databaseInitSQL,omitempty"`
+
+	// Databases to create inside PostgreSQL. Removing a database from this
+	// list does NOT drop the database.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	Databases []v1beta1.PostgresDatabaseSpec `json:"databases,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should use the defined default
 	// scheduling constraints. If the field is unset or false, the default
 	// scheduling constraints will be used in addition to any custom constraints
@@ -68,6 +77,14 @@ type PostgresClusterSpec struct {
 	// +optional
 	DisableDefaultPodScheduling *bool `json:"disableDefaultPodScheduling,omitempty"`
 
+	// Extensions to install in every database. Removing an extension from
+	// this list does NOT drop the extension.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Extensions []v1beta1.PostgresExtensionSpec `json:"extensions,omitempty"`
+
 	// The image name to use for PostgreSQL containers. When omitted, the value
 	// comes from an operator environment variable. For standard PostgreSQL images,
 	// the format is RELATED_IMAGE_POSTGRES_{postgresVersion},
@@ -110,6 +127,13 @@ type PostgresClusterSpec struct {
 	// +optional
 	Instrumentation *v1beta1.InstrumentationSpec `json:"instrumentation,omitempty"`
 
+	// When the operator may restart PostgreSQL and redeploy instances to apply
+	// changes. Outside this window, parameters that require a restart remain
+	// pending and instances keep running with their current Pod template.
+	// When unset, changes are applied immediately.
+	// +optional
+	MaintenanceWindow *v1beta1.MaintenanceWindowSpec `json:"maintenanceWindow,omitempty"`
+
 	// Whether or not the PostgreSQL cluster is being deployed to an OpenShift
 	// environment. If the field is unset, the operator will automatically
 	// detect the environment.
@@ -146,6 +170,16 @@ type PostgresClusterSpec struct {
 	// +optional
 	Proxy *PostgresProxySpec `json:"proxy,omitempty"`
 
+	// Logical replication publications to create inside PostgreSQL. Changes
+	// to the tables of a publication are applied to existing publications.
+	// Removing a publication from this list does NOT drop the publication.
+	// More info: https://www.postgresql.org/docs/current/logical-replication-publication.html
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Publications []v1beta1.PostgresPublicationSpec `json:"publications,omitempty"`
+
 	// The specification of a user interface that connects to PostgreSQL. -- DEPRECATED
 	// +optional
 	// +kubebuilder:validation:XValidation:rule="type(self) == null_type", message="userInterface not available in v1"
@@ -163,6 +197,11 @@ type PostgresClusterSpec struct {
 	// +optional
 	ReplicaService *v1beta1.ServiceSpec `json:"replicaService,omitempty"`
 
+	// How instances are redeployed when their Pod template changes, such as
+	// when the image changes for a minor release of PostgreSQL.
+	// +optional
+	Rollout *v1beta1.RolloutSpec `json:"rollout,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should be stopped.
 	// When this is true, workloads are scaled to zero and CronJobs
 	// are suspended.
@@ -174,6 +213,17 @@ type PostgresClusterSpec struct {
 	// +optional
 	Standby *PostgresStandbySpec `json:"standby,omitempty"`
 
+	// Logical replication subscriptions to create inside PostgreSQL. Each
+	// one receives changes from publications of another PostgresCluster in
+	// the same namespace. Removing a subscription from this list does NOT
+	// drop the subscription.
+	// More info: https://www.postgresql.org/docs/current/logical-replication-subscription.html
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Subscriptions []v1beta1.PostgresSubscriptionSpec `json:"subscriptions,omitempty"`
+
 	// A list of group IDs applied to the process of a container. These can be
 	// useful when accessing shared file systems with constrained permissions.
 	// More info: https://kubernetes.io/docs/reference/kubernetes-api/workload-resources/pod-v1/#security-context
@@ -190,10 +240,16 @@ type PostgresClusterSpec struct {
 	// +kubebuilder:validation:items:Maximum=2147483647
 	SupplementalGroups []int64 `json:"supplementalGroups,omitempty"`
 
+	// What happens to PostgreSQL users that are removed from the users field.
+	// The default keeps them in PostgreSQL.
+	// +optional
+	UserDeletion *v1beta1.PostgresUserDeletionSpec `json:"userDeletion,omitempty"`
+
 	// Users to create inside PostgreSQL and the databases they should access.
 	// The default creates one user that can access one database matching the
 	// PostgresCluster name. An empty list creates no users. Removing a user
-	// from this list does NOT drop the user nor revoke their access.
+	// from this list does NOT drop the user nor revoke their access unless
+	// the userDeletion policy is "Drop".
 	// +listType=map
 	// +listMapKey=name
 	// +kubebuilder:validation:MaxItems=64
@@ -356,12 +412,31 @@ type PostgresClusterStatus struct {
 	// Identifies the databases that have been installed into PostgreSQL.
 	DatabaseRevision string `json:"databaseRevision,omitempty"`
 
+	// The connection limits of databases whose connections are stopped. Each
+	// is restored when connections to its database start again.
+	// +optional
+	StoppedConnectionLimits map[string]int32 `json:"stoppedConnectionLimits,omitempty"`
+
+	// The extensions installed in each database.
+	// +listType=atomic
+	// +optional
+	Extensions []v1beta1.PostgresExtensionStatus `json:"extensions,omitempty"`
+
+	// Identifies the publications and subscriptions that have been written
+	// to PostgreSQL.
+	// +optional
+	LogicalReplicationRevision string `json:"logicalReplicationRevision,omitempty"`
+
 	// Current state of PostgreSQL instances.
 	// +listType=map
 	// +listMapKey=name
 	// +optional
 	InstanceSets []PostgresInstanceSetStatus `json:"instances,omitempty"`
 
+	// When replication statistics were last queried from the primary.
+	// +optional
+	ReplicationObservedTime *metav1.Time `json:"replicationObservedTime,omitempty"`
+
 	// +optional
 	Patroni v1beta1.PatroniStatus `json:"patroni,omitempty"`
 
@@ -393,6 +468,16 @@ type PostgresClusterStatus struct {
 	// +optional
 	StartupInstanceSet string `json:"startupInstanceSet,omitempty"`
 
+	// The state of subscriptions in spec.subscriptions that exist in PostgreSQL.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Subscriptions []v1beta1.PostgresSubscriptionStatus `json:"subscriptions,omitempty"`
+
+	// When the state of subscriptions was last queried from PostgreSQL.
+	// +optional
+	SubscriptionsObservedTime *metav1.Time `json:"subscriptionsObservedTime,omitempty"`
+
 	// Current state of the PostgreSQL user interface.
 	// +optional
 	UserInterface *PostgresUserInterfaceStatus `json:"userInterface,omitempty"`
@@ -400,6 +485,17 @@ type PostgresClusterStatus struct {
 	// Identifies the users that have been installed into PostgreSQL.
 	UsersRevision string `json:"usersRevision,omitempty"`
 
+	// The PostgreSQL users that have generated passwords.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Users []v1beta1.PostgresUserStatus `json:"users,omitempty"`
+
+	// The value of the "postgres-operator.crunchydata.com/rotate-passwords"
+	// annotation when passwords were last rotated on demand.
+	// +optional
+	PasswordRotation *string `json:"passwordRotation,omitempty"`
+
 	// Current state of PostgreSQL cluster monitoring tool configuration
 	// +optional
 	Monitoring MonitoringStatus `json:"monitoring,omitempty"`
@@ -414,8 +510,9 @@ type PostgresClusterStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
 	// conditions represent the observations of postgrescluster's current state.
-	// Known .status.conditions.type are: "PersistentVolumeResizing",
-	// "Progressing", "ProxyAvailable"
+	// Known .status.conditions.type are: "InstanceReinitializing", "MD5Passwords",
+	// "MissingReplicaIdentity", "MissingSchemas", "PersistentVolumeResizing",
+	// "Progressing", "ProxyAvailable", "SwitchoverOnCordon", "SynchronousReplication"
 	// +optional
 	// +listType=map
 	// +listMapKey=type
@@ -425,13 +522,21 @@ type PostgresClusterStatus struct {
 
 // PostgresClusterStatus condition types.
 const (
+	InstanceReinitializing      = "InstanceReinitializing"
+	MD5Passwords                = "MD5Passwords"
+	MissingReplicaIdentity      = "MissingReplicaIdentity"
+	MissingSchemas              = "MissingSchemas"
 	PersistentVolumeResizing    = "PersistentVolumeResizing"
 	PersistentVolumeResizeError = "PersistentVolumeResizeError"
 	PostgresClusterProgressing  = "Progressing"
 	ProxyAvailable              = "ProxyAvailable"
 	Registered                  = "Registered"
+	SwitchoverOnCordon          = "SwitchoverOnCordon"
+	SynchronousReplication      = "SynchronousReplication"
 )
 
+// ---
+// +kubebuilder:validation:XValidation:rule=`!has(self.tags) || !has(self.tags.replicateFrom) || !has(self.name) || self.tags.replicateFrom != self.name`,message="an instance set cannot replicate from itself"
 type PostgresInstanceSetSpec struct {
 	// +optional
 	Metadata *v1beta1.Metadata `json:"metadata,omitempty"`
@@ -465,6 +570,10 @@ type PostgresInstanceSetSpec struct {
 	// +optional
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 
+	// PostgreSQL configuration for instances in this set.
+	// +optional
+	Config *v1beta1.PostgresInstanceConfigSpec `json:"config,omitempty"`
+
 	// Custom sidecars for PostgreSQL instance pods. Changing this value causes
 	// PostgreSQL to restart.
 	// +optional
@@ -476,6 +585,14 @@ type PostgresInstanceSetSpec struct {
 	// +required
 	DataVolumeClaimSpec v1beta1.VolumeClaimSpec `json:"dataVolumeClaimSpec"`
 
+	// The image name to use for PostgreSQL containers in this set. When omitted,
+	// the value comes from spec.image. The image must contain the same major
+	// version of PostgreSQL as spec.postgresVersion. When the image tag names
+	// a different major version, the operator reports a warning event.
+	// Changing this value causes PostgreSQL to restart.
+	// +optional
+	Image string `json:"image,omitempty"`
+
 	// Priority class name for the PostgreSQL pod. Changing this value causes
 	// PostgreSQL to restart.
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/pod-priority-preemption/
@@ -501,6 +618,11 @@ type PostgresInstanceSetSpec struct {
 	// +optional
 	Sidecars *InstanceSidecars `json:"sidecars,omitempty"`
 
+	// Patroni tags that control failover, replica creation, and load balancing
+	// for instances in this set. Changing this value causes PostgreSQL to restart.
+	// +optional
+	Tags *v1beta1.PatroniTagsSpec `json:"tags,omitempty"`
+
 	// Tolerations of a PostgreSQL pod. Changing this value causes PostgreSQL to restart.
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/taint-and-toleration
 	// +optional
@@ -593,9 +715,21 @@ type PostgresInstanceSetStatus struct {
 	// +optional
 	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
 
+	// The image name of PostgreSQL containers in this set. This is empty while
+	// its Pods are running different images.
+	// +optional
+	Image string `json:"image,omitempty"`
+
 	// Desired Size of the pgData volume
 	// +optional
 	DesiredPGDataVolume map[string]string `json:"desiredPGDataVolume,omitempty"`
+
+	// The PostgreSQL instances of this set as last reported by Patroni and
+	// the primary.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Members []v1beta1.PostgresMemberStatus `json:"members,omitempty"`
 }
 
 // PostgresProxySpec is a union of the supported PostgreSQL proxies.
@@ -318,11 +318,25 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(DatabaseInitSQL)
 		**out = **in
 	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]v1beta1.PostgresDatabaseSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.DisableDefaultPodScheduling != nil {
 		in, out := &in.DisableDefaultPodScheduling, &out.DisableDefaultPodScheduling
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]v1beta1.PostgresExtensionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
 		*out = make([]corev1.LocalObjectReference, len(*in))
@@ -340,6 +354,11 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(v1beta1.InstrumentationSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(v1beta1.MaintenanceWindowSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.OpenShift != nil {
 		in, out := &in.OpenShift, &out.OpenShift
 		*out = new(bool)
@@ -365,6 +384,13 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(PostgresProxySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]v1beta1.PostgresPublicationSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.UserInterface != nil {
 		in, out := &in.UserInterface, &out.UserInterface
 		*out = new(UserInterfaceSpec)
@@ -385,6 +411,11 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(v1beta1.ServiceSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(v1beta1.RolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Shutdown != nil {
 		in, out := &in.Shutdown, &out.Shutdown
 		*out = new(bool)
@@ -395,11 +426,23 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(PostgresStandbySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = make([]v1beta1.PostgresSubscriptionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.SupplementalGroups != nil {
 		in, out := &in.SupplementalGroups, &out.SupplementalGroups
 		*out = make([]int64, len(*in))
 		copy(*out, *in)
 	}
+	if in.UserDeletion != nil {
+		in, out := &in.UserDeletion, &out.UserDeletion
+		*out = new(v1beta1.PostgresUserDeletionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Users != nil {
 		in, out := &in.Users, &out.Users
 		*out = make([]v1beta1.PostgresUserSpec, len(*in))
@@ -422,6 +465,18 @@ func (in *PostgresClusterSpec) DeepCopy() *PostgresClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 	*out = *in
+	if in.StoppedConnectionLimits != nil {
+		in, out := &in.StoppedConnectionLimits, &out.StoppedConnectionLimits
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]v1beta1.PostgresExtensionStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.InstanceSets != nil {
 		in, out := &in.InstanceSets, &out.InstanceSets
 		*out = make([]PostgresInstanceSetStatus, len(*in))
@@ -429,6 +484,10 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReplicationObservedTime != nil {
+		in, out := &in.ReplicationObservedTime, &out.ReplicationObservedTime
+		*out = (*in).DeepCopy()
+	}
 	in.Patroni.DeepCopyInto(&out.Patroni)
 	if in.PGBackRest != nil {
 		in, out := &in.PGBackRest, &out.PGBackRest
@@ -441,11 +500,34 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 		**out = **in
 	}
 	out.Proxy = in.Proxy
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = make([]v1beta1.PostgresSubscriptionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubscriptionsObservedTime != nil {
+		in, out := &in.SubscriptionsObservedTime, &out.SubscriptionsObservedTime
+		*out = (*in).DeepCopy()
+	}
 	if in.UserInterface != nil {
 		in, out := &in.UserInterface, &out.UserInterface
 		*out = new(PostgresUserInterfaceStatus)
 		**out = **in
 	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]v1beta1.PostgresUserStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PasswordRotation != nil {
+		in, out := &in.PasswordRotation, &out.PasswordRotation
+		*out = new(string)
+		**out = **in
+	}
 	out.Monitoring = in.Monitoring
 	if in.DatabaseInitSQL != nil {
 		in, out := &in.DatabaseInitSQL, &out.DatabaseInitSQL
@@ -484,6 +566,11 @@ func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(v1beta1.PostgresInstanceConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Containers != nil {
 		in, out := &in.Containers, &out.Containers
 		*out = make([]corev1.Container, len(*in))
@@ -513,6 +600,11 @@ func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 		*out = new(InstanceSidecars)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = new(v1beta1.PatroniTagsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]corev1.Toleration, len(*in))
@@ -565,6 +657,13 @@ func (in *PostgresInstanceSetStatus) DeepCopyInto(out *PostgresInstanceSetStatus
 			(*out)[key] = val
 		}
 	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]v1beta1.PostgresMemberStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceSetStatus.
@@ -4,7 +4,11 @@
 
 package v1beta1
 
-import "k8s.io/apimachinery/pkg/api/resource"
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 type PatroniSpec struct {
 	// Patroni dynamic configuration settings. Changes to this value will be
@@ -29,6 +33,13 @@ type PatroniSpec struct {
 	// +optional
 	Logging *PatroniLogConfig `json:"logging,omitempty"`
 
+	// Where the operator prefers the primary instance to run. Switchovers
+	// without a targetInstance choose a replica that matches. When the primary
+	// runs elsewhere, such as after a failover, the operator switches over to
+	// a ready replica that matches during the maintenance window.
+	// +optional
+	PreferredPrimary *PatroniPreferredPrimarySpec `json:"preferredPrimary,omitempty"`
+
 	// The port on which Patroni should listen.
 	// Changing this value causes PostgreSQL to restart.
 	// +optional
@@ -36,6 +47,23 @@ type PatroniSpec struct {
 	// +kubebuilder:validation:Minimum=1024
 	Port *int32 `json:"port,omitempty"`
 
+	// Replication slots that Patroni creates on the primary and keeps on
+	// replicas so they remain after a switchover or failover. Logical slots
+	// require PostgreSQL 11 or later. Setting this turns on "use_slots" in
+	// Patroni, which also gives each replica a physical slot on the primary.
+	// These take precedence over slots in dynamicConfiguration.
+	// More info: https://patroni.readthedocs.io/en/latest/dynamic_configuration.html
+	// ---
+	// +kubebuilder:validation:MaxItems=20
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Slots []PatroniSlotSpec `json:"slots,omitempty"`
+
+	// Limits on the WAL that replication slots can hold on the primary.
+	// +optional
+	SlotRetention *PatroniSlotRetentionSpec `json:"slotRetention,omitempty"`
+
 	// The interval for refreshing the leader lock and applying
 	// dynamicConfiguration. Must be less than leaderLeaseDurationSeconds.
 	// Changing this value causes PostgreSQL to restart.
@@ -44,10 +72,27 @@ type PatroniSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	SyncPeriodSeconds *int32 `json:"syncPeriodSeconds,omitempty"`
 
+	// Whether or not the operator switches over to a replica on another Node
+	// when the Node of the primary becomes unschedulable, such as when it is
+	// cordoned before a drain. This avoids a failover when the primary is
+	// evicted later. A PodDisruptionBudget keeps the primary from being
+	// evicted until then. This requires the "SwitchoverOnCordon" feature gate.
+	// The "SwitchoverOnCordon" condition reports when this cannot happen.
+	// +optional
+	SwitchoverOnCordon *bool `json:"switchoverOnCordon,omitempty"`
+
 	// Switchover gives options to perform ad hoc switchovers in a PostgresCluster.
 	// +optional
 	Switchover *PatroniSwitchover `json:"switchover,omitempty"`
 
+	// Synchronous replication settings. When this is set, transactions on the
+	// primary wait for their changes to be written to one or more replicas.
+	// These settings take precedence over synchronous settings in
+	// dynamicConfiguration.
+	// More info: https://patroni.readthedocs.io/en/latest/replication_modes.html
+	// +optional
+	Synchronous *PatroniSynchronousSpec `json:"synchronous,omitempty"`
+
 	// TODO(cbandy): Add UseConfigMaps bool, default false.
 	// TODO(cbandy): Allow other DCS: etcd, raft, etc?
 	// N.B. changing this will cause downtime.
@@ -86,7 +131,8 @@ type PatroniSwitchover struct {
 
 	// The instance that should become primary during a switchover. This field is
 	// optional when Type is "Switchover" and required when Type is "Failover".
-	// When it is not specified, a healthy replica is automatically selected.
+	// When it is not specified, the operator selects a ready replica with the
+	// least replication lag, preferring those that match preferredPrimary.
 	// +optional
 	TargetInstance *string `json:"targetInstance,omitempty"`
 
@@ -104,6 +150,65 @@ type PatroniSwitchover struct {
 	// +kubebuilder:default:=Switchover
 	// +optional
 	Type string `json:"type,omitempty"`
+
+	// When set, a requested switchover waits until this time. The operator
+	// chooses a candidate when the time arrives, so it is based on the
+	// replication lag at that time.
+	// ---
+	// +kubebuilder:validation:Format=date-time
+	// +optional
+	ScheduledTime *metav1.Time `json:"scheduledTime,omitempty"`
+}
+
+type PatroniPreferredPrimarySpec struct {
+	// The topology zone, as in the "topology.kubernetes.io/zone" label of
+	// Nodes, where the primary instance should run.
+	// More info: https://kubernetes.io/docs/reference/labels-annotations-taints/#topologykubernetesiozone
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Labels that the Node of the primary instance should have.
+	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node/#nodeselector
+	// ---
+	// +mapType=atomic
+	// +kubebuilder:validation:MaxProperties=10
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// The minimum time between switchovers that move the primary back to
+	// where it is preferred. Defaults to one hour.
+	// ---
+	// +kubebuilder:validation:Pattern=`^(PT)?( *[0-9]+ *(?i:(m|h|hr|d)|(min|hour|day)s?))+$`
+	//
+	// `controller-gen` needs to know "Type=string" to allow a "Pattern".
+	// +kubebuilder:validation:Type=string
+	//
+	// Set a max length to keep rule costs low.
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:XValidation:rule=`duration("5m") <= self && self <= duration("168h")`,message="must be between five minutes and one week"
+	//
+	// +optional
+	Interval *Duration `json:"interval,omitempty"`
+}
+
+// Matches reports whether or not a Node with labels is where p prefers the
+// primary instance to run. It returns false when p has no preference.
+func (p *PatroniPreferredPrimarySpec) Matches(labels map[string]string) bool {
+	if p == nil || (p.Zone == "" && len(p.NodeSelector) == 0) {
+		return false
+	}
+	if p.Zone != "" && labels[corev1.LabelTopologyZone] != p.Zone {
+		return false
+	}
+	for k, v := range p.NodeSelector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
 }
 
 // PatroniSwitchover types.
@@ -112,6 +217,187 @@ const (
 	PatroniSwitchoverTypeSwitchover = "Switchover"
 )
 
+// ---
+// +kubebuilder:validation:XValidation:rule=`self.type == "Logical" ? has(self.database) : !has(self.database) && !has(self.plugin)`,message="logical slots require a database; physical slots have neither database nor plugin"
+type PatroniSlotSpec struct {
+	// The name of this replication slot.
+	// ---
+	// Replication slot names may contain only lowercase letters, numbers,
+	// and underscore.
+	// - https://www.postgresql.org/docs/current/warm-standby.html#STREAMING-REPLICATION-SLOTS-MANIPULATION
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9_]+$`
+	// +required
+	Name string `json:"name"`
+
+	// The type of this replication slot. "Logical" slots decode changes in
+	// one database for clients such as Debezium. "Physical" slots retain WAL
+	// for standbys outside of this cluster.
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:MaxLength=15
+	//
+	// +kubebuilder:validation:Enum={Logical,Physical}
+	// +required
+	Type string `json:"type"`
+
+	// The database of this logical replication slot.
+	// ---
+	// +optional
+	Database PostgresIdentifier `json:"database,omitempty"`
+
+	// The output plugin of this logical replication slot. Defaults to "pgoutput".
+	// More info: https://www.postgresql.org/docs/current/logicaldecoding-output-plugin.html
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9_]+$`
+	// +optional
+	Plugin string `json:"plugin,omitempty"`
+}
+
+// PatroniSlotSpec types.
+const (
+	PatroniSlotTypeLogical  = "Logical"
+	PatroniSlotTypePhysical = "Physical"
+)
+
+type PatroniSlotRetentionSpec struct {
+	// The amount of WAL a replication slot can hold on the primary before
+	// it is reported.
+	// More info: https://kubernetes.io/docs/reference/kubernetes-api/common-definitions/quantity
+	// ---
+	// +required
+	MaxWAL *resource.Quantity `json:"maxWAL"`
+
+	// What happens to a slot that holds more WAL than maxWAL. "Report" creates
+	// a Warning event and lists the slot in status. "Drop" also drops the slot
+	// when no client is connected to it. Patroni creates permanent slots again
+	// at the current position, so their clients miss any changes in between.
+	// The slots Patroni keeps for its replicas are only reported.
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:MaxLength=15
+	//
+	// +kubebuilder:default=Report
+	// +kubebuilder:validation:Enum={Report,Drop}
+	// +optional
+	Policy string `json:"policy,omitempty"`
+}
+
+// PatroniSlotRetentionSpec policies.
+const (
+	PatroniSlotRetentionDrop   = "Drop"
+	PatroniSlotRetentionReport = "Report"
+)
+
+type PatroniSynchronousSpec struct {
+	// How Patroni chooses synchronous replicas. "Priority" waits for the
+	// first replicas in a list Patroni maintains. "Quorum" waits for any of
+	// the replicas and requires Patroni v4 or later.
+	// More info: https://www.postgresql.org/docs/current/runtime-config-replication.html#GUC-SYNCHRONOUS-STANDBY-NAMES
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:MaxLength=15
+	//
+	// +kubebuilder:default=Priority
+	// +kubebuilder:validation:Enum={Priority,Quorum}
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// The number of replicas that must confirm each transaction. This should
+	// be no more than the number of replicas across all instance sets. When
+	// fewer replicas are available, Patroni makes all of them synchronous.
+	// When none are available, the primary waits for one if strict is true
+	// and otherwise accepts writes without any. The "SynchronousReplication"
+	// condition is false while the instance sets have too few replicas.
+	// ---
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Standbys *int32 `json:"standbys,omitempty"`
+
+	// Whether to stop writes when fewer than the number of synchronous
+	// replicas are available. When false, the primary accepts writes without
+	// synchronous replicas until they return.
+	// ---
+	// +kubebuilder:default=false
+	// +optional
+	Strict *bool `json:"strict,omitempty"`
+}
+
+// PatroniTagsSpec describes how Patroni treats the instances of one set.
+// More info: https://patroni.readthedocs.io/en/latest/yaml_configuration.html#tags
+type PatroniTagsSpec struct {
+	// The name of another instance set from which replicas in this set stream
+	// changes, rather than from the primary. Replicas follow the first instance
+	// of that set, by name, and follow the primary while that instance is not
+	// running.
+	// More info: https://patroni.readthedocs.io/en/latest/replication_modes.html#cascading-replication
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	ReplicateFrom string `json:"replicateFrom,omitempty"`
+
+	// Whether or not instances in this set are excluded from the replica
+	// Service. The other instances are labeled so the Service selects them.
+	// ---
+	// +optional
+	NoLoadBalance *bool `json:"noLoadBalance,omitempty"`
+
+	// Whether or not instances in this set can be promoted to primary.
+	// ---
+	// +optional
+	NoFailover *bool `json:"noFailover,omitempty"`
+
+	// How long replicas in this set wait before applying changes from the
+	// primary. Delayed replicas are never promoted and are excluded from the
+	// replica Service.
+	// More info: https://www.postgresql.org/docs/current/runtime-config-replication.html#GUC-RECOVERY-MIN-APPLY-DELAY
+	// ---
+	// Accept the units used by other durations in this API and reject fractions.
+	// +kubebuilder:validation:Pattern=`^((PT)?( *[0-9]+ *(?i:(s|m|h|hr|d)|(sec|min|hour|day)s?))+|0)$`
+	//
+	// `controller-gen` needs to know "Type=string" to allow a "Pattern".
+	// +kubebuilder:validation:Type=string
+	//
+	// Set a max length to keep rule costs low.
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:XValidation:rule=`duration("0") <= self && self <= duration("168h")`,message="must be at most one week"
+	//
+	// +optional
+	ReplicationDelay *Duration `json:"replicationDelay,omitempty"`
+}
+
+// NoFailoverOrDelay reports whether instances described by t can never be
+// promoted to primary.
+func (t *PatroniTagsSpec) NoFailoverOrDelay() bool {
+	return t != nil && ((t.NoFailover != nil && *t.NoFailover) || t.Delayed())
+}
+
+// NoLoadBalanceOrDelay reports whether instances described by t are excluded
+// from the replica Service.
+func (t *PatroniTagsSpec) NoLoadBalanceOrDelay() bool {
+	return t != nil && ((t.NoLoadBalance != nil && *t.NoLoadBalance) || t.Delayed())
+}
+
+// Delayed reports whether instances described by t apply changes after a delay.
+func (t *PatroniTagsSpec) Delayed() bool {
+	return t != nil && t.ReplicationDelay != nil && t.ReplicationDelay.AsDuration().Duration > 0
+}
+
+// PatroniSynchronousSpec modes.
+const (
+	PatroniSynchronousModePriority = "Priority"
+	PatroniSynchronousModeQuorum   = "Quorum"
+)
+
 // Default sets the default values for certain Patroni configuration attributes,
 // including:
 // - Lock Lease Duration
@@ -149,4 +435,92 @@ type PatroniStatus struct {
 	// Tracks the current timeline during switchovers
 	// +optional
 	SwitchoverTimeline *int64 `json:"switchoverTimeline,omitempty"`
+
+	// The most recent switchover requested with the trigger annotation.
+	// +optional
+	LastSwitchover *PatroniSwitchoverStatus `json:"lastSwitchover,omitempty"`
+
+	// When the operator last switched over to move the primary to where it
+	// is preferred.
+	// +optional
+	PreferredPrimarySwitchoverTime *metav1.Time `json:"preferredPrimarySwitchoverTime,omitempty"`
+
+	// Replication slots on the primary that hold more WAL than slotRetention.maxWAL.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	SlotsRetainingWAL []PatroniSlotStatus `json:"slotsRetainingWAL,omitempty"`
+
+	// When replication slots were last queried from the primary.
+	// +optional
+	SlotsObservedTime *metav1.Time `json:"slotsObservedTime,omitempty"`
+
+	// The instances that Patroni currently considers synchronous replicas.
+	// +listType=atomic
+	// +optional
+	SynchronousStandbys []string `json:"synchronousStandbys,omitempty"`
+}
+
+type PatroniSwitchoverStatus struct {
+	// The value of the trigger annotation that requested this switchover.
+	// +required
+	Trigger string `json:"trigger"`
+
+	// The type of switchover: "Switchover" or "Failover".
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// The instance Pod that was asked to become primary. When this is empty,
+	// Patroni chose a replica.
+	// +optional
+	Candidate string `json:"candidate,omitempty"`
+
+	// When the switchover was scheduled to happen.
+	// +optional
+	ScheduledTime *metav1.Time `json:"scheduledTime,omitempty"`
+
+	// When the operator asked Patroni to change the primary.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// When Patroni finished changing the primary or gave up.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The result of this switchover: "Scheduled", "Succeeded", or "Failed".
+	// +optional
+	Outcome string `json:"outcome,omitempty"`
+
+	// Details about a failed switchover.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
+
+type PatroniSlotStatus struct {
+	// The name of this replication slot.
+	// +required
+	Name string `json:"name"`
+
+	// The type of this replication slot: "logical" or "physical".
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Whether or not a client was connected to this slot.
+	// +optional
+	Active bool `json:"active,omitempty"`
+
+	// Bytes of WAL this slot held on the primary.
+	// +optional
+	RetainedBytes *int64 `json:"retainedBytes,omitempty"`
+
+	// The last time these details were queried from PostgreSQL.
+	// +optional
+	ObservedTime *metav1.Time `json:"observedTime,omitempty"`
+}
+
+// PatroniSwitchoverStatus outcomes.
+const (
+	PatroniSwitchoverFailed    = "Failed"
+	PatroniSwitchoverScheduled = "Scheduled"
+	PatroniSwitchoverSucceeded = "Succeeded"
+)
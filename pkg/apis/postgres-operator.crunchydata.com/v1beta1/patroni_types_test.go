@@ -0,0 +1,36 @@
+// Copyright 2021 - 2025 Crunchy Data Solutions, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPatroniPreferredPrimarySpecMatches(t *testing.T) {
+	labels := map[string]string{
+		"topology.kubernetes.io/zone": "east",
+		"disk":                        "fast",
+	}
+
+	var spec *PatroniPreferredPrimarySpec
+	assert.Assert(t, !spec.Matches(labels), "expected no preference")
+	assert.Assert(t, !new(PatroniPreferredPrimarySpec).Matches(labels), "expected no preference")
+
+	spec = &PatroniPreferredPrimarySpec{Zone: "east"}
+	assert.Assert(t, spec.Matches(labels))
+	assert.Assert(t, !spec.Matches(nil))
+
+	spec.NodeSelector = map[string]string{"disk": "fast"}
+	assert.Assert(t, spec.Matches(labels))
+
+	spec.NodeSelector["disk"] = "slow"
+	assert.Assert(t, !spec.Matches(labels))
+
+	spec = &PatroniPreferredPrimarySpec{NodeSelector: map[string]string{"disk": "fast"}}
+	assert.Assert(t, spec.Matches(labels))
+	assert.Assert(t, !spec.Matches(map[string]string{"disk": ""}))
+}
@@ -10,6 +10,13 @@ import (
 )
 
 // PGUpgradeSpec defines the desired state of PGUpgrade
+// ---
+// +kubebuilder:validation:XValidation:rule=`!has(self.method) || self.method != "LogicalReplication" || has(self.logicalReplication)`,message="logicalReplication is required when method is LogicalReplication"
+// +kubebuilder:validation:XValidation:rule=`!has(self.check) || !has(self.method) || self.method == "PGUpgrade"`,message="check is only available when method is PGUpgrade"
+// +kubebuilder:validation:XValidation:rule=`!has(self.postUpgrade) || !has(self.method) || self.method == "PGUpgrade"`,message="postUpgrade is only available when method is PGUpgrade"
+// +kubebuilder:validation:XValidation:rule=`!has(self.retention) || !has(self.method) || self.method == "PGUpgrade"`,message="retention is only available when method is PGUpgrade"
+// +kubebuilder:validation:XValidation:rule=`!has(self.retention) || !has(self.retention.action) || self.retention.action != "Rollback" || (has(self.transferMethod) && self.transferMethod != "Link") || has(self.retention.volumeSnapshotClassName)`,message="Rollback after a Link upgrade requires retention.volumeSnapshotClassName"
+// +kubebuilder:validation:XValidation:rule=`!has(self.replicaMethod) || self.replicaMethod != "Rsync" || ((!has(self.method) || self.method == "PGUpgrade") && (!has(self.transferMethod) || self.transferMethod == "Link"))`,message="Rsync is only available when method is PGUpgrade and transferMethod is Link"
 type PGUpgradeSpec struct {
 
 	// +optional
@@ -21,6 +28,67 @@ type PGUpgradeSpec struct {
 	// +required
 	PostgresClusterName string `json:"postgresClusterName"`
 
+	// How to upgrade the cluster. "PGUpgrade" stops the cluster and upgrades
+	// its data directory in place using pg_upgrade. "LogicalReplication"
+	// copies the data of the running cluster to a new PostgresCluster at the
+	// new version, then moves the primary Service and PgBouncer of the cluster
+	// to the new one. Defaults to "PGUpgrade".
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:MaxLength=20
+	//
+	// +kubebuilder:validation:Enum={PGUpgrade,LogicalReplication}
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Settings for upgrading through logical replication.
+	// ---
+	// +optional
+	LogicalReplication *PGUpgradeLogicalReplicationSpec `json:"logicalReplication,omitempty"`
+
+	// Run only "pg_upgrade --check" against a copy of the data volumes of the
+	// primary while the cluster keeps running. When instances have a separate
+	// WAL volume or tablespace volumes, the cluster must be shut down so the
+	// copies agree with one another. Incompatibilities are reported in the
+	// Succeeded condition. Remove this field to perform the upgrade.
+	// ---
+	// +optional
+	Check *PGUpgradeCheckSpec `json:"check,omitempty"`
+
+	// Tasks to run after the upgrade, once the cluster is running at
+	// toPostgresVersion. The upgrade succeeds when they are done.
+	// ---
+	// +optional
+	PostUpgrade *PGUpgradePostUpgradeSpec `json:"postUpgrade,omitempty"`
+
+	// Keep the data from before the upgrade until the upgrade is confirmed
+	// or rolled back. When omitted, the data is removed from replicas as soon
+	// as the primary is upgraded.
+	// ---
+	// +optional
+	Retention *PGUpgradeRetentionSpec `json:"retention,omitempty"`
+
+	// How to upgrade replicas. "Recreate" removes their data so that they are
+	// recreated from a backup once the primary starts. "Rsync" upgrades their
+	// data in place by copying only what the upgrade changed from the primary
+	// using rsync and hard links, as documented for pg_upgrade. It requires
+	// a "Link" upgrade and an image that has rsync. A Job mounts the volumes
+	// of the primary and of each replica at the same time, so both must be
+	// able to attach to one Node, such as volumes in the same zone or
+	// ReadWriteMany volumes. A replica whose Job cannot be scheduled for two
+	// minutes, or that was behind the primary when the cluster stopped, is
+	// recreated. Defaults to "Recreate".
+	// More info: https://www.postgresql.org/docs/current/pgupgrade.html#PGUPGRADE-STEP-REPLICAS
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:MaxLength=10
+	//
+	// +kubebuilder:validation:Enum={Recreate,Rsync}
+	// +optional
+	ReplicaMethod string `json:"replicaMethod,omitempty"`
+
 	// The image name to use for major PostgreSQL upgrades.
 	// +optional
 	Image *string `json:"image,omitempty"`
@@ -73,6 +141,130 @@ type PGUpgradeSpec struct {
 	PGUpgradeSettings `json:",inline"`
 }
 
+// PGUpgradeSpec methods.
+const (
+	PGUpgradeMethodPGUpgrade          = "PGUpgrade"
+	PGUpgradeMethodLogicalReplication = "LogicalReplication"
+
+	PGUpgradeReplicaMethodRecreate = "Recreate"
+	PGUpgradeReplicaMethodRsync    = "Rsync"
+)
+
+type PGUpgradeLogicalReplicationSpec struct {
+	// The name of a new PostgresCluster to create at toPostgresVersion. Its
+	// specification is copied from the PostgresCluster being upgraded.
+	// ---
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	ClusterName string `json:"clusterName"`
+
+	// The databases to copy to the new cluster. Each one is copied through
+	// its own publication and subscription.
+	// ---
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=20
+	// +listType=set
+	// +required
+	Databases []PostgresIdentifier `json:"databases"`
+
+	// The PostgreSQL image of the new cluster. Defaults to the image for
+	// toPostgresVersion configured in the operator.
+	// ---
+	// +optional
+	Image *string `json:"image,omitempty"`
+
+	// A superuser in the users field of the PostgresCluster being upgraded,
+	// such as "postgres". Its password is copied to the new cluster, and it
+	// is used to copy the schema and receive changes of every database.
+	// ---
+	// This value goes into the name of a corev1.Secret.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	//
+	// +required
+	User PostgresIdentifier `json:"user"`
+}
+
+type PGUpgradeCheckSpec struct {
+	// The VolumeSnapshotClass used to copy the data volumes of the primary.
+	// When omitted, the volumes are cloned by their storage provisioner.
+	// More info: https://kubernetes.io/docs/concepts/storage/volume-pvc-datasource/
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+type PGUpgradePostUpgradeSpec struct {
+	// Whether or not to collect planner statistics in every database using
+	// "vacuumdb --all --analyze-in-stages". pg_upgrade does not copy them,
+	// so queries may be slow until this is done.
+	// More info: https://www.postgresql.org/docs/current/pgupgrade.html
+	// ---
+	// +kubebuilder:default=true
+	// +optional
+	Analyze *bool `json:"analyze,omitempty"`
+
+	// Whether or not to update extensions in every database. This runs the
+	// update_extensions.sql script generated by pg_upgrade, if any, then
+	// "ALTER EXTENSION ... UPDATE" for every extension that is not at its
+	// default version. Extensions in the extensions fields of the
+	// PostgresCluster are left at the version specified there.
+	// More info: https://www.postgresql.org/docs/current/sql-alterextension.html
+	// ---
+	// +kubebuilder:default=true
+	// +optional
+	UpdateExtensions *bool `json:"updateExtensions,omitempty"`
+
+	// A superuser in the users field of the PostgresCluster, such as
+	// "postgres". Each task runs in a Job that connects to the primary as
+	// this user.
+	// ---
+	// This value goes into the name of a corev1.Secret.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	//
+	// +required
+	User PostgresIdentifier `json:"user"`
+}
+
+type PGUpgradeRetentionSpec struct {
+	// What to do with the data from before the upgrade. Nothing is done
+	// while this is unset. "Confirm" removes that data from every instance,
+	// and the upgrade succeeds. "Rollback" sets the postgresVersion and images
+	// of the cluster and its instance sets back to what they were before the
+	// upgrade so that it starts from that data. This discards every change written to the
+	// cluster since the upgrade. Rollback requires the cluster be shut down.
+	// Once the new version starts, the data files it shares with the old
+	// version after a "Link" upgrade are no longer usable, so rolling back a
+	// "Link" upgrade requires volumeSnapshotClassName. The volumes of the
+	// primary are then restored from its VolumeSnapshots.
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:MaxLength=10
+	//
+	// +kubebuilder:validation:Enum={Confirm,Rollback}
+	// +optional
+	Action string `json:"action,omitempty"`
+
+	// The VolumeSnapshotClass used to take snapshots of the volumes of the
+	// primary before it is upgraded. A PostgresCluster at fromPostgresVersion
+	// can be created from PersistentVolumeClaims of these snapshots using its
+	// dataSource.volumes field. The snapshots are deleted when the upgrade is
+	// confirmed or this PGUpgrade is deleted.
+	// More info: https://kubernetes.io/docs/concepts/storage/volume-snapshots/
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+// PGUpgradeRetentionSpec actions.
+const (
+	PGUpgradeRetentionConfirm  = "Confirm"
+	PGUpgradeRetentionRollback = "Rollback"
+)
+
 // Arguments and settings for the pg_upgrade tool.
 // See: https://www.postgresql.org/docs/current/pgupgrade.html
 // ---
@@ -131,6 +323,16 @@ type PGUpgradeStatus struct {
 	// +optional
 	// +kubebuilder:validation:Minimum=0
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The image of the PostgresCluster when its upgrade started. A rollback
+	// sets the cluster back to this image.
+	// +optional
+	PreviousImage *string `json:"previousImage,omitempty"`
+
+	// The images of PostgresCluster instance sets when its upgrade started.
+	// A rollback sets each instance set back to its image.
+	// +optional
+	PreviousInstanceImages map[string]string `json:"previousInstanceImages,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -6,6 +6,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -24,6 +25,16 @@ type PostgresAuthenticationSpec struct {
 	// +listType=atomic
 	// +optional
 	Rules []PostgresHBARuleSpec `json:"rules,omitempty"`
+
+	// Whether to refuse MD5 password authentication once no PostgreSQL roles
+	// have MD5 passwords, as reported by the "MD5Passwords" condition. Rules
+	// above and in the Patroni section that use the "md5" or "password" method
+	// then use "scram-sha-256", and the password_encryption parameter is always
+	// "scram-sha-256".
+	// More info: https://www.postgresql.org/docs/current/auth-password.html
+	// ---
+	// +optional
+	RejectMD5 *bool `json:"rejectMD5,omitempty"`
 }
 
 type PostgresConfigSpec struct {
@@ -71,7 +82,7 @@ type PostgresConfigSpec struct {
 	// +kubebuilder:validation:XValidation:rule=`!has(self.hot_standby)`,message=`hot_standby is always enabled`
 	// +kubebuilder:validation:XValidation:rule=`!has(self.synchronous_standby_names)`
 	// +kubebuilder:validation:XValidation:rule=`!has(self.primary_conninfo) && !has(self.primary_slot_name)`
-	// +kubebuilder:validation:XValidation:rule=`!has(self.recovery_min_apply_delay)`,message=`delayed replication is not supported at this time`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.recovery_min_apply_delay)`,message=`delay replication using instance set tags instead`
 	//
 	// # Logging
 	// - https://www.postgresql.org/docs/current/runtime-config-logging.html
@@ -85,6 +96,161 @@ type PostgresConfigSpec struct {
 	Parameters map[string]intstr.IntOrString `json:"parameters,omitempty"`
 }
 
+type PostgresInstanceConfigSpec struct {
+	// Configuration parameters for the PostgreSQL servers of one instance set.
+	// These take precedence over spec.config.parameters. Parameters that must
+	// be the same on every instance of the cluster cannot be set here.
+	// Changing this value causes PostgreSQL to restart.
+	// More info: https://www.postgresql.org/docs/current/runtime-config.html
+	// ---
+	// +kubebuilder:validation:MaxProperties=20
+	//
+	// # Cluster-wide
+	// Patroni takes these from DCS and ignores any values set locally.
+	// - https://patroni.readthedocs.io/en/latest/patroni_configuration.html#postgresql-parameters-controlled-by-patroni
+	//
+	// +kubebuilder:validation:XValidation:rule=`!has(self.max_connections) && !has(self.max_locks_per_transaction) && !has(self.max_prepared_transactions) && !has(self.max_worker_processes)`,message=`must be the same on every instance: max_connections, max_locks_per_transaction, max_prepared_transactions, max_worker_processes`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.max_replication_slots) && !has(self.max_wal_senders) && !has(self.track_commit_timestamp) && !has(self.wal_keep_segments) && !has(self.wal_keep_size)`,message=`must be the same on every instance: max_replication_slots, max_wal_senders, track_commit_timestamp, wal_keep_segments, wal_keep_size`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.hot_standby) && !has(self.wal_level) && !has(self.wal_log_hints)`,message=`must be the same on every instance: hot_standby, wal_level, wal_log_hints`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.shared_preload_libraries)`,message=`change shared_preload_libraries using .spec.config.parameters instead`
+	//
+	// # Managed by the operator
+	// These are rejected in spec.config.parameters, too. Instance sets are an
+	// unbounded list, so avoid rules here that iterate over parameter names.
+	//
+	// +kubebuilder:validation:XValidation:rule=`!has(self.config_file) && !has(self.data_directory) && !has(self.external_pid_file) && !has(self.hba_file) && !has(self.ident_file)`,message=`cannot change file locations: config_file, data_directory, external_pid_file, hba_file, ident_file`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.listen_addresses) && !has(self.port) && !has(self.unix_socket_directories)`,message=`cannot change network settings: listen_addresses, port, unix_socket_directories`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.ssl) && !has(self.ssl_ca_file) && !has(self.ssl_cert_file) && !has(self.ssl_key_file)`,message=`TLS is always enabled: ssl, ssl_ca_file, ssl_cert_file, ssl_key_file`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.archive_mode) && !has(self.archive_command) && !has(self.restore_command)`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.recovery_target) && !has(self.recovery_target_lsn) && !has(self.recovery_target_name) && !has(self.recovery_target_time) && !has(self.recovery_target_xid)`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.synchronous_standby_names) && !has(self.primary_conninfo) && !has(self.primary_slot_name)`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.recovery_min_apply_delay)`,message=`delay replication using instance set tags instead`
+	// +kubebuilder:validation:XValidation:rule=`!has(self.cluster_name) && !has(self.logging_collector) && !has(self.log_file_mode)`,message=`cannot change logging settings: cluster_name, logging_collector, log_file_mode`
+	//
+	// +mapType=granular
+	// +optional
+	Parameters map[string]intstr.IntOrString `json:"parameters,omitempty"`
+}
+
+type PostgresDatabaseSpec struct {
+	// The name of this PostgreSQL database.
+	// ---
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The role that owns this database. When omitted from a new database, the
+	// database is owned by the "postgres" superuser. Changes to this value are
+	// applied to existing databases, but removing it leaves the current owner
+	// in place.
+	// More info: https://www.postgresql.org/docs/current/manage-ag-createdb.html
+	// ---
+	// +optional
+	Owner PostgresIdentifier `json:"owner,omitempty"`
+
+	// The number of concurrent connections allowed to this database. The
+	// value -1 means no limit. Changes to this value are applied to existing
+	// databases.
+	// ---
+	// +kubebuilder:validation:Minimum=-1
+	// +optional
+	ConnectionLimit *int32 `json:"connectionLimit,omitempty"`
+
+	// The character set encoding of this database. When this differs from the
+	// encoding of the template, the template should be "template0". This value
+	// is used only when the database is created.
+	// More info: https://www.postgresql.org/docs/current/multibyte.html
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:Pattern=`^[-_A-Za-z0-9]+$`
+	// +optional
+	Encoding string `json:"encoding,omitempty"`
+
+	// Extensions to install in this database. These take precedence over
+	// extensions of the same name in the PostgresCluster spec.
+	// ---
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Extensions []PostgresExtensionSpec `json:"extensions,omitempty"`
+
+	// The ICU locale of this database. This requires PostgreSQL 15 or newer
+	// and is used only when the database is created.
+	// More info: https://www.postgresql.org/docs/current/collation.html#COLLATION-MANAGING-CREATE-ICU
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[[:print:]]+$`
+	// +optional
+	ICULocale string `json:"icuLocale,omitempty"`
+
+	// The operating system locale of this database. This requires PostgreSQL
+	// 13 or newer and is used only when the database is created.
+	// More info: https://www.postgresql.org/docs/current/locale.html
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[[:print:]]+$`
+	// +optional
+	Locale string `json:"locale,omitempty"`
+
+	// The database from which this database is copied. This value is used only
+	// when the database is created.
+	// More info: https://www.postgresql.org/docs/current/manage-ag-templatedbs.html
+	// ---
+	// +optional
+	Template PostgresIdentifier `json:"template,omitempty"`
+}
+
+type PostgresExtensionSpec struct {
+	// The name of this PostgreSQL extension.
+	// More info: https://www.postgresql.org/docs/current/sql-createextension.html
+	// ---
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The schema in which to install this extension. When omitted, the
+	// extension is installed in the schema required by the extension or,
+	// when there is none, in the "public" schema. This value is used only
+	// when the extension is created.
+	// ---
+	// +optional
+	Schema PostgresIdentifier `json:"schema,omitempty"`
+
+	// Shared libraries this extension requires in "shared_preload_libraries".
+	// Changing this value causes PostgreSQL to restart.
+	// ---
+	// +kubebuilder:validation:MaxItems=5
+	// +kubebuilder:validation:items:MinLength=1
+	// +kubebuilder:validation:items:MaxLength=63
+	// +kubebuilder:validation:items:Pattern=`^[-_.A-Za-z0-9]+$`
+	// +listType=set
+	// +optional
+	SharedPreloadLibraries []string `json:"sharedPreloadLibraries,omitempty"`
+
+	// The version of this extension to install. When omitted, the default
+	// version in the PostgreSQL image is installed. Installed extensions are
+	// updated to this version, or to the default, when the image changes.
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=50
+	// +kubebuilder:validation:Pattern=`^[-_.+A-Za-z0-9]+$`
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+type PostgresExtensionStatus struct {
+	// The database in which this extension is installed.
+	Database string `json:"database"`
+
+	// The name of this extension.
+	Name string `json:"name"`
+
+	// The version of this extension that is installed.
+	Version string `json:"version"`
+}
+
 // ---
 type PostgresHBARule struct {
 	// The connection transport this rule matches. Typical values are:
@@ -169,6 +335,7 @@ type PostgresHBARuleSpec struct {
 // +kubebuilder:validation:MaxLength=63
 type PostgresIdentifier = string
 
+// +kubebuilder:validation:XValidation:rule=`!has(self.rotation) || !has(self.secretKeyRef)`,message="passwords from a Secret are not rotated"
 type PostgresPasswordSpec struct {
 	// Type of password to generate. Defaults to ASCII. Valid options are ASCII
 	// and AlphaNumeric.
@@ -183,6 +350,40 @@ type PostgresPasswordSpec struct {
 	// +kubebuilder:validation:Enum={ASCII,AlphaNumeric}
 	// +required
 	Type string `json:"type"`
+
+	// Generate new passwords on a schedule or on demand. A new password is
+	// generated whenever the value of the "postgres-operator.crunchydata.com/rotate-passwords"
+	// annotation on the PostgresCluster changes.
+	// +optional
+	Rotation *PostgresPasswordRotationSpec `json:"rotation,omitempty"`
+
+	// A key of an existing Secret in the same namespace that holds the password
+	// of this user. The value is either a plaintext password or a SCRAM-SHA-256
+	// verifier. Only a verifier is written to the user Secret, and PostgreSQL
+	// is updated when the value changes.
+	// More info: https://www.postgresql.org/docs/current/auth-password.html
+	// +optional
+	SecretKeyRef *SecretKeyRef `json:"secretKeyRef,omitempty"`
+}
+
+type PostgresPasswordRotationSpec struct {
+	// How long a password is used before a new one is generated. The new
+	// password replaces the old one in PostgreSQL and in the user Secret;
+	// PostgreSQL stops accepting the old password immediately.
+	// When unset, passwords are rotated only on demand.
+	// ---
+	// Accept the units used by other durations in this API and reject fractions.
+	// +kubebuilder:validation:Pattern=`^(PT)?( *[0-9]+ *(?i:(h|hr|d|w|wk)|(hour|day|week)s?))+$`
+	//
+	// `controller-gen` needs to know "Type=string" to allow a "Pattern".
+	// +kubebuilder:validation:Type=string
+	//
+	// Set a max length to keep rule costs low.
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:XValidation:rule=`duration("1h") <= self && self <= duration("8760h")`,message="must be between one hour and one year"
+	//
+	// +optional
+	Interval *Duration `json:"interval,omitempty"`
 }
 
 // PostgresPasswordSpec types.
@@ -191,6 +392,171 @@ const (
 	PostgresPasswordTypeASCII        = "ASCII"
 )
 
+type PostgresPublicationSpec struct {
+	// The name of this PostgreSQL publication.
+	// ---
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The database in which to create this publication.
+	// ---
+	// +required
+	Database PostgresIdentifier `json:"database"`
+
+	// Tables to publish. When omitted, this publication includes every table
+	// in the database, including tables created later. PostgreSQL refuses
+	// updates and deletes in published tables without a replica identity, so
+	// that publication is not created until every table has one; the
+	// "MissingReplicaIdentity" condition lists those that do not. The tables
+	// must exist before the publication is created.
+	// ---
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=100
+	// +listType=atomic
+	// +optional
+	Tables []PostgresPublicationTableSpec `json:"tables,omitempty"`
+}
+
+type PostgresPublicationTableSpec struct {
+	// The name of this table.
+	// ---
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The schema of this table. When omitted, the table is in the "public" schema.
+	// ---
+	// +optional
+	Schema PostgresIdentifier `json:"schema,omitempty"`
+}
+
+type PostgresSubscriptionSpec struct {
+	// The name of this PostgreSQL subscription. This is also the name of the
+	// replication slot it creates in the source cluster.
+	// ---
+	// Replication slot names may contain only lowercase letters, numbers,
+	// and underscore.
+	// - https://www.postgresql.org/docs/current/warm-standby.html#STREAMING-REPLICATION-SLOTS-MANIPULATION
+	// +kubebuilder:validation:Pattern=`^[a-z0-9_]+$`
+	//
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The database in which to create this subscription. Changes are written
+	// to tables of the same name in this database.
+	// ---
+	// +required
+	Database PostgresIdentifier `json:"database"`
+
+	// Whether or not this subscription receives changes. Defaults to true.
+	// ---
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Publications in the source database from which to receive changes.
+	// Changes to this value are applied to existing subscriptions.
+	// ---
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=20
+	// +listType=set
+	// +required
+	Publications []PostgresIdentifier `json:"publications"`
+
+	// The PostgresCluster that publishes changes to this subscription.
+	// ---
+	// +required
+	Source PostgresSubscriptionSourceSpec `json:"source"`
+}
+
+type PostgresSubscriptionSourceSpec struct {
+	// The name of a PostgresCluster in the same namespace.
+	// ---
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	ClusterName string `json:"clusterName"`
+
+	// The database in the source cluster that has the publications. Defaults
+	// to the database of the subscription.
+	// ---
+	// +optional
+	Database PostgresIdentifier `json:"database,omitempty"`
+
+	// A user in the users field of the source cluster. The subscription
+	// connects through the primary Service using the password in that
+	// user's Secret or in the Secret referenced by its password.secretKeyRef.
+	// The user needs the REPLICATION attribute and privilege to read the
+	// published tables.
+	// More info: https://www.postgresql.org/docs/current/logical-replication-security.html
+	// ---
+	// This value goes into the name of a corev1.Secret.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	//
+	// +required
+	User PostgresIdentifier `json:"user"`
+}
+
+type PostgresSubscriptionStatus struct {
+	// The name of this PostgreSQL subscription.
+	// +required
+	Name string `json:"name"`
+
+	// The database in which this subscription exists.
+	// +required
+	Database string `json:"database"`
+
+	// The state of this subscription: "Disabled" when it is not enabled,
+	// "Stopped" when its worker is not running, "Synchronizing" while tables
+	// are copied from the source, or "Streaming" otherwise.
+	// +required
+	State string `json:"state"`
+
+	// Seconds since the source cluster last confirmed a position in its WAL
+	// to this subscription. This is approximately how far behind it is.
+	// +optional
+	LagSeconds *int64 `json:"lagSeconds,omitempty"`
+
+	// The last time these details were queried from PostgreSQL.
+	// +optional
+	ObservedTime *metav1.Time `json:"observedTime,omitempty"`
+}
+
+// PostgresSubscriptionStatus states.
+const (
+	PostgresSubscriptionDisabled      = "Disabled"
+	PostgresSubscriptionStopped       = "Stopped"
+	PostgresSubscriptionStreaming     = "Streaming"
+	PostgresSubscriptionSynchronizing = "Synchronizing"
+)
+
+type PostgresUserDeletionSpec struct {
+	// What happens to a PostgreSQL user that is removed from the users field.
+	// "Retain" keeps the user in PostgreSQL and deletes its Secret. "Drop"
+	// removes the user from PostgreSQL and then deletes its Secret. A user that
+	// owns objects is dropped only when reassignOwnedTo is set.
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:MaxLength=15
+	//
+	// +kubebuilder:default=Retain
+	// +kubebuilder:validation:Enum={Retain,Drop}
+	// +required
+	Policy string `json:"policy"`
+
+	// The role that receives ownership of objects owned by a user before
+	// that user is dropped.
+	// More info: https://www.postgresql.org/docs/current/sql-reassign-owned.html
+	// ---
+	// +optional
+	ReassignOwnedTo PostgresIdentifier `json:"reassignOwnedTo,omitempty"`
+}
+
+// PostgresUserDeletionSpec policies.
+const (
+	PostgresUserDeletionDrop   = "Drop"
+	PostgresUserDeletionRetain = "Retain"
+)
+
 type PostgresUserSpec struct {
 	// The name of this PostgreSQL user. The value may contain only lowercase
 	// letters, numbers, and hyphen so that it fits into Kubernetes metadata.
@@ -207,8 +573,8 @@ type PostgresUserSpec struct {
 	Name PostgresIdentifier `json:"name"`
 
 	// Databases to which this user can connect and create objects. Removing a
-	// database from this list does NOT revoke access. This field is ignored for
-	// the "postgres" user.
+	// database from this list does NOT revoke access unless grants are also
+	// specified. This field is ignored for the "postgres" user.
 	// ---
 	// +listType=set
 	// +optional
@@ -225,8 +591,150 @@ type PostgresUserSpec struct {
 	// +optional
 	Options string `json:"options,omitempty"`
 
+	// Privileges granted to this user. When this is specified, privileges
+	// of these kinds that are not listed are revoked from this user. Databases
+	// in the databases field keep all their privileges. Privileges in schemas
+	// that do not exist are reported in the "MissingSchemas" condition and
+	// tried again every hour. This field is ignored for the "postgres" user.
+	// ---
+	// +optional
+	Grants *PostgresGrantsSpec `json:"grants,omitempty"`
+
 	// Properties of the password generated for this user.
 	// ---
 	// +optional
 	Password *PostgresPasswordSpec `json:"password,omitempty"`
 }
+
+type PostgresUserStatus struct {
+	// The name of this PostgreSQL user.
+	// +required
+	Name string `json:"name"`
+
+	// The last time a new password was generated for this user.
+	// +optional
+	PasswordRotationTime *metav1.Time `json:"passwordRotationTime,omitempty"`
+}
+
+type PostgresGrantsSpec struct {
+	// Privileges on databases.
+	// More info: https://www.postgresql.org/docs/current/ddl-priv.html
+	// ---
+	// +kubebuilder:validation:MaxItems=20
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Databases []PostgresDatabaseGrant `json:"databases,omitempty"`
+
+	// Privileges given to objects created in the future.
+	// More info: https://www.postgresql.org/docs/current/sql-alterdefaultprivileges.html
+	// ---
+	// +kubebuilder:validation:MaxItems=50
+	// +listType=atomic
+	// +optional
+	DefaultPrivileges []PostgresDefaultPrivilegesGrant `json:"defaultPrivileges,omitempty"`
+
+	// Roles of which this user is a member. Memberships granted WITH ADMIN
+	// OPTION are never revoked.
+	// More info: https://www.postgresql.org/docs/current/role-membership.html
+	// ---
+	// +kubebuilder:validation:MaxItems=20
+	// +listType=set
+	// +optional
+	Roles []PostgresIdentifier `json:"roles,omitempty"`
+
+	// Privileges on schemas.
+	// More info: https://www.postgresql.org/docs/current/ddl-priv.html
+	// ---
+	// +kubebuilder:validation:MaxItems=50
+	// +listType=map
+	// +listMapKey=database
+	// +listMapKey=schema
+	// +optional
+	Schemas []PostgresSchemaGrant `json:"schemas,omitempty"`
+}
+
+type PostgresDatabaseGrant struct {
+	// The name of the database.
+	// ---
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// Privileges on this database.
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:items:MaxLength=15
+	//
+	// +kubebuilder:validation:items:Enum={CONNECT,CREATE,TEMPORARY}
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	// +required
+	Privileges []string `json:"privileges"`
+}
+
+// ---
+// +kubebuilder:validation:XValidation:rule=`!has(self.schema) || self.objectType != "SCHEMAS"`,message=`cannot limit SCHEMAS to a schema`
+type PostgresDefaultPrivilegesGrant struct {
+	// The database in which objects are created.
+	// ---
+	// +required
+	Database PostgresIdentifier `json:"database"`
+
+	// The kind of objects that receive these privileges.
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:MaxLength=15
+	//
+	// +kubebuilder:validation:Enum={TABLES,SEQUENCES,FUNCTIONS,TYPES,SCHEMAS}
+	// +required
+	ObjectType string `json:"objectType"`
+
+	// Privileges on these objects.
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:items:MaxLength=15
+	//
+	// +kubebuilder:validation:items:Enum={SELECT,INSERT,UPDATE,DELETE,TRUNCATE,REFERENCES,TRIGGER,MAINTAIN,USAGE,EXECUTE,CREATE}
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	// +required
+	Privileges []string `json:"privileges"`
+
+	// The role that creates these objects.
+	// ---
+	// +required
+	Role PostgresIdentifier `json:"role"`
+
+	// The schema in which objects are created. When omitted, these privileges
+	// apply to objects in every schema.
+	// ---
+	// +optional
+	Schema PostgresIdentifier `json:"schema,omitempty"`
+}
+
+type PostgresSchemaGrant struct {
+	// The database that contains the schema.
+	// ---
+	// +required
+	Database PostgresIdentifier `json:"database"`
+
+	// Privileges on this schema.
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:items:MaxLength=15
+	//
+	// +kubebuilder:validation:items:Enum={USAGE,CREATE}
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	// +required
+	Privileges []string `json:"privileges"`
+
+	// The name of the schema.
+	// ---
+	// +required
+	Schema PostgresIdentifier `json:"schema"`
+}
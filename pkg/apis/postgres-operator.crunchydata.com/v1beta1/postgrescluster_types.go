@@ -6,8 +6,11 @@ package v1beta1
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -59,6 +62,15 @@ type PostgresClusterSpec struct {
 	// namespace as the cluster.
 	// +optional
 	DatabaseInitSQL *DatabaseInitSQL `json:"databaseInitSQL,omitempty"`
+
+	// Databases to create inside PostgreSQL. Removing a database from this
+	// list does NOT drop the database.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	Databases []PostgresDatabaseSpec `json:"databases,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should use the defined default
 	// scheduling constraints. If the field is unset or false, the default
 	// scheduling constraints will be used in addition to any custom constraints
@@ -66,6 +78,14 @@ type PostgresClusterSpec struct {
 	// +optional
 	DisableDefaultPodScheduling *bool `json:"disableDefaultPodScheduling,omitempty"`
 
+	// Extensions to install in every database. Removing an extension from
+	// this list does NOT drop the extension.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Extensions []PostgresExtensionSpec `json:"extensions,omitempty"`
+
 	// The image name to use for PostgreSQL containers. When omitted, the value
 	// comes from an operator environment variable. For standard PostgreSQL images,
 	// the format is RELATED_IMAGE_POSTGRES_{postgresVersion},
@@ -108,6 +128,13 @@ type PostgresClusterSpec struct {
 	// +optional
 	Instrumentation *InstrumentationSpec `json:"instrumentation,omitempty"`
 
+	// When the operator may restart PostgreSQL and redeploy instances to apply
+	// changes. Outside this window, parameters that require a restart remain
+	// pending and instances keep running with their current Pod template.
+	// When unset, changes are applied immediately.
+	// +optional
+	MaintenanceWindow *MaintenanceWindowSpec `json:"maintenanceWindow,omitempty"`
+
 	// Whether or not the PostgreSQL cluster is being deployed to an OpenShift
 	// environment. If the field is unset, the operator will automatically
 	// detect the environment.
@@ -144,6 +171,16 @@ type PostgresClusterSpec struct {
 	// +optional
 	Proxy *PostgresProxySpec `json:"proxy,omitempty"`
 
+	// Logical replication publications to create inside PostgreSQL. Changes
+	// to the tables of a publication are applied to existing publications.
+	// Removing a publication from this list does NOT drop the publication.
+	// More info: https://www.postgresql.org/docs/current/logical-replication-publication.html
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Publications []PostgresPublicationSpec `json:"publications,omitempty"`
+
 	// The specification of a user interface that connects to PostgreSQL.
 	// +optional
 	UserInterface *UserInterfaceSpec `json:"userInterface,omitempty"`
@@ -160,6 +197,11 @@ type PostgresClusterSpec struct {
 	// +optional
 	ReplicaService *ServiceSpec `json:"replicaService,omitempty"`
 
+	// How instances are redeployed when their Pod template changes, such as
+	// when the image changes for a minor release of PostgreSQL.
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should be stopped.
 	// When this is true, workloads are scaled to zero and CronJobs
 	// are suspended.
@@ -171,6 +213,17 @@ type PostgresClusterSpec struct {
 	// +optional
 	Standby *PostgresStandbySpec `json:"standby,omitempty"`
 
+	// Logical replication subscriptions to create inside PostgreSQL. Each
+	// one receives changes from publications of another PostgresCluster in
+	// the same namespace. Removing a subscription from this list does NOT
+	// drop the subscription.
+	// More info: https://www.postgresql.org/docs/current/logical-replication-subscription.html
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Subscriptions []PostgresSubscriptionSpec `json:"subscriptions,omitempty"`
+
 	// A list of group IDs applied to the process of a container. These can be
 	// useful when accessing shared file systems with constrained permissions.
 	// More info: https://kubernetes.io/docs/reference/kubernetes-api/workload-resources/pod-v1/#security-context
@@ -187,10 +240,16 @@ type PostgresClusterSpec struct {
 	// +kubebuilder:validation:items:Maximum=2147483647
 	SupplementalGroups []int64 `json:"supplementalGroups,omitempty"`
 
+	// What happens to PostgreSQL users that are removed from the users field.
+	// The default keeps them in PostgreSQL.
+	// +optional
+	UserDeletion *PostgresUserDeletionSpec `json:"userDeletion,omitempty"`
+
 	// Users to create inside PostgreSQL and the databases they should access.
 	// The default creates one user that can access one database matching the
 	// PostgresCluster name. An empty list creates no users. Removing a user
-	// from this list does NOT drop the user nor revoke their access.
+	// from this list does NOT drop the user nor revoke their access unless
+	// the userDeletion policy is "Drop".
 	// +listType=map
 	// +listMapKey=name
 	// +kubebuilder:validation:MaxItems=64
@@ -198,6 +257,92 @@ type PostgresClusterSpec struct {
 	Users []PostgresUserSpec `json:"users,omitempty"`
 }
 
+type RolloutSpec struct {
+	// "Rolling" redeploys one instance at a time, replicas before the primary.
+	// Patroni chooses the replica that becomes primary. "ReplicasFirst" also
+	// redeploys one instance at a time, but it waits for each redeployed
+	// replica to be streaming and caught up to the primary before it moves on.
+	// When every replica is done, it switches over to the one with the least
+	// replication lag and redeploys the old primary. Both wait for the
+	// maintenanceWindow, if any. Defaults to "Rolling".
+	// ---
+	// Kubernetes assumes the evaluation cost of an enum value is very large.
+	// TODO(k8s-1.29): Drop MaxLength after Kubernetes 1.29; https://issue.k8s.io/119511
+	// +kubebuilder:validation:MaxLength=15
+	//
+	// +kubebuilder:validation:Enum={Rolling,ReplicasFirst}
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+
+	// The most WAL a redeployed replica can have yet to replay and still be
+	// caught up to the primary. Applies to the "ReplicasFirst" strategy.
+	// In a standby cluster, this is measured against the standby leader using
+	// the WAL positions reported by Patroni.
+	// Defaults to 16Mi, the size of one WAL segment.
+	// More info: https://kubernetes.io/docs/reference/kubernetes-api/common-definitions/quantity
+	// ---
+	// +optional
+	MaxReplayLag *resource.Quantity `json:"maxReplayLag,omitempty"`
+}
+
+// RolloutSpec strategies.
+const (
+	RolloutRolling       = "Rolling"
+	RolloutReplicasFirst = "ReplicasFirst"
+)
+
+// MaintenanceWindowSpec describes hours of the week, in UTC, like the day
+// and hour fields of a cron schedule.
+type MaintenanceWindowSpec struct {
+	// Days of the week when the window is open. When empty, the window is
+	// open every day.
+	// ---
+	// +kubebuilder:validation:items:Enum={Sun,Mon,Tue,Wed,Thu,Fri,Sat}
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=7
+	// +optional
+	Days []string `json:"days,omitempty"`
+
+	// Hours of the day, 0 through 23 in UTC, when the window is open. When
+	// empty, the window is open all day.
+	// ---
+	// +kubebuilder:validation:items:Minimum=0
+	// +kubebuilder:validation:items:Maximum=23
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=24
+	// +optional
+	Hours []int32 `json:"hours,omitempty"`
+}
+
+// Open reports whether or not w is open at t. When it is not, it also returns
+// how long until it opens. A nil window is always open.
+func (w *MaintenanceWindowSpec) Open(t time.Time) (bool, time.Duration) {
+	if w == nil {
+		return true, 0
+	}
+
+	contains := func(t time.Time) bool {
+		return (len(w.Days) == 0 || slices.Contains(w.Days, t.Weekday().String()[:3])) &&
+			(len(w.Hours) == 0 || slices.Contains(w.Hours, int32(t.Hour())))
+	}
+
+	t = t.UTC()
+	if contains(t) {
+		return true, 0
+	}
+
+	// Check the start of every hour in the coming week.
+	start := t.Truncate(time.Hour)
+	for i := 1; i <= 7*24; i++ {
+		if next := start.Add(time.Duration(i) * time.Hour); contains(next) {
+			return false, next.Sub(t)
+		}
+	}
+
+	// The enums above make this unreachable.
+	return false, 0
+}
+
 // DataSource defines data sources for a new PostgresCluster.
 type DataSource struct {
 	// Defines a pgBackRest cloud-based data source that can be used to pre-populate the
@@ -353,12 +498,31 @@ type PostgresClusterStatus struct {
 	// Identifies the databases that have been installed into PostgreSQL.
 	DatabaseRevision string `json:"databaseRevision,omitempty"`
 
+	// The connection limits of databases whose connections are stopped. Each
+	// is restored when connections to its database start again.
+	// +optional
+	StoppedConnectionLimits map[string]int32 `json:"stoppedConnectionLimits,omitempty"`
+
+	// The extensions installed in each database.
+	// +listType=atomic
+	// +optional
+	Extensions []PostgresExtensionStatus `json:"extensions,omitempty"`
+
+	// Identifies the publications and subscriptions that have been written
+	// to PostgreSQL.
+	// +optional
+	LogicalReplicationRevision string `json:"logicalReplicationRevision,omitempty"`
+
 	// Current state of PostgreSQL instances.
 	// +listType=map
 	// +listMapKey=name
 	// +optional
 	InstanceSets []PostgresInstanceSetStatus `json:"instances,omitempty"`
 
+	// When replication statistics were last queried from the primary.
+	// +optional
+	ReplicationObservedTime *metav1.Time `json:"replicationObservedTime,omitempty"`
+
 	// +optional
 	Patroni PatroniStatus `json:"patroni,omitempty"`
 
@@ -390,6 +554,16 @@ type PostgresClusterStatus struct {
 	// +optional
 	StartupInstanceSet string `json:"startupInstanceSet,omitempty"`
 
+	// The state of subscriptions in spec.subscriptions that exist in PostgreSQL.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Subscriptions []PostgresSubscriptionStatus `json:"subscriptions,omitempty"`
+
+	// When the state of subscriptions was last queried from PostgreSQL.
+	// +optional
+	SubscriptionsObservedTime *metav1.Time `json:"subscriptionsObservedTime,omitempty"`
+
 	// Current state of the PostgreSQL user interface.
 	// +optional
 	UserInterface *PostgresUserInterfaceStatus `json:"userInterface,omitempty"`
@@ -397,6 +571,17 @@ type PostgresClusterStatus struct {
 	// Identifies the users that have been installed into PostgreSQL.
 	UsersRevision string `json:"usersRevision,omitempty"`
 
+	// The PostgreSQL users that have generated passwords.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Users []PostgresUserStatus `json:"users,omitempty"`
+
+	// The value of the "postgres-operator.crunchydata.com/rotate-passwords"
+	// annotation when passwords were last rotated on demand.
+	// +optional
+	PasswordRotation *string `json:"passwordRotation,omitempty"`
+
 	// Current state of PostgreSQL cluster monitoring tool configuration
 	// +optional
 	Monitoring MonitoringStatus `json:"monitoring,omitempty"`
@@ -411,8 +596,9 @@ type PostgresClusterStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
 	// conditions represent the observations of postgrescluster's current state.
-	// Known .status.conditions.type are: "PersistentVolumeResizing",
-	// "Progressing", "ProxyAvailable"
+	// Known .status.conditions.type are: "InstanceReinitializing", "MD5Passwords",
+	// "MissingReplicaIdentity", "MissingSchemas", "PersistentVolumeResizing",
+	// "Progressing", "ProxyAvailable", "SwitchoverOnCordon", "SynchronousReplication"
 	// +optional
 	// +listType=map
 	// +listMapKey=type
@@ -422,13 +608,21 @@ type PostgresClusterStatus struct {
 
 // PostgresClusterStatus condition types.
 const (
+	InstanceReinitializing      = "InstanceReinitializing"
+	MD5Passwords                = "MD5Passwords"
+	MissingReplicaIdentity      = "MissingReplicaIdentity"
+	MissingSchemas              = "MissingSchemas"
 	PersistentVolumeResizing    = "PersistentVolumeResizing"
 	PersistentVolumeResizeError = "PersistentVolumeResizeError"
 	PostgresClusterProgressing  = "Progressing"
 	ProxyAvailable              = "ProxyAvailable"
 	Registered                  = "Registered"
+	SwitchoverOnCordon          = "SwitchoverOnCordon"
+	SynchronousReplication      = "SynchronousReplication"
 )
 
+// ---
+// +kubebuilder:validation:XValidation:rule=`!has(self.tags) || !has(self.tags.replicateFrom) || !has(self.name) || self.tags.replicateFrom != self.name`,message="an instance set cannot replicate from itself"
 type PostgresInstanceSetSpec struct {
 	// +optional
 	Metadata *Metadata `json:"metadata,omitempty"`
@@ -462,6 +656,10 @@ type PostgresInstanceSetSpec struct {
 	// +optional
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 
+	// PostgreSQL configuration for instances in this set.
+	// +optional
+	Config *PostgresInstanceConfigSpec `json:"config,omitempty"`
+
 	// Custom sidecars for PostgreSQL instance pods. Changing this value causes
 	// PostgreSQL to restart.
 	// +optional
@@ -473,6 +671,14 @@ type PostgresInstanceSetSpec struct {
 	// +required
 	DataVolumeClaimSpec VolumeClaimSpec `json:"dataVolumeClaimSpec"`
 
+	// The image name to use for PostgreSQL containers in this set. When omitted,
+	// the value comes from spec.image. The image must contain the same major
+	// version of PostgreSQL as spec.postgresVersion. When the image tag names
+	// a different major version, the operator reports a warning event.
+	// Changing this value causes PostgreSQL to restart.
+	// +optional
+	Image string `json:"image,omitempty"`
+
 	// Priority class name for the PostgreSQL pod. Changing this value causes
 	// PostgreSQL to restart.
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/pod-priority-preemption/
@@ -498,6 +704,11 @@ type PostgresInstanceSetSpec struct {
 	// +optional
 	Sidecars *InstanceSidecars `json:"sidecars,omitempty"`
 
+	// Patroni tags that control failover, replica creation, and load balancing
+	// for instances in this set. Changing this value causes PostgreSQL to restart.
+	// +optional
+	Tags *PatroniTagsSpec `json:"tags,omitempty"`
+
 	// Tolerations of a PostgreSQL pod. Changing this value causes PostgreSQL to restart.
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/taint-and-toleration
 	// +optional
@@ -590,11 +801,112 @@ type PostgresInstanceSetStatus struct {
 	// +optional
 	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
 
+	// The image name of PostgreSQL containers in this set. This is empty while
+	// its Pods are running different images.
+	// +optional
+	Image string `json:"image,omitempty"`
+
 	// Desired Size of the pgData volume
 	// +optional
 	DesiredPGDataVolume map[string]string `json:"desiredPGDataVolume,omitempty"`
+
+	// The PostgreSQL instances of this set as last reported by Patroni and
+	// the primary.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Members []PostgresMemberStatus `json:"members,omitempty"`
+}
+
+type PostgresMemberStatus struct {
+	// The name of the instance Pod.
+	// +required
+	Name string `json:"name"`
+
+	// The role of this instance in the cluster, such as "primary", "replica",
+	// or "standby_leader".
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	// The state of PostgreSQL in this instance, such as "running" or "streaming".
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// The image of the PostgreSQL container in this instance.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// The PostgreSQL timeline of this instance.
+	// +optional
+	Timeline *int64 `json:"timeline,omitempty"`
+
+	// Whether or not PostgreSQL has parameter changes that require a restart.
+	// +optional
+	PendingRestart bool `json:"pendingRestart,omitempty"`
+
+	// The PostgreSQL parameters that changed and require a restart.
+	// +listType=set
+	// +optional
+	PendingRestartParameters []string `json:"pendingRestartParameters,omitempty"`
+
+	// The most recent request to reinitialize this instance.
+	// +optional
+	Reinitialize *PostgresMemberReinitializeStatus `json:"reinitialize,omitempty"`
+
+	// When the primary last reported the replication details below.
+	// +optional
+	ObservedTime *metav1.Time `json:"observedTime,omitempty"`
+
+	// Whether or not the primary waits for this replica to confirm transactions.
+	// +optional
+	Synchronous bool `json:"synchronous,omitempty"`
+
+	// Bytes of WAL this replica has yet to write.
+	// +optional
+	WriteLagBytes *int64 `json:"writeLagBytes,omitempty"`
+
+	// Bytes of WAL this replica has yet to replay.
+	// +optional
+	ReplayLagBytes *int64 `json:"replayLagBytes,omitempty"`
+
+	// Whole seconds between the primary flushing WAL and this replica
+	// reporting that it wrote it.
+	// +optional
+	WriteLagSeconds *int64 `json:"writeLagSeconds,omitempty"`
+
+	// Whole seconds between the primary flushing WAL and this replica
+	// reporting that it replayed it.
+	// +optional
+	ReplayLagSeconds *int64 `json:"replayLagSeconds,omitempty"`
+}
+
+type PostgresMemberReinitializeStatus struct {
+	// The value of the reinitialize annotation that requested this.
+	// +required
+	Trigger string `json:"trigger"`
+
+	// When the operator asked Patroni to reinitialize this instance.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// When PostgreSQL was running again or the request was refused or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The result of this request: "InProgress", "Succeeded", "Failed", or "Refused".
+	// +optional
+	Outcome string `json:"outcome,omitempty"`
 }
 
+// PostgresMemberReinitializeStatus outcomes and "InstanceReinitializing"
+// condition reasons.
+const (
+	ReinitializeFailed     = "Failed"
+	ReinitializeInProgress = "InProgress"
+	ReinitializeRefused    = "Refused"
+	ReinitializeSucceeded  = "Succeeded"
+)
+
 // PostgresProxySpec is a union of the supported PostgreSQL proxies.
 type PostgresProxySpec struct {
 
@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 	"sigs.k8s.io/yaml"
@@ -132,6 +133,53 @@ resources: {}
 	`)+"\n")
 }
 
+func TestMaintenanceWindowSpecOpen(t *testing.T) {
+	// 2024-01-02 is a Tuesday.
+	tuesday := time.Date(2024, time.January, 2, 14, 30, 0, 0, time.UTC)
+
+	t.Run("Nil", func(t *testing.T) {
+		var window *MaintenanceWindowSpec
+		open, next := window.Open(tuesday)
+		assert.Assert(t, open)
+		assert.Equal(t, next, time.Duration(0))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		open, next := new(MaintenanceWindowSpec).Open(tuesday)
+		assert.Assert(t, open)
+		assert.Equal(t, next, time.Duration(0))
+	})
+
+	t.Run("Inside", func(t *testing.T) {
+		window := &MaintenanceWindowSpec{Days: []string{"Tue"}, Hours: []int32{14}}
+		open, next := window.Open(tuesday)
+		assert.Assert(t, open)
+		assert.Equal(t, next, time.Duration(0))
+	})
+
+	t.Run("LaterToday", func(t *testing.T) {
+		window := &MaintenanceWindowSpec{Hours: []int32{2, 22}}
+		open, next := window.Open(tuesday)
+		assert.Assert(t, !open)
+		assert.Equal(t, next, 7*time.Hour+30*time.Minute)
+	})
+
+	t.Run("NextWeek", func(t *testing.T) {
+		window := &MaintenanceWindowSpec{Days: []string{"Tue"}, Hours: []int32{2}}
+		open, next := window.Open(tuesday)
+		assert.Assert(t, !open)
+		assert.Equal(t, next, 6*24*time.Hour+11*time.Hour+30*time.Minute)
+	})
+
+	t.Run("TimeZone", func(t *testing.T) {
+		// 2024-01-02T09:30 in New York is 14:30 UTC.
+		zone := time.FixedZone("EST", -5*60*60)
+		window := &MaintenanceWindowSpec{Days: []string{"Tue"}, Hours: []int32{14}}
+		open, _ := window.Open(tuesday.In(zone))
+		assert.Assert(t, open)
+	})
+}
+
 func TestMetadataGetLabels(t *testing.T) {
 	for _, test := range []struct {
 		m           Metadata
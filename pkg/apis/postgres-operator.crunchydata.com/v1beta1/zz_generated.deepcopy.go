@@ -623,6 +623,31 @@ func (in *InstrumentationSpec) DeepCopy() *InstrumentationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindowSpec) DeepCopyInto(out *MaintenanceWindowSpec) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hours != nil {
+		in, out := &in.Hours, &out.Hours
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowSpec.
+func (in *MaintenanceWindowSpec) DeepCopy() *MaintenanceWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metadata) DeepCopyInto(out *Metadata) {
 	*out = *in
@@ -1672,6 +1697,26 @@ func (in *PGUpgrade) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeCheckSpec) DeepCopyInto(out *PGUpgradeCheckSpec) {
+	*out = *in
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeCheckSpec.
+func (in *PGUpgradeCheckSpec) DeepCopy() *PGUpgradeCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PGUpgradeList) DeepCopyInto(out *PGUpgradeList) {
 	*out = *in
@@ -1704,6 +1749,76 @@ func (in *PGUpgradeList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeLogicalReplicationSpec) DeepCopyInto(out *PGUpgradeLogicalReplicationSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeLogicalReplicationSpec.
+func (in *PGUpgradeLogicalReplicationSpec) DeepCopy() *PGUpgradeLogicalReplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeLogicalReplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradePostUpgradeSpec) DeepCopyInto(out *PGUpgradePostUpgradeSpec) {
+	*out = *in
+	if in.Analyze != nil {
+		in, out := &in.Analyze, &out.Analyze
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UpdateExtensions != nil {
+		in, out := &in.UpdateExtensions, &out.UpdateExtensions
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradePostUpgradeSpec.
+func (in *PGUpgradePostUpgradeSpec) DeepCopy() *PGUpgradePostUpgradeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradePostUpgradeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeRetentionSpec) DeepCopyInto(out *PGUpgradeRetentionSpec) {
+	*out = *in
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeRetentionSpec.
+func (in *PGUpgradeRetentionSpec) DeepCopy() *PGUpgradeRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PGUpgradeSettings) DeepCopyInto(out *PGUpgradeSettings) {
 	*out = *in
@@ -1727,6 +1842,26 @@ func (in *PGUpgradeSpec) DeepCopyInto(out *PGUpgradeSpec) {
 		*out = new(Metadata)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LogicalReplication != nil {
+		in, out := &in.LogicalReplication, &out.LogicalReplication
+		*out = new(PGUpgradeLogicalReplicationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Check != nil {
+		in, out := &in.Check, &out.Check
+		*out = new(PGUpgradeCheckSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostUpgrade != nil {
+		in, out := &in.PostUpgrade, &out.PostUpgrade
+		*out = new(PGUpgradePostUpgradeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(PGUpgradeRetentionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Image != nil {
 		in, out := &in.Image, &out.Image
 		*out = new(string)
@@ -1778,6 +1913,18 @@ func (in *PGUpgradeStatus) DeepCopyInto(out *PGUpgradeStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PreviousImage != nil {
+		in, out := &in.PreviousImage, &out.PreviousImage
+		*out = new(string)
+		**out = **in
+	}
+	if in.PreviousInstanceImages != nil {
+		in, out := &in.PreviousInstanceImages, &out.PreviousInstanceImages
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeStatus.
@@ -1815,6 +1962,92 @@ func (in *PatroniLogConfig) DeepCopy() *PatroniLogConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniPreferredPrimarySpec) DeepCopyInto(out *PatroniPreferredPrimarySpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniPreferredPrimarySpec.
+func (in *PatroniPreferredPrimarySpec) DeepCopy() *PatroniPreferredPrimarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniPreferredPrimarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniSlotRetentionSpec) DeepCopyInto(out *PatroniSlotRetentionSpec) {
+	*out = *in
+	if in.MaxWAL != nil {
+		in, out := &in.MaxWAL, &out.MaxWAL
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSlotRetentionSpec.
+func (in *PatroniSlotRetentionSpec) DeepCopy() *PatroniSlotRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniSlotRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniSlotSpec) DeepCopyInto(out *PatroniSlotSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSlotSpec.
+func (in *PatroniSlotSpec) DeepCopy() *PatroniSlotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniSlotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniSlotStatus) DeepCopyInto(out *PatroniSlotStatus) {
+	*out = *in
+	if in.RetainedBytes != nil {
+		in, out := &in.RetainedBytes, &out.RetainedBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ObservedTime != nil {
+		in, out := &in.ObservedTime, &out.ObservedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSlotStatus.
+func (in *PatroniSlotStatus) DeepCopy() *PatroniSlotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniSlotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PatroniSpec) DeepCopyInto(out *PatroniSpec) {
 	*out = *in
@@ -1829,21 +2062,48 @@ func (in *PatroniSpec) DeepCopyInto(out *PatroniSpec) {
 		*out = new(PatroniLogConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PreferredPrimary != nil {
+		in, out := &in.PreferredPrimary, &out.PreferredPrimary
+		*out = new(PatroniPreferredPrimarySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Port != nil {
 		in, out := &in.Port, &out.Port
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Slots != nil {
+		in, out := &in.Slots, &out.Slots
+		*out = make([]PatroniSlotSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SlotRetention != nil {
+		in, out := &in.SlotRetention, &out.SlotRetention
+		*out = new(PatroniSlotRetentionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SyncPeriodSeconds != nil {
 		in, out := &in.SyncPeriodSeconds, &out.SyncPeriodSeconds
 		*out = new(int32)
 		**out = **in
 	}
+	if in.SwitchoverOnCordon != nil {
+		in, out := &in.SwitchoverOnCordon, &out.SwitchoverOnCordon
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Switchover != nil {
 		in, out := &in.Switchover, &out.Switchover
 		*out = new(PatroniSwitchover)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Synchronous != nil {
+		in, out := &in.Synchronous, &out.Synchronous
+		*out = new(PatroniSynchronousSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSpec.
@@ -1869,6 +2129,31 @@ func (in *PatroniStatus) DeepCopyInto(out *PatroniStatus) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.LastSwitchover != nil {
+		in, out := &in.LastSwitchover, &out.LastSwitchover
+		*out = new(PatroniSwitchoverStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreferredPrimarySwitchoverTime != nil {
+		in, out := &in.PreferredPrimarySwitchoverTime, &out.PreferredPrimarySwitchoverTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SlotsRetainingWAL != nil {
+		in, out := &in.SlotsRetainingWAL, &out.SlotsRetainingWAL
+		*out = make([]PatroniSlotStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SlotsObservedTime != nil {
+		in, out := &in.SlotsObservedTime, &out.SlotsObservedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SynchronousStandbys != nil {
+		in, out := &in.SynchronousStandbys, &out.SynchronousStandbys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniStatus.
@@ -1889,6 +2174,10 @@ func (in *PatroniSwitchover) DeepCopyInto(out *PatroniSwitchover) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ScheduledTime != nil {
+		in, out := &in.ScheduledTime, &out.ScheduledTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSwitchover.
@@ -1901,6 +2190,88 @@ func (in *PatroniSwitchover) DeepCopy() *PatroniSwitchover {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniSwitchoverStatus) DeepCopyInto(out *PatroniSwitchoverStatus) {
+	*out = *in
+	if in.ScheduledTime != nil {
+		in, out := &in.ScheduledTime, &out.ScheduledTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSwitchoverStatus.
+func (in *PatroniSwitchoverStatus) DeepCopy() *PatroniSwitchoverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniSwitchoverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniSynchronousSpec) DeepCopyInto(out *PatroniSynchronousSpec) {
+	*out = *in
+	if in.Standbys != nil {
+		in, out := &in.Standbys, &out.Standbys
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Strict != nil {
+		in, out := &in.Strict, &out.Strict
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSynchronousSpec.
+func (in *PatroniSynchronousSpec) DeepCopy() *PatroniSynchronousSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniSynchronousSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniTagsSpec) DeepCopyInto(out *PatroniTagsSpec) {
+	*out = *in
+	if in.NoLoadBalance != nil {
+		in, out := &in.NoLoadBalance, &out.NoLoadBalance
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NoFailover != nil {
+		in, out := &in.NoFailover, &out.NoFailover
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReplicationDelay != nil {
+		in, out := &in.ReplicationDelay, &out.ReplicationDelay
+		*out = new(Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniTagsSpec.
+func (in *PatroniTagsSpec) DeepCopy() *PatroniTagsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniTagsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresAuthenticationSpec) DeepCopyInto(out *PostgresAuthenticationSpec) {
 	*out = *in
@@ -1911,6 +2282,11 @@ func (in *PostgresAuthenticationSpec) DeepCopyInto(out *PostgresAuthenticationSp
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RejectMD5 != nil {
+		in, out := &in.RejectMD5, &out.RejectMD5
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresAuthenticationSpec.
@@ -2059,14 +2435,28 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(DatabaseInitSQL)
 		**out = **in
 	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresDatabaseSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.DisableDefaultPodScheduling != nil {
 		in, out := &in.DisableDefaultPodScheduling, &out.DisableDefaultPodScheduling
 		*out = new(bool)
 		**out = **in
 	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]corev1.LocalObjectReference, len(*in))
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]PostgresExtensionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.InstanceSets != nil {
@@ -2081,6 +2471,11 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(InstrumentationSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindowSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.OpenShift != nil {
 		in, out := &in.OpenShift, &out.OpenShift
 		*out = new(bool)
@@ -2106,6 +2501,13 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(PostgresProxySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]PostgresPublicationSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.UserInterface != nil {
 		in, out := &in.UserInterface, &out.UserInterface
 		*out = new(UserInterfaceSpec)
@@ -2126,6 +2528,11 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(ServiceSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Shutdown != nil {
 		in, out := &in.Shutdown, &out.Shutdown
 		*out = new(bool)
@@ -2136,11 +2543,23 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(PostgresStandbySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = make([]PostgresSubscriptionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.SupplementalGroups != nil {
 		in, out := &in.SupplementalGroups, &out.SupplementalGroups
 		*out = make([]int64, len(*in))
 		copy(*out, *in)
 	}
+	if in.UserDeletion != nil {
+		in, out := &in.UserDeletion, &out.UserDeletion
+		*out = new(PostgresUserDeletionSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Users != nil {
 		in, out := &in.Users, &out.Users
 		*out = make([]PostgresUserSpec, len(*in))
@@ -2163,6 +2582,18 @@ func (in *PostgresClusterSpec) DeepCopy() *PostgresClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 	*out = *in
+	if in.StoppedConnectionLimits != nil {
+		in, out := &in.StoppedConnectionLimits, &out.StoppedConnectionLimits
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]PostgresExtensionStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.InstanceSets != nil {
 		in, out := &in.InstanceSets, &out.InstanceSets
 		*out = make([]PostgresInstanceSetStatus, len(*in))
@@ -2170,6 +2601,10 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ReplicationObservedTime != nil {
+		in, out := &in.ReplicationObservedTime, &out.ReplicationObservedTime
+		*out = (*in).DeepCopy()
+	}
 	in.Patroni.DeepCopyInto(&out.Patroni)
 	if in.PGBackRest != nil {
 		in, out := &in.PGBackRest, &out.PGBackRest
@@ -2182,11 +2617,34 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 		**out = **in
 	}
 	out.Proxy = in.Proxy
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = make([]PostgresSubscriptionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SubscriptionsObservedTime != nil {
+		in, out := &in.SubscriptionsObservedTime, &out.SubscriptionsObservedTime
+		*out = (*in).DeepCopy()
+	}
 	if in.UserInterface != nil {
 		in, out := &in.UserInterface, &out.UserInterface
 		*out = new(PostgresUserInterfaceStatus)
 		**out = **in
 	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]PostgresUserStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PasswordRotation != nil {
+		in, out := &in.PasswordRotation, &out.PasswordRotation
+		*out = new(string)
+		**out = **in
+	}
 	out.Monitoring = in.Monitoring
 	if in.DatabaseInitSQL != nil {
 		in, out := &in.DatabaseInitSQL, &out.DatabaseInitSQL
@@ -2241,6 +2699,149 @@ func (in *PostgresConfigSpec) DeepCopy() *PostgresConfigSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseGrant) DeepCopyInto(out *PostgresDatabaseGrant) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseGrant.
+func (in *PostgresDatabaseGrant) DeepCopy() *PostgresDatabaseGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseSpec) DeepCopyInto(out *PostgresDatabaseSpec) {
+	*out = *in
+	if in.ConnectionLimit != nil {
+		in, out := &in.ConnectionLimit, &out.ConnectionLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]PostgresExtensionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseSpec.
+func (in *PostgresDatabaseSpec) DeepCopy() *PostgresDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDefaultPrivilegesGrant) DeepCopyInto(out *PostgresDefaultPrivilegesGrant) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDefaultPrivilegesGrant.
+func (in *PostgresDefaultPrivilegesGrant) DeepCopy() *PostgresDefaultPrivilegesGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDefaultPrivilegesGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresExtensionSpec) DeepCopyInto(out *PostgresExtensionSpec) {
+	*out = *in
+	if in.SharedPreloadLibraries != nil {
+		in, out := &in.SharedPreloadLibraries, &out.SharedPreloadLibraries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresExtensionSpec.
+func (in *PostgresExtensionSpec) DeepCopy() *PostgresExtensionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresExtensionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresExtensionStatus) DeepCopyInto(out *PostgresExtensionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresExtensionStatus.
+func (in *PostgresExtensionStatus) DeepCopy() *PostgresExtensionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresExtensionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresGrantsSpec) DeepCopyInto(out *PostgresGrantsSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresDatabaseGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultPrivileges != nil {
+		in, out := &in.DefaultPrivileges, &out.DefaultPrivileges
+		*out = make([]PostgresDefaultPrivilegesGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schemas != nil {
+		in, out := &in.Schemas, &out.Schemas
+		*out = make([]PostgresSchemaGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresGrantsSpec.
+func (in *PostgresGrantsSpec) DeepCopy() *PostgresGrantsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresGrantsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresHBARule) DeepCopyInto(out *PostgresHBARule) {
 	*out = *in
@@ -2289,6 +2890,28 @@ func (in *PostgresHBARuleSpec) DeepCopy() *PostgresHBARuleSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresInstanceConfigSpec) DeepCopyInto(out *PostgresInstanceConfigSpec) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]intstr.IntOrString, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceConfigSpec.
+func (in *PostgresInstanceConfigSpec) DeepCopy() *PostgresInstanceConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresInstanceConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 	*out = *in
@@ -2302,6 +2925,11 @@ func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(PostgresInstanceConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Containers != nil {
 		in, out := &in.Containers, &out.Containers
 		*out = make([]corev1.Container, len(*in))
@@ -2331,6 +2959,11 @@ func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 		*out = new(InstanceSidecars)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = new(PatroniTagsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]corev1.Toleration, len(*in))
@@ -2383,6 +3016,13 @@ func (in *PostgresInstanceSetStatus) DeepCopyInto(out *PostgresInstanceSetStatus
 			(*out)[key] = val
 		}
 	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]PostgresMemberStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceSetStatus.
@@ -2395,9 +3035,116 @@ func (in *PostgresInstanceSetStatus) DeepCopy() *PostgresInstanceSetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresMemberReinitializeStatus) DeepCopyInto(out *PostgresMemberReinitializeStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresMemberReinitializeStatus.
+func (in *PostgresMemberReinitializeStatus) DeepCopy() *PostgresMemberReinitializeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresMemberReinitializeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresMemberStatus) DeepCopyInto(out *PostgresMemberStatus) {
+	*out = *in
+	if in.Timeline != nil {
+		in, out := &in.Timeline, &out.Timeline
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PendingRestartParameters != nil {
+		in, out := &in.PendingRestartParameters, &out.PendingRestartParameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Reinitialize != nil {
+		in, out := &in.Reinitialize, &out.Reinitialize
+		*out = new(PostgresMemberReinitializeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObservedTime != nil {
+		in, out := &in.ObservedTime, &out.ObservedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.WriteLagBytes != nil {
+		in, out := &in.WriteLagBytes, &out.WriteLagBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ReplayLagBytes != nil {
+		in, out := &in.ReplayLagBytes, &out.ReplayLagBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.WriteLagSeconds != nil {
+		in, out := &in.WriteLagSeconds, &out.WriteLagSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ReplayLagSeconds != nil {
+		in, out := &in.ReplayLagSeconds, &out.ReplayLagSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresMemberStatus.
+func (in *PostgresMemberStatus) DeepCopy() *PostgresMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPasswordRotationSpec) DeepCopyInto(out *PostgresPasswordRotationSpec) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPasswordRotationSpec.
+func (in *PostgresPasswordRotationSpec) DeepCopy() *PostgresPasswordRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPasswordRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresPasswordSpec) DeepCopyInto(out *PostgresPasswordSpec) {
 	*out = *in
+	if in.Rotation != nil {
+		in, out := &in.Rotation, &out.Rotation
+		*out = new(PostgresPasswordRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(SecretKeyRef)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPasswordSpec.
@@ -2446,6 +3193,63 @@ func (in *PostgresProxyStatus) DeepCopy() *PostgresProxyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPublicationSpec) DeepCopyInto(out *PostgresPublicationSpec) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]PostgresPublicationTableSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPublicationSpec.
+func (in *PostgresPublicationSpec) DeepCopy() *PostgresPublicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPublicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPublicationTableSpec) DeepCopyInto(out *PostgresPublicationTableSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPublicationTableSpec.
+func (in *PostgresPublicationTableSpec) DeepCopy() *PostgresPublicationTableSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPublicationTableSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSchemaGrant) DeepCopyInto(out *PostgresSchemaGrant) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSchemaGrant.
+func (in *PostgresSchemaGrant) DeepCopy() *PostgresSchemaGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSchemaGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresStandbySpec) DeepCopyInto(out *PostgresStandbySpec) {
 	*out = *in
@@ -2466,6 +3270,86 @@ func (in *PostgresStandbySpec) DeepCopy() *PostgresStandbySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSubscriptionSourceSpec) DeepCopyInto(out *PostgresSubscriptionSourceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscriptionSourceSpec.
+func (in *PostgresSubscriptionSourceSpec) DeepCopy() *PostgresSubscriptionSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscriptionSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSubscriptionSpec) DeepCopyInto(out *PostgresSubscriptionSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	in.Source.DeepCopyInto(&out.Source)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscriptionSpec.
+func (in *PostgresSubscriptionSpec) DeepCopy() *PostgresSubscriptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscriptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSubscriptionStatus) DeepCopyInto(out *PostgresSubscriptionStatus) {
+	*out = *in
+	if in.LagSeconds != nil {
+		in, out := &in.LagSeconds, &out.LagSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ObservedTime != nil {
+		in, out := &in.ObservedTime, &out.ObservedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscriptionStatus.
+func (in *PostgresSubscriptionStatus) DeepCopy() *PostgresSubscriptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscriptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserDeletionSpec) DeepCopyInto(out *PostgresUserDeletionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserDeletionSpec.
+func (in *PostgresUserDeletionSpec) DeepCopy() *PostgresUserDeletionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserDeletionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresUserInterfaceStatus) DeepCopyInto(out *PostgresUserInterfaceStatus) {
 	*out = *in
@@ -2490,10 +3374,15 @@ func (in *PostgresUserSpec) DeepCopyInto(out *PostgresUserSpec) {
 		*out = make([]PostgresIdentifier, len(*in))
 		copy(*out, *in)
 	}
+	if in.Grants != nil {
+		in, out := &in.Grants, &out.Grants
+		*out = new(PostgresGrantsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Password != nil {
 		in, out := &in.Password, &out.Password
 		*out = new(PostgresPasswordSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -2507,6 +3396,25 @@ func (in *PostgresUserSpec) DeepCopy() *PostgresUserSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserStatus) DeepCopyInto(out *PostgresUserStatus) {
+	*out = *in
+	if in.PasswordRotationTime != nil {
+		in, out := &in.PasswordRotationTime, &out.PasswordRotationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserStatus.
+func (in *PostgresUserStatus) DeepCopy() *PostgresUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresVolumesSpec) DeepCopyInto(out *PostgresVolumesSpec) {
 	*out = *in
@@ -2633,6 +3541,26 @@ func (in *RepoStatus) DeepCopy() *RepoStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutSpec) DeepCopyInto(out *RolloutSpec) {
+	*out = *in
+	if in.MaxReplayLag != nil {
+		in, out := &in.MaxReplayLag, &out.MaxReplayLag
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSpec.
+func (in *RolloutSpec) DeepCopy() *RolloutSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in SchemalessObject) DeepCopyInto(out *SchemalessObject) {
 	{